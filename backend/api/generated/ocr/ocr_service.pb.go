@@ -124,6 +124,66 @@ func (x *StringListResponse) GetPageNum() uint32 {
 	return 0
 }
 
+type PDFProgress struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PagesDone     uint32                 `protobuf:"varint,1,opt,name=pages_done,json=pagesDone,proto3" json:"pages_done,omitempty"`
+	PagesTotal    uint32                 `protobuf:"varint,2,opt,name=pages_total,json=pagesTotal,proto3" json:"pages_total,omitempty"`
+	Result        *StringListResponse    `protobuf:"bytes,3,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PDFProgress) Reset() {
+	*x = PDFProgress{}
+	mi := &file_ocr_service_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PDFProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PDFProgress) ProtoMessage() {}
+
+func (x *PDFProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_ocr_service_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PDFProgress.ProtoReflect.Descriptor instead.
+func (*PDFProgress) Descriptor() ([]byte, []int) {
+	return file_ocr_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PDFProgress) GetPagesDone() uint32 {
+	if x != nil {
+		return x.PagesDone
+	}
+	return 0
+}
+
+func (x *PDFProgress) GetPagesTotal() uint32 {
+	if x != nil {
+		return x.PagesTotal
+	}
+	return 0
+}
+
+func (x *PDFProgress) GetResult() *StringListResponse {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
 var File_ocr_service_proto protoreflect.FileDescriptor
 
 var file_ocr_service_proto_rawDesc = []byte{
@@ -137,15 +197,26 @@ var file_ocr_service_proto_rawDesc = []byte{
 	0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03,
 	0x28, 0x09, 0x52, 0x05, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x12, 0x19, 0x0a, 0x08, 0x70, 0x61, 0x67,
 	0x65, 0x5f, 0x6e, 0x75, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x70, 0x61, 0x67,
-	0x65, 0x4e, 0x75, 0x6d, 0x32, 0x44, 0x0a, 0x0a, 0x4f, 0x43, 0x52, 0x53, 0x65, 0x72, 0x76, 0x69,
+	0x65, 0x4e, 0x75, 0x6d, 0x22, 0x7e, 0x0a, 0x0b, 0x50, 0x44, 0x46, 0x50, 0x72, 0x6f, 0x67, 0x72,
+	0x65, 0x73, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x61, 0x67, 0x65, 0x73, 0x5f, 0x64, 0x6f, 0x6e,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x70, 0x61, 0x67, 0x65, 0x73, 0x44, 0x6f,
+	0x6e, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x70, 0x61, 0x67, 0x65, 0x73, 0x5f, 0x74, 0x6f, 0x74, 0x61,
+	0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x70, 0x61, 0x67, 0x65, 0x73, 0x54, 0x6f,
+	0x74, 0x61, 0x6c, 0x12, 0x2f, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x6f, 0x63, 0x72, 0x2e, 0x53, 0x74, 0x72, 0x69, 0x6e, 0x67,
+	0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x52, 0x06, 0x72, 0x65,
+	0x73, 0x75, 0x6c, 0x74, 0x32, 0x75, 0x0a, 0x0a, 0x4f, 0x43, 0x52, 0x53, 0x65, 0x72, 0x76, 0x69,
 	0x63, 0x65, 0x12, 0x36, 0x0a, 0x0a, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x50, 0x44, 0x46,
 	0x12, 0x0f, 0x2e, 0x6f, 0x63, 0x72, 0x2e, 0x50, 0x44, 0x46, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
 	0x74, 0x1a, 0x17, 0x2e, 0x6f, 0x63, 0x72, 0x2e, 0x53, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x4c, 0x69,
-	0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x32, 0x5a, 0x30, 0x67, 0x69,
-	0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6f, 0x4f, 0x53, 0x6f, 0x6d, 0x6e, 0x75,
-	0x73, 0x2f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6c, 0x61, 0x74, 0x65, 0x2f, 0x61, 0x70, 0x69,
-	0x2f, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2f, 0x6f, 0x63, 0x72, 0x62, 0x06,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2f, 0x0a, 0x08, 0x54, 0x72,
+	0x61, 0x63, 0x6b, 0x50, 0x44, 0x46, 0x12, 0x0f, 0x2e, 0x6f, 0x63, 0x72, 0x2e, 0x50, 0x44, 0x46,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x6f, 0x63, 0x72, 0x2e, 0x50, 0x44,
+	0x46, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x30, 0x01, 0x42, 0x32, 0x5a, 0x30, 0x67,
+	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6f, 0x4f, 0x53, 0x6f, 0x6d, 0x6e,
+	0x75, 0x73, 0x2f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6c, 0x61, 0x74, 0x65, 0x2f, 0x61, 0x70,
+	0x69, 0x2f, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2f, 0x6f, 0x63, 0x72, 0x62,
+	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -160,19 +231,23 @@ func file_ocr_service_proto_rawDescGZIP() []byte {
 	return file_ocr_service_proto_rawDescData
 }
 
-var file_ocr_service_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_ocr_service_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
 var file_ocr_service_proto_goTypes = []any{
 	(*PDFRequest)(nil),         // 0: ocr.PDFRequest
 	(*StringListResponse)(nil), // 1: ocr.StringListResponse
+	(*PDFProgress)(nil),        // 2: ocr.PDFProgress
 }
 var file_ocr_service_proto_depIdxs = []int32{
-	0, // 0: ocr.OCRService.ProcessPDF:input_type -> ocr.PDFRequest
-	1, // 1: ocr.OCRService.ProcessPDF:output_type -> ocr.StringListResponse
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	1, // 0: ocr.PDFProgress.result:type_name -> ocr.StringListResponse
+	0, // 1: ocr.OCRService.ProcessPDF:input_type -> ocr.PDFRequest
+	0, // 2: ocr.OCRService.TrackPDF:input_type -> ocr.PDFRequest
+	1, // 3: ocr.OCRService.ProcessPDF:output_type -> ocr.StringListResponse
+	2, // 4: ocr.OCRService.TrackPDF:output_type -> ocr.PDFProgress
+	3, // [3:5] is the sub-list for method output_type
+	1, // [1:3] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
 }
 
 func init() { file_ocr_service_proto_init() }
@@ -186,7 +261,7 @@ func file_ocr_service_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_ocr_service_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   3,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
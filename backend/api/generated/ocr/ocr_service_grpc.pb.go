@@ -20,6 +20,7 @@ const _ = grpc.SupportPackageIsVersion9
 
 const (
 	OCRService_ProcessPDF_FullMethodName = "/ocr.OCRService/ProcessPDF"
+	OCRService_TrackPDF_FullMethodName   = "/ocr.OCRService/TrackPDF"
 )
 
 // OCRServiceClient is the client API for OCRService service.
@@ -27,6 +28,7 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type OCRServiceClient interface {
 	ProcessPDF(ctx context.Context, in *PDFRequest, opts ...grpc.CallOption) (*StringListResponse, error)
+	TrackPDF(ctx context.Context, in *PDFRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PDFProgress], error)
 }
 
 type oCRServiceClient struct {
@@ -47,11 +49,31 @@ func (c *oCRServiceClient) ProcessPDF(ctx context.Context, in *PDFRequest, opts
 	return out, nil
 }
 
+func (c *oCRServiceClient) TrackPDF(ctx context.Context, in *PDFRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PDFProgress], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &OCRService_ServiceDesc.Streams[0], OCRService_TrackPDF_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[PDFRequest, PDFProgress]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OCRService_TrackPDFClient = grpc.ServerStreamingClient[PDFProgress]
+
 // OCRServiceServer is the server API for OCRService service.
 // All implementations must embed UnimplementedOCRServiceServer
 // for forward compatibility.
 type OCRServiceServer interface {
 	ProcessPDF(context.Context, *PDFRequest) (*StringListResponse, error)
+	TrackPDF(*PDFRequest, grpc.ServerStreamingServer[PDFProgress]) error
 	mustEmbedUnimplementedOCRServiceServer()
 }
 
@@ -65,6 +87,9 @@ type UnimplementedOCRServiceServer struct{}
 func (UnimplementedOCRServiceServer) ProcessPDF(context.Context, *PDFRequest) (*StringListResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ProcessPDF not implemented")
 }
+func (UnimplementedOCRServiceServer) TrackPDF(*PDFRequest, grpc.ServerStreamingServer[PDFProgress]) error {
+	return status.Errorf(codes.Unimplemented, "method TrackPDF not implemented")
+}
 func (UnimplementedOCRServiceServer) mustEmbedUnimplementedOCRServiceServer() {}
 func (UnimplementedOCRServiceServer) testEmbeddedByValue()                    {}
 
@@ -104,6 +129,17 @@ func _OCRService_ProcessPDF_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _OCRService_TrackPDF_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(PDFRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OCRServiceServer).TrackPDF(m, &grpc.GenericServerStream[PDFRequest, PDFProgress]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OCRService_TrackPDFServer = grpc.ServerStreamingServer[PDFProgress]
+
 // OCRService_ServiceDesc is the grpc.ServiceDesc for OCRService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -116,6 +152,12 @@ var OCRService_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _OCRService_ProcessPDF_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "TrackPDF",
+			Handler:       _OCRService_TrackPDF_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "ocr_service.proto",
 }
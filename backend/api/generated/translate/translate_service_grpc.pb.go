@@ -20,6 +20,9 @@ const _ = grpc.SupportPackageIsVersion9
 
 const (
 	TranslateService_ProcessTranslation_FullMethodName = "/translate.TranslateService/ProcessTranslation"
+	TranslateService_TrackTranslation_FullMethodName   = "/translate.TranslateService/TrackTranslation"
+	TranslateService_ProposeTerms_FullMethodName       = "/translate.TranslateService/ProposeTerms"
+	TranslateService_TranslateSegments_FullMethodName  = "/translate.TranslateService/TranslateSegments"
 )
 
 // TranslateServiceClient is the client API for TranslateService service.
@@ -27,6 +30,9 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type TranslateServiceClient interface {
 	ProcessTranslation(ctx context.Context, in *TranslateRequest, opts ...grpc.CallOption) (*TranslateResult, error)
+	TrackTranslation(ctx context.Context, in *TranslateRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TranslateProgress], error)
+	ProposeTerms(ctx context.Context, in *TermRequest, opts ...grpc.CallOption) (*TermResult, error)
+	TranslateSegments(ctx context.Context, in *SegmentRequest, opts ...grpc.CallOption) (*SegmentResult, error)
 }
 
 type translateServiceClient struct {
@@ -47,11 +53,53 @@ func (c *translateServiceClient) ProcessTranslation(ctx context.Context, in *Tra
 	return out, nil
 }
 
+func (c *translateServiceClient) TrackTranslation(ctx context.Context, in *TranslateRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TranslateProgress], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TranslateService_ServiceDesc.Streams[0], TranslateService_TrackTranslation_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[TranslateRequest, TranslateProgress]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TranslateService_TrackTranslationClient = grpc.ServerStreamingClient[TranslateProgress]
+
+func (c *translateServiceClient) ProposeTerms(ctx context.Context, in *TermRequest, opts ...grpc.CallOption) (*TermResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TermResult)
+	err := c.cc.Invoke(ctx, TranslateService_ProposeTerms_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *translateServiceClient) TranslateSegments(ctx context.Context, in *SegmentRequest, opts ...grpc.CallOption) (*SegmentResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SegmentResult)
+	err := c.cc.Invoke(ctx, TranslateService_TranslateSegments_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TranslateServiceServer is the server API for TranslateService service.
 // All implementations must embed UnimplementedTranslateServiceServer
 // for forward compatibility.
 type TranslateServiceServer interface {
 	ProcessTranslation(context.Context, *TranslateRequest) (*TranslateResult, error)
+	TrackTranslation(*TranslateRequest, grpc.ServerStreamingServer[TranslateProgress]) error
+	ProposeTerms(context.Context, *TermRequest) (*TermResult, error)
+	TranslateSegments(context.Context, *SegmentRequest) (*SegmentResult, error)
 	mustEmbedUnimplementedTranslateServiceServer()
 }
 
@@ -65,6 +113,15 @@ type UnimplementedTranslateServiceServer struct{}
 func (UnimplementedTranslateServiceServer) ProcessTranslation(context.Context, *TranslateRequest) (*TranslateResult, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ProcessTranslation not implemented")
 }
+func (UnimplementedTranslateServiceServer) TrackTranslation(*TranslateRequest, grpc.ServerStreamingServer[TranslateProgress]) error {
+	return status.Errorf(codes.Unimplemented, "method TrackTranslation not implemented")
+}
+func (UnimplementedTranslateServiceServer) ProposeTerms(context.Context, *TermRequest) (*TermResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ProposeTerms not implemented")
+}
+func (UnimplementedTranslateServiceServer) TranslateSegments(context.Context, *SegmentRequest) (*SegmentResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TranslateSegments not implemented")
+}
 func (UnimplementedTranslateServiceServer) mustEmbedUnimplementedTranslateServiceServer() {}
 func (UnimplementedTranslateServiceServer) testEmbeddedByValue()                          {}
 
@@ -104,6 +161,53 @@ func _TranslateService_ProcessTranslation_Handler(srv interface{}, ctx context.C
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TranslateService_TrackTranslation_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TranslateRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TranslateServiceServer).TrackTranslation(m, &grpc.GenericServerStream[TranslateRequest, TranslateProgress]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TranslateService_TrackTranslationServer = grpc.ServerStreamingServer[TranslateProgress]
+
+func _TranslateService_ProposeTerms_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TermRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TranslateServiceServer).ProposeTerms(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TranslateService_ProposeTerms_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TranslateServiceServer).ProposeTerms(ctx, req.(*TermRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TranslateService_TranslateSegments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SegmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TranslateServiceServer).TranslateSegments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TranslateService_TranslateSegments_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TranslateServiceServer).TranslateSegments(ctx, req.(*SegmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // TranslateService_ServiceDesc is the grpc.ServiceDesc for TranslateService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -115,7 +219,21 @@ var TranslateService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ProcessTranslation",
 			Handler:    _TranslateService_ProcessTranslation_Handler,
 		},
+		{
+			MethodName: "ProposeTerms",
+			Handler:    _TranslateService_ProposeTerms_Handler,
+		},
+		{
+			MethodName: "TranslateSegments",
+			Handler:    _TranslateService_TranslateSegments_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "TrackTranslation",
+			Handler:       _TranslateService_TrackTranslation_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "translate_service.proto",
 }
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"github.com/oOSomnus/transflate/cmd/task_manager/config"
+	"github.com/oOSomnus/transflate/internal/task_manager/repository"
+	"github.com/oOSomnus/transflate/internal/task_manager/usecase"
+	"github.com/spf13/viper"
+	"log"
+	"os"
+)
+
+// defaultEnvironment specifies the default environment for the application.
+// configType indicates the configuration file type used.
+const (
+	defaultEnvironment = "local"
+	configType         = "yaml"
+)
+
+// init configures the logger with standard flags, microseconds precision, and a custom prefix for the command.
+func init() {
+	log.SetFlags(log.LstdFlags | log.Lmicroseconds)
+	log.SetPrefix("[Balance Reconciler] ")
+}
+
+// main is the entry point of the balance reconciler, which compares the balance of every user with the sum of the
+// transactions of their ledger and prints the users whose balances drifted from it. It exits with status 1 if any
+// balance drifted, so that it can run from cron or CI, and with status 2 if the balances cannot be reconciled.
+// Drifted balances are reported and never corrected, since only the operator can tell which side is right.
+func main() {
+	initializeConfig()
+
+	db, err := config.NewPostgresConfig().Connect()
+	if err != nil {
+		log.Fatalf("Database connection error: %v", err)
+	}
+	drifts, err := usecase.NewBalanceUsecase(repository.NewBalanceRepository(db)).FindBalanceDrift()
+	if closeErr := db.Close(); closeErr != nil {
+		log.Println("Database closing error:", closeErr)
+	}
+	if err != nil {
+		log.Printf("Error reconciling balances: %v", err)
+		os.Exit(2)
+	}
+
+	if len(drifts) == 0 {
+		log.Println("All balances match their ledger")
+		return
+	}
+	for _, drift := range drifts {
+		fmt.Printf(
+			"%s\tbalance=%d\tledger=%d\tdrift=%+d\n",
+			drift.Username, drift.Balance, drift.LedgerBalance, drift.Balance-drift.LedgerBalance,
+		)
+	}
+	log.Printf("%d balances drifted from their ledger", len(drifts))
+	os.Exit(1)
+}
+
+// initializeConfig reads and applies environment-specific configuration files using Viper.
+// Defaults to "local" environment if TRANSFLATE_ENV is not set.
+// Terminates the application if the configuration file cannot be read.
+func initializeConfig() {
+	env := os.Getenv("TRANSFLATE_ENV")
+	if env == "" {
+		env = defaultEnvironment
+	}
+
+	viper.SetConfigName(fmt.Sprintf("config.%s", env))
+	viper.SetConfigType(configType)
+	viper.AddConfigPath(".")
+
+	if err := viper.ReadInConfig(); err != nil {
+		log.Fatalf("Config file reading error: %v", err)
+	}
+}
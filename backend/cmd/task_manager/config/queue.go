@@ -0,0 +1,33 @@
+package config
+
+import (
+	"github.com/oOSomnus/transflate/internal/task_manager/repository"
+	"github.com/spf13/viper"
+	"log"
+)
+
+// defaultQueueStream and defaultQueueGroup name the Redis stream of the task queue and its consumer group unless
+// queue.stream and queue.group are configured.
+const (
+	defaultQueueStream = "task-jobs"
+	defaultQueueGroup  = "task-workers"
+)
+
+// NewTaskQueue initializes the task queue shared by the task manager, which enqueues jobs, and the task workers, which
+// consume them, on the Redis stream and consumer group configured by queue.stream and queue.group.
+// If the queue cannot be created, the function logs the error and exits the application.
+func NewTaskQueue(redisClient *RedisClient) repository.TaskQueue {
+	stream := viper.GetString("queue.stream")
+	if stream == "" {
+		stream = defaultQueueStream
+	}
+	group := viper.GetString("queue.group")
+	if group == "" {
+		group = defaultQueueGroup
+	}
+	taskQueue, err := repository.NewRedisTaskQueue(redisClient.GetClient(), stream, group)
+	if err != nil {
+		log.Fatalf("Failed to create task queue: %v", err)
+	}
+	return taskQueue
+}
@@ -54,9 +54,8 @@ func main() {
 	redisClient := setupRedisClient()
 	defer redisClient.Close()
 
-	r, cleanup := initializeServer(dbConnection, redisClient)
+	r := initializeServer(dbConnection, redisClient)
 
-	defer cleanup()
 	log.Printf("Starting server on %s", defaultPort)
 	if err := r.Run(defaultPort); err != nil {
 		log.Fatal(err)
@@ -81,8 +80,8 @@ func setupRedisClient() *config.RedisClient {
 // initializeServer initializes and configures a Gin engine with middleware, repositories, handlers, and routes.
 // It takes a database connection and a Redis client as parameters and returns the configured *gin.Engine.
 // The function sets trusted proxies, configures CORS, initializes services, and sets up routes for HTTP handling.
-// It also ensures proper resource cleanup for services upon termination.
-func initializeServer(db *sql.DB, redisClient *config.RedisClient) (*gin.Engine, func()) {
+// Submitted tasks are only queued here and processed by the task workers.
+func initializeServer(db *sql.DB, redisClient *config.RedisClient) *gin.Engine {
 	gin.SetMode(viper.GetString("gin.mode"))
 	verifyDatabaseCredentials()
 
@@ -94,56 +93,43 @@ func initializeServer(db *sql.DB, redisClient *config.RedisClient) (*gin.Engine,
 	// Setup repositories
 	userRepo := repository.NewUserRepository(db)
 	taskRepo := repository.NewTaskRepository(redisClient.GetClient())
+	glossaryRepo := repository.NewGlossaryRepository(db)
+	styleProfileRepo := repository.NewStyleProfileRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	balanceRepo := repository.NewBalanceRepository(db)
+	taskQueue := config.NewTaskQueue(redisClient)
+	taskEvents := repository.NewTaskEventBus(redisClient.GetClient())
 
 	// Setup handlers
 	userHandler := handlers.NewUserHandler(usecase.NewUserUsecase(userRepo))
-	s3Service, ocrService, translateService := initializeServices()
+	balanceHandler := handlers.NewBalanceHandler(usecase.NewBalanceUsecase(balanceRepo))
+	s3Service := initializeStorageService()
 
-	taskStatusService := service.NewTaskStatusService(taskRepo)
+	taskStatusService := service.NewTaskStatusService(taskRepo, taskEvents)
 
-	taskUsecase := usecase.NewTaskUsecase(userRepo, taskRepo, ocrService, s3Service, translateService)
-	taskHandler := handlers.NewTaskHandler(taskUsecase, taskStatusService)
+	// Tasks are processed by the task workers, so the OCR and translate services are not needed here
+	taskUsecase := usecase.NewTaskUsecase(userRepo, taskRepo, s3Service, taskQueue)
+	glossaryUsecase := usecase.NewGlossaryUsecase(glossaryRepo)
+	styleProfileUsecase := usecase.NewStyleProfileUsecase(styleProfileRepo)
+	taskHandler := handlers.NewTaskHandler(taskUsecase, taskStatusService, glossaryUsecase, styleProfileUsecase)
+	glossaryHandler := handlers.NewGlossaryHandler(glossaryUsecase)
+	styleProfileHandler := handlers.NewStyleProfileHandler(styleProfileUsecase)
+	// Webhook payloads are posted by the workers, so this instance needs no sender
+	webhookHandler := handlers.NewWebhookHandler(usecase.NewWebhookUsecase(webhookRepo, taskStatusService, nil))
 
-	setupRoutes(r, userHandler, taskHandler)
+	setupRoutes(r, userHandler, balanceHandler, taskHandler, glossaryHandler, styleProfileHandler, webhookHandler)
 
-	cleanup := func() {
-		cleanupServiceResources(ocrService, translateService)
-	}
-	return r, cleanup
+	return r
 }
 
-// initializeServices initializes and returns instances of S3StorageServiceImpl, OCRService, and TranslateServiceImpl.
-// It logs and exits the application if any of the services fail to initialize.
-func initializeServices() (service.S3StorageService, service.OCRClient, service.TranslateService) {
+// initializeStorageService initializes and returns an instance of S3StorageServiceImpl, which stores the inputs of the
+// queued tasks. It logs and exits the application if the service fails to initialize.
+func initializeStorageService() service.S3StorageService {
 	s3Service, err := service.NewS3StorageService()
 	if err != nil {
 		log.Fatalf("S3 service initialization failed: %v", err)
-		return nil, nil, nil
-	}
-
-	ocrService, err := service.NewOCRService()
-	if err != nil {
-		log.Fatalf("OCR service initialization failed: %v", err)
-		return nil, nil, nil
-	}
-
-	translateService, err := service.NewTranslateService()
-	if err != nil {
-		log.Fatalf("Translate service initialization failed: %v", err)
-		return nil, nil, nil
-	}
-
-	return s3Service, ocrService, translateService
-}
-
-// cleanupServiceResources ensures the proper closure of resources for OCR and Translate services to release gRPC connections.
-func cleanupServiceResources(ocrService service.OCRClient, translateService service.TranslateService) {
-	if err := ocrService.Close(); err != nil {
-		log.Println("OCR service close error:", err)
-	}
-	if err := translateService.CloseTransGrpcConn(); err != nil {
-		log.Println("Translate service close error:", err)
 	}
+	return s3Service
 }
 
 // configureCORS configures Cross-Origin Resource Sharing (CORS) settings for a gin.Engine instance.
@@ -174,7 +160,14 @@ func setupTrustedProxies(r *gin.Engine, proxies []string) {
 // r is the Gin engine used to define HTTP routes and middleware.
 // userHandler handles user-related endpoints like login, register, and user info.
 // taskHandler handles task-related endpoints, such as task submission.
-func setupRoutes(r *gin.Engine, userHandler *handlers.UserHandlerImpl, taskHandler *handlers.TaskHandlerImpl) {
+// glossaryHandler handles the glossary management endpoints of the authenticated user.
+// styleProfileHandler handles the style profile management endpoints of the authenticated user.
+// webhookHandler handles the webhook, webhook secret and delivery log endpoints of the authenticated user.
+func setupRoutes(
+	r *gin.Engine, userHandler *handlers.UserHandlerImpl, balanceHandler *handlers.BalanceHandlerImpl,
+	taskHandler *handlers.TaskHandlerImpl, glossaryHandler *handlers.GlossaryHandlerImpl,
+	styleProfileHandler *handlers.StyleProfileHandlerImpl, webhookHandler *handlers.WebhookHandlerImpl,
+) {
 	r.POST("/login", userHandler.Login)
 	r.POST("/register", userHandler.Register)
 
@@ -182,7 +175,38 @@ func setupRoutes(r *gin.Engine, userHandler *handlers.UserHandlerImpl, taskHandl
 	auth.Use(middleware.AuthMiddleware())
 	auth.POST("/submit", taskHandler.TaskSubmit)
 	auth.GET("/user/info", userHandler.Info)
+	auth.GET("/user/transactions", balanceHandler.Transactions)
 	auth.GET("/tasks", taskHandler.TaskStatusCheckHandler)
+	auth.GET("/tasks/stream", taskHandler.StreamTasks)
+	auth.GET("/tasks/:id", taskHandler.TaskDetail)
+	auth.GET("/tasks/:id/terms", taskHandler.TaskTerms)
+	auth.PUT("/tasks/:id/terms", taskHandler.UpdateTaskTerms)
+	auth.POST("/tasks/:id/terms/confirm", taskHandler.ConfirmTaskTerms)
+	auth.POST("/tasks/:id/cancel", taskHandler.CancelTask)
+	auth.POST("/tasks/:id/retry", taskHandler.RetryTask)
+
+	auth.GET("/glossaries", glossaryHandler.List)
+	auth.POST("/glossaries", glossaryHandler.Create)
+	auth.GET("/glossaries/:id", glossaryHandler.Get)
+	auth.PUT("/glossaries/:id", glossaryHandler.Update)
+	auth.DELETE("/glossaries/:id", glossaryHandler.Delete)
+	auth.POST("/glossaries/:id/terms", glossaryHandler.AddTerms)
+	auth.DELETE("/glossaries/:id/terms/:termId", glossaryHandler.DeleteTerm)
+	auth.POST("/glossaries/:id/import", glossaryHandler.Import)
+
+	auth.GET("/style-profiles", styleProfileHandler.List)
+	auth.POST("/style-profiles", styleProfileHandler.Create)
+	auth.GET("/style-profiles/:id", styleProfileHandler.Get)
+	auth.PUT("/style-profiles/:id", styleProfileHandler.Update)
+	auth.DELETE("/style-profiles/:id", styleProfileHandler.Delete)
+
+	auth.GET("/webhooks", webhookHandler.List)
+	auth.POST("/webhooks", webhookHandler.Create)
+	auth.DELETE("/webhooks/:id", webhookHandler.Delete)
+	auth.GET("/webhooks/secret", webhookHandler.Secret)
+	auth.POST("/webhooks/secret/rotate", webhookHandler.RotateSecret)
+	auth.GET("/webhooks/deliveries", webhookHandler.Deliveries)
+	auth.POST("/webhooks/deliveries/:id/replay", webhookHandler.Replay)
 }
 
 // verifyDatabaseCredentials ensures the presence of database username and password in the application configuration.
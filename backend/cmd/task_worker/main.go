@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/oOSomnus/transflate/cmd/task_manager/config"
+	"github.com/oOSomnus/transflate/internal/task_manager/repository"
+	"github.com/oOSomnus/transflate/internal/task_manager/service"
+	"github.com/oOSomnus/transflate/internal/task_manager/usecase"
+	"github.com/oOSomnus/transflate/internal/task_manager/worker"
+	"github.com/spf13/viper"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// defaultEnvironment specifies the default environment for the application.
+// configType indicates the configuration file type used.
+const (
+	defaultEnvironment = "local"
+	configType         = "yaml"
+)
+
+// init configures the logger with standard flags, microseconds precision, and a custom prefix for the service.
+func init() {
+	log.SetFlags(log.LstdFlags | log.Lmicroseconds)
+	log.SetPrefix("[Task Worker] ")
+}
+
+// main is the entry point of the task worker, which processes the tasks queued by the task manager until it receives
+// SIGINT or SIGTERM. Any number of task workers can run next to each other, each running worker.concurrency tasks at
+// once, posts the webhook notifications of the tasks and releases the balance reservations that expired. On shutdown
+// the tasks in progress are finished before exiting.
+func main() {
+	initializeConfig()
+
+	// Setup resources
+	dbConnection := setupDatabaseConnection()
+	defer cleanupResources(dbConnection)
+	redisClient := config.NewRedisClient()
+	defer redisClient.Close()
+
+	s3Service, ocrService, translateService := initializeServices()
+	defer cleanupServiceResources(ocrService, translateService)
+
+	userRepo := repository.NewUserRepository(dbConnection)
+	taskRepo := repository.NewTaskRepository(redisClient.GetClient())
+	taskQueue := config.NewTaskQueue(redisClient)
+	taskEvents := repository.NewTaskEventBus(redisClient.GetClient())
+	taskStatusService := service.NewTaskStatusService(taskRepo, taskEvents)
+	taskUsecase := usecase.NewTaskProcessingUsecase(userRepo, taskRepo, ocrService, s3Service, translateService, taskQueue)
+	webhookRepo := repository.NewWebhookRepository(dbConnection)
+	webhookUsecase := usecase.NewWebhookUsecase(webhookRepo, taskStatusService, service.NewWebhookSender())
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		worker.NewWebhookDispatcher(webhookUsecase).Run(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		worker.NewReservationSweeper(taskUsecase).Run(ctx)
+	}()
+	worker.NewWorker(taskQueue, taskUsecase, taskStatusService, webhookUsecase).Run(ctx)
+	wg.Wait()
+	log.Println("Task worker stopped")
+}
+
+// initializeConfig reads and applies environment-specific configuration files using Viper.
+// Defaults to "local" environment if TRANSFLATE_ENV is not set.
+// Terminates the application if the configuration file cannot be read.
+func initializeConfig() {
+	env := os.Getenv("TRANSFLATE_ENV")
+	if env == "" {
+		env = defaultEnvironment
+	}
+
+	viper.SetConfigName(fmt.Sprintf("config.%s", env))
+	viper.SetConfigType(configType)
+	viper.AddConfigPath(".")
+
+	if err := viper.ReadInConfig(); err != nil {
+		log.Fatalf("Config file reading error: %v", err)
+	}
+}
+
+// setupDatabaseConnection initializes and returns a database connection using the Postgres configuration settings.
+func setupDatabaseConnection() *sql.DB {
+	dbConnection, err := config.NewPostgresConfig().Connect()
+	if err != nil {
+		log.Fatalf("Database connection error: %v", err)
+	}
+	return dbConnection
+}
+
+// initializeServices initializes and returns instances of S3StorageServiceImpl, OCRService, and TranslateServiceImpl.
+// It logs and exits the application if any of the services fail to initialize.
+func initializeServices() (service.S3StorageService, service.OCRClient, service.TranslateService) {
+	s3Service, err := service.NewS3StorageService()
+	if err != nil {
+		log.Fatalf("S3 service initialization failed: %v", err)
+	}
+
+	ocrService, err := service.NewOCRService()
+	if err != nil {
+		log.Fatalf("OCR service initialization failed: %v", err)
+	}
+
+	translateService, err := service.NewTranslateService()
+	if err != nil {
+		log.Fatalf("Translate service initialization failed: %v", err)
+	}
+
+	return s3Service, ocrService, translateService
+}
+
+// cleanupServiceResources ensures the proper closure of resources for OCR and Translate services to release gRPC connections.
+func cleanupServiceResources(ocrService service.OCRClient, translateService service.TranslateService) {
+	if err := ocrService.Close(); err != nil {
+		log.Println("OCR service close error:", err)
+	}
+	if err := translateService.CloseTransGrpcConn(); err != nil {
+		log.Println("Translate service close error:", err)
+	}
+}
+
+// cleanupResources safely closes the provided database connection and logs any errors that occur during the close operation.
+func cleanupResources(db *sql.DB) {
+	if err := db.Close(); err != nil {
+		log.Println("Database closing error:", err)
+	}
+}
@@ -1,14 +1,29 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	pb "github.com/oOSomnus/transflate/api/generated/translate"
+	"github.com/oOSomnus/transflate/internal/translate_service/domain"
+	"github.com/oOSomnus/transflate/internal/translate_service/repository"
 	"github.com/oOSomnus/transflate/internal/translate_service/server"
+	"github.com/oOSomnus/transflate/internal/translate_service/usecase"
+	"github.com/redis/go-redis/v9"
 	"github.com/spf13/viper"
 	"google.golang.org/grpc"
 	"log"
 	"net"
 	"os"
+	"time"
+)
+
+// defaultMemoryTTL is how long translation memory entries are kept when translate.memory.ttl is not configured.
+// defaultMinLengthRatio and defaultMaxLengthRatio bound the length of a translation relative to its source when
+// translate.quality.min-length-ratio and translate.quality.max-length-ratio are not configured.
+const (
+	defaultMemoryTTL      = 30 * 24 * time.Hour
+	defaultMinLengthRatio = 0.1
+	defaultMaxLengthRatio = 2.0
 )
 
 func init() {
@@ -35,9 +50,134 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	memory, closeMemory := setupTranslationMemory()
+	defer closeMemory()
+	// The term translator also extracts the term sheets of consistency mode and proposes terms for review
+	translator, termTranslator := setupTranslators()
+	translateUsecase := usecase.NewTranslateUsecase(translator, memory, termTranslator, qualityValidators())
+
 	grpcServer := grpc.NewServer()
-	pb.RegisterTranslateServiceServer(grpcServer, &server.TranslateServiceServer{})
+	pb.RegisterTranslateServiceServer(
+		grpcServer, server.NewTranslateServiceServer(translateUsecase, usecase.NewTermUsecase(termTranslator)),
+	)
 	if err := grpcServer.Serve(listener); err != nil {
 		log.Fatal(err)
 	}
 }
+
+// backendConfig describes a translation backend configured under translate.backends.
+// Type is either openai, for OpenAI or any OpenAI-compatible API at BaseURL, or deepl for the DeepL API at BaseURL.
+// Model selects the chat model of an openai backend, and APIKey defaults to openai.api.key for openai backends.
+type backendConfig struct {
+	Name    string `mapstructure:"name"`
+	Type    string `mapstructure:"type"`
+	Model   string `mapstructure:"model"`
+	APIKey  string `mapstructure:"api-key"`
+	BaseURL string `mapstructure:"base-url"`
+}
+
+// setupTranslators returns the Translator of the service along with the GPTTranslator extracting and translating
+// terms. Without translate.backends, the GPTTranslator configured under openai does both. Otherwise the configured
+// backends are tried in order by a FallbackTranslator whose circuit breakers open after translate.breaker.failures
+// consecutive failures for translate.breaker.open-timeout, and the first openai backend handles terms.
+// Terminates the application if a backend is misconfigured.
+func setupTranslators() (domain.Translator, *domain.GPTTranslator) {
+	var configs []backendConfig
+	if err := viper.UnmarshalKey("translate.backends", &configs); err != nil {
+		log.Fatalf("Invalid translation backends: %v", err)
+	}
+	if len(configs) == 0 {
+		translator := domain.NewGPTTranslator()
+		return translator, translator
+	}
+
+	var termTranslator *domain.GPTTranslator
+	backends := make([]domain.TranslationBackend, 0, len(configs))
+	for _, config := range configs {
+		var translator domain.Translator
+		switch config.Type {
+		case "openai":
+			apiKey := config.APIKey
+			if apiKey == "" {
+				apiKey = viper.GetString("openai.api.key")
+			}
+			gpt := domain.NewOpenAITranslator(apiKey, config.BaseURL, config.Model)
+			if termTranslator == nil {
+				termTranslator = gpt
+			}
+			translator = gpt
+		case "deepl":
+			translator = domain.NewDeepLTranslator(config.APIKey, config.BaseURL)
+		default:
+			log.Fatalf("Unknown type %q of translation backend %s", config.Type, config.Name)
+		}
+		backends = append(backends, domain.TranslationBackend{Name: config.Name, Translator: translator})
+	}
+	translator, err := domain.NewFallbackTranslator(
+		backends, domain.BreakerSettings{
+			Failures:    viper.GetUint32("translate.breaker.failures"),
+			OpenTimeout: viper.GetDuration("translate.breaker.open-timeout"),
+		},
+	)
+	if err != nil {
+		log.Fatalf("Invalid translation backends: %v", err)
+	}
+	if termTranslator == nil {
+		termTranslator = domain.NewGPTTranslator()
+	}
+	log.Printf("Translating with %d backends, primary %s", len(backends), backends[0].Name)
+	return translator, termTranslator
+}
+
+// setupTranslationMemory connects to the Redis instance configured under redis.addr and returns a translation memory
+// backed by it, along with a function releasing the connection. The translation memory is disabled and nil is returned
+// if Redis is not configured or unreachable, so that translations still work without it.
+func setupTranslationMemory() (domain.TranslationMemory, func()) {
+	addr := viper.GetString("redis.addr")
+	if addr == "" {
+		log.Println("Redis not configured, translation memory disabled")
+		return nil, func() {}
+	}
+	client := redis.NewClient(
+		&redis.Options{
+			Addr:     addr,
+			Password: viper.GetString("redis.password"),
+			DB:       0, // 使用默认数据库
+		},
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		log.Printf("Failed to connect to redis, translation memory disabled: %v", err)
+		_ = client.Close()
+		return nil, func() {}
+	}
+
+	ttl := viper.GetDuration("translate.memory.ttl")
+	if ttl <= 0 {
+		ttl = defaultMemoryTTL
+	}
+	return repository.NewRedisTranslationMemory(client, ttl), func() {
+		if err := client.Close(); err != nil {
+			log.Println("Redis closing error:", err)
+		}
+	}
+}
+
+// qualityValidators returns the validators checking every new translation, with the length ratio bounds configured
+// under translate.quality. The checks are skipped if translate.quality.enabled is set to false.
+func qualityValidators() []domain.QualityValidator {
+	if viper.IsSet("translate.quality.enabled") && !viper.GetBool("translate.quality.enabled") {
+		log.Println("Quality checks disabled")
+		return nil
+	}
+	minRatio := viper.GetFloat64("translate.quality.min-length-ratio")
+	if minRatio <= 0 {
+		minRatio = defaultMinLengthRatio
+	}
+	maxRatio := viper.GetFloat64("translate.quality.max-length-ratio")
+	if maxRatio <= 0 {
+		maxRatio = defaultMaxLengthRatio
+	}
+	return domain.DefaultValidators(minRatio, maxRatio)
+}
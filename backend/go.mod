@@ -18,8 +18,10 @@ require (
 	github.com/otiai10/gosseract/v2 v2.4.1
 	github.com/pkg/errors v0.9.1
 	github.com/redis/go-redis/v9 v9.7.0
+	github.com/sony/gobreaker v1.0.0
 	github.com/spf13/viper v1.19.0
 	github.com/stretchr/testify v1.10.0
+	github.com/yuin/goldmark v1.7.8
 	golang.org/x/crypto v0.31.0
 	golang.org/x/net v0.33.0
 	google.golang.org/grpc v1.69.2
@@ -45,9 +45,38 @@ type OCRServiceServer struct {
 
 // ProcessPDF handles a PDF processing request by converting it to images, performing OCR, and returning extracted text.
 // It utilizes temporary files, worker pools, and concurrency for efficiency. It returns the OCR result and page count.
+// Once ctx is done, such as when the client cancels the task, no further pages are processed and ctx.Err() is returned.
 func (s *OCRServiceServer) ProcessPDF(ctx context.Context, req *pb.PDFRequest) (*pb.StringListResponse, error) {
-	// Create temp folder
 	log.Println("Received PDF Process request")
+	return recognizePDF(ctx, req, nil)
+}
+
+// TrackPDF handles a PDF processing request like ProcessPDF, but reports its progress on the stream: a message with
+// no recognized page once the pages of the PDF are counted, one message as each page is recognized, and a last
+// message carrying the OCR result.
+func (s *OCRServiceServer) TrackPDF(req *pb.PDFRequest, stream pb.OCRService_TrackPDFServer) error {
+	log.Println("Received PDF Track request")
+	result, err := recognizePDF(
+		stream.Context(), req, func(done, total int) {
+			progress := &pb.PDFProgress{PagesDone: uint32(done), PagesTotal: uint32(total)}
+			if err := stream.Send(progress); err != nil {
+				log.Printf("failed to send OCR progress: %v", err)
+			}
+		},
+	)
+	if err != nil {
+		return err
+	}
+	return stream.Send(&pb.PDFProgress{PagesDone: result.PageNum, PagesTotal: result.PageNum, Result: result})
+}
+
+// recognizePDF converts the PDF of req to images and recognizes the text of every page. When progress is not nil it
+// is called with no page done once the pages are counted, then each time a page is recognized; the calls never
+// overlap.
+func recognizePDF(
+	ctx context.Context, req *pb.PDFRequest, progress func(done, total int),
+) (*pb.StringListResponse, error) {
+	// Create temp folder
 	log.Println("Creating temp file ...")
 	lang := req.Language
 	tmpFile, err := os.CreateTemp("", "input-*.pdf")
@@ -74,7 +103,7 @@ func (s *OCRServiceServer) ProcessPDF(ctx context.Context, req *pb.PDFRequest) (
 	if err := tmpFile.Close(); err != nil {
 		return nil, fmt.Errorf("failed to close temp file: %v", err)
 	}
-	cmd := exec.Command("pdftoppm", "-png", tmpFile.Name(), outputPattern)
+	cmd := exec.CommandContext(ctx, "pdftoppm", "-png", tmpFile.Name(), outputPattern)
 	if err := cmd.Run(); err != nil {
 		return nil, fmt.Errorf("failed to run pdftoppm: %v", err)
 	}
@@ -96,6 +125,13 @@ func (s *OCRServiceServer) ProcessPDF(ctx context.Context, req *pb.PDFRequest) (
 	// Worker pool for concurrent OCR
 	ocrResults := make([]string, len(files))
 
+	// Progress of the recognized pages, reported one page at a time
+	var progressMu sync.Mutex
+	pagesDone := 0
+	if progress != nil {
+		progress(0, pageNumber)
+	}
+
 	// Acquiring number of cpus
 	numCPU := runtime.NumCPU()
 
@@ -106,12 +142,25 @@ func (s *OCRServiceServer) ProcessPDF(ctx context.Context, req *pb.PDFRequest) (
 	workerPool := make(chan struct{}, numCPU+1)
 	log.Println("Starting worker pool ...")
 	for i, file := range files {
+		if ctx.Err() != nil {
+			// The client cancelled the request, so the remaining pages are not recognized
+			break
+		}
 		wg.Add(1)
 		workerPool <- struct{}{} // Acquire a worker slot
 
 		go func(index int, fileName string) {
 			defer wg.Done()
 			defer func() { <-workerPool }() // Release the worker slot
+			if progress != nil {
+				// A page counts as done even when it could not be recognized
+				defer func() {
+					progressMu.Lock()
+					defer progressMu.Unlock()
+					pagesDone++
+					progress(pagesDone, pageNumber)
+				}()
+			}
 			client := gossPool.Get()
 			defer gossPool.Put(client)
 
@@ -134,5 +183,9 @@ func (s *OCRServiceServer) ProcessPDF(ctx context.Context, req *pb.PDFRequest) (
 	log.Println("Waiting worker pool to finish.")
 	wg.Wait() // Wait for all workers to complete
 	log.Println("Worker pool finished.")
+	if err := ctx.Err(); err != nil {
+		log.Println("PDF Process request cancelled")
+		return nil, err
+	}
 	return &pb.StringListResponse{Lines: ocrResults, PageNum: uint32(pageNumber)}, nil
 }
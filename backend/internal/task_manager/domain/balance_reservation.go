@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// ReservationReserved marks pages held from the balance of a user while their task runs.
+// ReservationCommitted marks reserved pages charged for good once their task is done.
+// ReservationReleased marks reserved pages given back to the balance of the user, since their task failed, was
+// cancelled or did not finish before the reservation expired.
+const (
+	ReservationReserved  = "reserved"
+	ReservationCommitted = "committed"
+	ReservationReleased  = "released"
+)
+
+// BalanceReservation is the charge of a task to the balance of its user. The pages of the document are subtracted
+// from the balance once its text is recognized and held until the task is done, which commits them, or fails, which
+// releases them. A reservation neither committed nor released by ExpiresAt is released, and SettledAt is the time the
+// reservation was committed or released.
+type BalanceReservation struct {
+	ID        int        `json:"id"`
+	Username  string     `json:"-"`
+	TaskID    string     `json:"task_id"`
+	Pages     int        `json:"pages"`
+	Status    string     `json:"status"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	SettledAt *time.Time `json:"settled_at"`
+}
@@ -0,0 +1,63 @@
+package domain
+
+import "time"
+
+// TransactionOpening records the balance a user had before the ledger was introduced.
+// TransactionCredit and TransactionDebit record pages added to or subtracted from a balance outside of tasks.
+// TransactionReservation records the pages of a task held from a balance, and TransactionCommit records them charged
+// for good, which leaves the balance unchanged.
+// TransactionRefund records reserved pages given back to a balance.
+const (
+	TransactionOpening     = "opening"
+	TransactionCredit      = "credit"
+	TransactionDebit       = "debit"
+	TransactionReservation = "reservation"
+	TransactionCommit      = "commit"
+	TransactionRefund      = "refund"
+)
+
+// ReasonPagesRecognized, ReasonTaskRetried, ReasonTaskCompleted, ReasonTaskFailed, ReasonTaskCancelled,
+// ReasonRetryNotQueued, ReasonReservationExpired and ReasonTaskNotDelivered are the reasons recorded on the
+// transactions of tasks, which are shown to their user.
+// ReasonBalanceDebited is the reason recorded on debits outside of tasks.
+const (
+	ReasonPagesRecognized    = "Pages of the document recognized"
+	ReasonTaskRetried        = "Task retried"
+	ReasonTaskCompleted      = "Task completed"
+	ReasonTaskFailed         = "Task failed"
+	ReasonTaskCancelled      = "Task cancelled"
+	ReasonRetryNotQueued     = "Retried task could not be queued"
+	ReasonReservationExpired = "Reservation expired before the task finished"
+	ReasonTaskNotDelivered   = "Charged task could not be delivered"
+	ReasonBalanceDebited     = "Balance debited"
+)
+
+// BalanceTransaction is an entry of the ledger of the balance of a user. Amount is the change of the balance, negative
+// for pages taken from it, and BalanceAfter the balance right after the change. TaskID is set on the transactions of
+// tasks.
+type BalanceTransaction struct {
+	ID           int       `json:"id"`
+	Username     string    `json:"-"`
+	Kind         string    `json:"kind"`
+	Amount       int       `json:"amount"`
+	BalanceAfter int       `json:"balance_after"`
+	TaskID       string    `json:"task_id,omitempty"`
+	Reason       string    `json:"reason"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TransactionFilter selects the transactions of a user created at or after From and before To, newest first, skipping
+// Offset of them and returning at most Limit. A zero From or To leaves that end of the range open.
+type TransactionFilter struct {
+	From   time.Time
+	To     time.Time
+	Limit  int
+	Offset int
+}
+
+// BalanceDrift is a user whose stored balance differs from the sum of the amounts of their ledger.
+type BalanceDrift struct {
+	Username      string `json:"username"`
+	Balance       int    `json:"balance"`
+	LedgerBalance int    `json:"ledger_balance"`
+}
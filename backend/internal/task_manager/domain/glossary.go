@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// Glossary represents a named list of terms owned by a user and enforced while translating their documents.
+type Glossary struct {
+	ID        int            `json:"id"`
+	Name      string         `json:"name"`
+	CreatedAt time.Time      `json:"created_at"`
+	Terms     []GlossaryTerm `json:"terms,omitempty"`
+}
+
+// GlossaryTerm represents a source term and the target term it must always be translated to.
+type GlossaryTerm struct {
+	ID     int    `json:"id,omitempty"`
+	Source string `json:"source" binding:"required"`
+	Target string `json:"target" binding:"required"`
+}
+
+// GlossaryRequest represents the structure for creating or replacing a glossary.
+// Name is required, while Terms optionally provides the complete list of terms of the glossary.
+type GlossaryRequest struct {
+	Name  string         `json:"name" binding:"required"`
+	Terms []GlossaryTerm `json:"terms" binding:"dive"`
+}
+
+// GlossaryViolation reports a glossary term found in a chunk whose required translation is missing from the result.
+type GlossaryViolation struct {
+	ChunkIndex int    `json:"chunk_index"`
+	Source     string `json:"source"`
+	Target     string `json:"target"`
+}
@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// FormalityFormal, FormalityNeutral and FormalityInformal are the registers a translation may be written in.
+const (
+	FormalityFormal   = "formal"
+	FormalityNeutral  = "neutral"
+	FormalityInformal = "informal"
+)
+
+// DomainLegal, DomainMedical, DomainTechnical and DomainLiterary are the domain presets a translation may follow.
+const (
+	DomainLegal     = "legal"
+	DomainMedical   = "medical"
+	DomainTechnical = "technical"
+	DomainLiterary  = "literary"
+)
+
+// MaxInstructionsLength is the maximum number of characters of the custom instructions of a style.
+const MaxInstructionsLength = 2000
+
+// ErrInvalidStyle indicates that the requested formality or domain preset is not supported or the instructions are
+// too long.
+var ErrInvalidStyle = errors.New(
+	"invalid style, formality must be formal, neutral or informal, domain legal, medical, technical or literary, " +
+		"and instructions at most 2000 characters",
+)
+
+// TranslationStyle selects how a document is translated: the Formality of the register, the Domain preset and
+// free-form Instructions of the user. Empty fields keep the default behavior of the translation service.
+type TranslationStyle struct {
+	Formality    string `json:"formality,omitempty"`
+	Domain       string `json:"domain,omitempty"`
+	Instructions string `json:"instructions,omitempty"`
+}
+
+// ParseTranslationStyle trims and validates a translation style. Returns ErrInvalidStyle if the formality or domain
+// preset is not supported or the instructions exceed MaxInstructionsLength characters.
+func ParseTranslationStyle(formality, domain, instructions string) (TranslationStyle, error) {
+	style := TranslationStyle{
+		Formality:    strings.ToLower(strings.TrimSpace(formality)),
+		Domain:       strings.ToLower(strings.TrimSpace(domain)),
+		Instructions: strings.TrimSpace(instructions),
+	}
+	switch style.Formality {
+	case "", FormalityFormal, FormalityNeutral, FormalityInformal:
+	default:
+		return TranslationStyle{}, ErrInvalidStyle
+	}
+	switch style.Domain {
+	case "", DomainLegal, DomainMedical, DomainTechnical, DomainLiterary:
+	default:
+		return TranslationStyle{}, ErrInvalidStyle
+	}
+	if utf8.RuneCountInString(style.Instructions) > MaxInstructionsLength {
+		return TranslationStyle{}, ErrInvalidStyle
+	}
+	return style, nil
+}
+
+// Override returns the style with every non-empty field of other replacing the field of the style, so that options
+// given with a task take precedence over the profile it selects.
+func (s TranslationStyle) Override(other TranslationStyle) TranslationStyle {
+	if other.Formality != "" {
+		s.Formality = other.Formality
+	}
+	if other.Domain != "" {
+		s.Domain = other.Domain
+	}
+	if other.Instructions != "" {
+		s.Instructions = other.Instructions
+	}
+	return s
+}
+
+// StyleProfile is a named translation style owned by a user, which can be selected when submitting tasks.
+type StyleProfile struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	TranslationStyle
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// StyleProfileRequest represents the structure for creating or replacing a style profile.
+// Name is required, while the style fields are optional.
+type StyleProfileRequest struct {
+	Name         string `json:"name" binding:"required"`
+	Formality    string `json:"formality"`
+	Domain       string `json:"domain"`
+	Instructions string `json:"instructions"`
+}
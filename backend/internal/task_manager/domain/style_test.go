@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTranslationStyle(t *testing.T) {
+	tests := []struct {
+		name         string
+		formality    string
+		domain       string
+		instructions string
+		expected     TranslationStyle
+		wantErr      bool
+	}{
+		{"default style", "", "", "", TranslationStyle{}, false},
+		{
+			"normalized", " Informal", "LITERARY ", " Keep the puns. ",
+			TranslationStyle{Formality: FormalityInformal, Domain: DomainLiterary, Instructions: "Keep the puns."}, false,
+		},
+		{"unknown formality", "casual", "", "", TranslationStyle{}, true},
+		{"unknown domain", "", "financial", "", TranslationStyle{}, true},
+		{"overlong instructions", "", "", strings.Repeat("译", MaxInstructionsLength+1), TranslationStyle{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got, err := ParseTranslationStyle(tt.formality, tt.domain, tt.instructions)
+				if tt.wantErr {
+					assert.ErrorIs(t, err, ErrInvalidStyle)
+				} else {
+					assert.NoError(t, err)
+				}
+				assert.Equal(t, tt.expected, got)
+			},
+		)
+	}
+}
+
+func TestTranslationStyleOverride(t *testing.T) {
+	profile := TranslationStyle{Formality: FormalityFormal, Domain: DomainLegal, Instructions: "Keep clause numbers."}
+	got := profile.Override(TranslationStyle{Formality: FormalityNeutral})
+	assert.Equal(
+		t, TranslationStyle{Formality: FormalityNeutral, Domain: DomainLegal, Instructions: "Keep clause numbers."}, got,
+	)
+}
@@ -0,0 +1,127 @@
+package domain
+
+import "errors"
+
+// LayoutTranslation renders only the translation.
+// LayoutAlternating renders every source block followed by its translation.
+// LayoutTable renders source and translation side by side in a two-column table.
+const (
+	LayoutTranslation = "translation"
+	LayoutAlternating = "alternating"
+	LayoutTable       = "table"
+)
+
+// FormatMarkdown renders the document as markdown.
+// FormatHTML renders the document as a standalone HTML page.
+const (
+	FormatMarkdown = "md"
+	FormatHTML     = "html"
+)
+
+// ErrInvalidOutput indicates that the requested output layout or format is not supported.
+var ErrInvalidOutput = errors.New(
+	"invalid output option, layout must be translation, alternating or table and format md or html",
+)
+
+// OutputOptions selects how the translated document is laid out and in which file format it is delivered.
+type OutputOptions struct {
+	Layout string `json:"layout"`
+	Format string `json:"format"`
+}
+
+// ParseOutputOptions validates the requested layout and format, applying LayoutTranslation and FormatMarkdown when
+// they are empty. Returns ErrInvalidOutput if either is not supported.
+func ParseOutputOptions(layout, format string) (OutputOptions, error) {
+	if layout == "" {
+		layout = LayoutTranslation
+	}
+	if format == "" {
+		format = FormatMarkdown
+	}
+	switch layout {
+	case LayoutTranslation, LayoutAlternating, LayoutTable:
+	default:
+		return OutputOptions{}, ErrInvalidOutput
+	}
+	switch format {
+	case FormatMarkdown, FormatHTML:
+	default:
+		return OutputOptions{}, ErrInvalidOutput
+	}
+	return OutputOptions{Layout: layout, Format: format}, nil
+}
+
+// IsBilingual reports whether the layout shows the source text next to its translation.
+func (o OutputOptions) IsBilingual() bool {
+	return o.Layout == LayoutAlternating || o.Layout == LayoutTable
+}
+
+// TaskOptions holds the settings a user selected when submitting a task.
+// Lang is the OCR language of the document, Glossary lists the terms to enforce during translation, and Output selects
+// how the result is rendered. Consistency asks for a term sheet to be extracted first and followed by every chunk.
+// ReviewTerms pauses the task after OCR until the user has reviewed the terms proposed for the document.
+// Style selects the formality, domain preset and custom instructions of the translation.
+type TaskOptions struct {
+	Lang        string           `json:"lang"`
+	Glossary    []GlossaryTerm   `json:"glossary,omitempty"`
+	Output      OutputOptions    `json:"output"`
+	Consistency bool             `json:"consistency"`
+	ReviewTerms bool             `json:"review_terms"`
+	Style       TranslationStyle `json:"style"`
+}
+
+// TranslationResult is the outcome of the OCR and translation stages of a task.
+// Text is the translated markdown, and GlossaryViolations lists glossary terms that were not translated as required.
+// MemoryHits counts the chunks reused from the translation memory out of ChunkCount chunks in total.
+// Usage reports the tokens spent on the translation.
+// Segments pairs every source chunk with its translation in document order.
+// TermSheet lists the key terms extracted in consistency mode with the translations used throughout the document.
+// QualityIssues lists the quality checks the translated chunks still fail, and RetriedChunks counts the chunks that
+// were retranslated because of failed checks.
+type TranslationResult struct {
+	Text               string
+	GlossaryViolations []GlossaryViolation
+	MemoryHits         int
+	ChunkCount         int
+	Usage              TranslationUsage
+	Segments           []Segment
+	TermSheet          []GlossaryTerm
+	QualityIssues      []QualityIssue
+	RetriedChunks      int
+}
+
+// QualityIssue reports a quality check the translation of a chunk failed, such as a wrong target language, a
+// suspicious length, an untranslated span or a missing number, with a human-readable detail.
+type QualityIssue struct {
+	ChunkIndex int    `json:"chunk_index"`
+	Check      string `json:"check"`
+	Detail     string `json:"detail"`
+}
+
+// Segment is a chunk of the source text aligned with its translation, and Backend names the backend that translated
+// it, which is empty for chunks taken from the translation memory.
+type Segment struct {
+	Source      string
+	Translation string
+	Backend     string
+}
+
+// TokenUsage counts the tokens a model consumed for prompts and produced in completions.
+type TokenUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// TranslationUsage reports the cost of translating a task: the model used, the wall time of the translation in
+// milliseconds, the total token usage and the token usage of every chunk in document order.
+// Backends names the backend that translated every chunk in document order, empty for chunks taken from the
+// translation memory, and FallbackChunks counts the chunks translated by a fallback backend instead of the primary one.
+type TranslationUsage struct {
+	Model          string       `json:"model"`
+	ElapsedMs      int64        `json:"elapsed_ms"`
+	Total          TokenUsage   `json:"total"`
+	Chunks         []TokenUsage `json:"chunks"`
+	Backends       []string     `json:"backends,omitempty"`
+	FallbackChunks int          `json:"fallback_chunks"`
+}
@@ -0,0 +1,60 @@
+package domain
+
+import "time"
+
+// StageOCR, StageReview, StageTranslation and StageUpload name the stages of the pipeline of a task.
+// StageReview only runs for tasks whose terms are reviewed, and lasts until the user confirms the review.
+const (
+	StageOCR         = "ocr"
+	StageReview      = "review"
+	StageTranslation = "translation"
+	StageUpload      = "upload"
+)
+
+// TaskStages lists the stages of the pipeline of a task in the order they run.
+var TaskStages = []string{StageOCR, StageReview, StageTranslation, StageUpload}
+
+// TaskFieldJob is the task field holding the JSON encoded job last queued for a task, which RetryTask queues again.
+// TaskFieldPageCount is the task field holding the number of pages of the document of a task.
+// TaskFieldPagesBilled and TaskFieldPagesRefunded are the task fields counting the pages billed for a task and the
+// pages refunded since, which never exceed the pages billed.
+// TaskFieldError is the task field holding the reason a failed task failed.
+// TaskFieldUsage is the task field holding the JSON encoded TranslationUsage of a translated task.
+const (
+	TaskFieldJob           = "job"
+	TaskFieldPageCount     = "page_count"
+	TaskFieldPagesBilled   = "pages_billed"
+	TaskFieldPagesRefunded = "pages_refunded"
+	TaskFieldError         = "error"
+	TaskFieldUsage         = "usage"
+)
+
+// TaskStage is the time a stage of a task started and the time it ended, which is nil while the stage runs or if it
+// failed.
+type TaskStage struct {
+	Name      string     `json:"name"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at"`
+}
+
+// TaskDetail is the full state of a single task.
+// Lang is the OCR language selected for the document, Pages the number of pages of the document once its OCR has run,
+// and PagesBilled and PagesRefunded the pages charged for the task and given back since.
+// Stages lists the stages that have started so far in pipeline order, Progress is the progress of the running stage
+// while the task recognizes or translates its text, and Error explains why a failed task failed. Usage reports the
+// cost of the translation of a translated task and the backend that translated every chunk.
+type TaskDetail struct {
+	ID            string            `json:"id"`
+	Filename      string            `json:"filename"`
+	Status        int               `json:"status"`
+	Link          string            `json:"link"`
+	CreatedAt     string            `json:"created_at"`
+	Lang          string            `json:"lang,omitempty"`
+	Pages         int               `json:"pages"`
+	PagesBilled   int               `json:"pages_billed"`
+	PagesRefunded int               `json:"pages_refunded"`
+	Stages        []TaskStage       `json:"stages"`
+	Progress      *TaskProgress     `json:"progress,omitempty"`
+	Error         string            `json:"error,omitempty"`
+	Usage         *TranslationUsage `json:"usage,omitempty"`
+}
@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+// TaskEventCreated reports a newly submitted task.
+// TaskEventStatus reports a change of the status of a task.
+// TaskEventStageStarted and TaskEventStageEnded report the start and end of a stage of the pipeline of a task.
+// TaskEventProgress reports the progress of the running stage of a task.
+// TaskEventLink reports the download link of a completed task.
+// TaskEventFailed reports the reason a task failed.
+const (
+	TaskEventCreated      = "created"
+	TaskEventStatus       = "status"
+	TaskEventStageStarted = "stage_started"
+	TaskEventStageEnded   = "stage_ended"
+	TaskEventProgress     = "progress"
+	TaskEventLink         = "link"
+	TaskEventFailed       = "failed"
+)
+
+// TaskEvent is a change of a task, pushed to the user of the task as it happens.
+// TaskID is the ID of the task as listed by the task list. Filename is set by created events, Status by created and
+// status events, Stage by stage events, Progress by progress events, Link by link events and Error by failed events.
+type TaskEvent struct {
+	Type     string        `json:"type"`
+	TaskID   string        `json:"task_id"`
+	Filename string        `json:"filename,omitempty"`
+	Status   *int          `json:"status,omitempty"`
+	Stage    string        `json:"stage,omitempty"`
+	Progress *TaskProgress `json:"progress,omitempty"`
+	Link     string        `json:"link,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	Time     time.Time     `json:"time"`
+}
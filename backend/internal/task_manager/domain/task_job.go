@@ -0,0 +1,29 @@
+package domain
+
+// JobProcessDocument runs OCR on an uploaded PDF and translates it, or proposes its terms for review.
+// JobTranslateReviewed translates the OCR text of a task whose term review has been confirmed.
+const (
+	JobProcessDocument   = "process_document"
+	JobTranslateReviewed = "translate_reviewed"
+)
+
+// TaskJob is the work of a task waiting in the task queue.
+// Kind selects the pipeline to run, and InputKey is the storage key of its input: the uploaded PDF for
+// JobProcessDocument and the reviewed OCR text for JobTranslateReviewed. Options are the settings of the task.
+// Resume marks the job of a retried task, which reuses the OCR text and translation result stored by its previous run.
+type TaskJob struct {
+	Kind     string      `json:"kind"`
+	TaskID   string      `json:"task_id"`
+	Username string      `json:"username"`
+	InputKey string      `json:"input_key"`
+	Options  TaskOptions `json:"options"`
+	Resume   bool        `json:"resume,omitempty"`
+}
+
+// QueuedJob is a TaskJob delivered by the task queue under its message ID, which acknowledges it once the job is
+// done. Deliveries counts how many times the job has been delivered to a worker, including this time.
+type QueuedJob struct {
+	ID         string
+	Job        TaskJob
+	Deliveries int64
+}
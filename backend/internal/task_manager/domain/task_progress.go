@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// ProgressPages and ProgressChunks name the units the progress of a task is counted in: the pages of its document
+// recognized by OCR and the chunks of its text translated.
+const (
+	ProgressPages  = "pages"
+	ProgressChunks = "chunks"
+)
+
+// ProgressStages maps every unit progress is counted in to the stage whose progress it counts.
+var ProgressStages = map[string]string{ProgressPages: StageOCR, ProgressChunks: StageTranslation}
+
+// TaskProgress is the progress of the running stage of a task, Done units out of Total. ETASeconds estimates the
+// seconds left until the stage ends, and is nil until the estimate can be made.
+type TaskProgress struct {
+	Unit       string `json:"unit"`
+	Done       int    `json:"done"`
+	Total      int    `json:"total"`
+	ETASeconds *int   `json:"eta_seconds,omitempty"`
+}
+
+// EstimateRemaining estimates the time left until done reaches total, assuming the remaining units progress at the
+// rate the units done so far have since startedAt. No estimate is made before the first unit is done or once all are.
+func EstimateRemaining(startedAt time.Time, done, total int, now time.Time) (time.Duration, bool) {
+	elapsed := now.Sub(startedAt)
+	if done <= 0 || done >= total || elapsed <= 0 {
+		return 0, false
+	}
+	return time.Duration(float64(elapsed) / float64(done) * float64(total-done)), true
+}
+
+// EstimateSeconds sets the ETASeconds of the progress from EstimateRemaining, rounding up to whole seconds, and leaves
+// it nil when no estimate can be made.
+func (p *TaskProgress) EstimateSeconds(startedAt time.Time, now time.Time) {
+	remaining, ok := EstimateRemaining(startedAt, p.Done, p.Total, now)
+	if !ok {
+		p.ETASeconds = nil
+		return
+	}
+	seconds := int(math.Ceil(remaining.Seconds()))
+	p.ETASeconds = &seconds
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateRemaining(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		now      time.Time
+		done     int
+		total    int
+		expected time.Duration
+		ok       bool
+	}{
+		{"quarter done", start.Add(10 * time.Second), 1, 4, 30 * time.Second, true},
+		{"half done", start.Add(time.Minute), 5, 10, time.Minute, true},
+		{"nothing done", start.Add(time.Minute), 0, 10, 0, false},
+		{"all done", start.Add(time.Minute), 10, 10, 0, false},
+		{"clock behind start", start.Add(-time.Second), 1, 10, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				remaining, ok := EstimateRemaining(start, tt.done, tt.total, tt.now)
+				assert.Equal(t, tt.ok, ok)
+				assert.Equal(t, tt.expected, remaining)
+			},
+		)
+	}
+}
+
+func TestTaskProgressEstimateSeconds(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	progress := TaskProgress{Unit: ProgressPages, Done: 3, Total: 4}
+	progress.EstimateSeconds(start, start.Add(10*time.Second))
+	if assert.NotNil(t, progress.ETASeconds) {
+		assert.Equal(t, 4, *progress.ETASeconds)
+	}
+
+	progress.Done = 4
+	progress.EstimateSeconds(start, start.Add(10*time.Second))
+	assert.Nil(t, progress.ETASeconds)
+}
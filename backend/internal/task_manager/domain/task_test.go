@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOutputOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		layout   string
+		format   string
+		expected OutputOptions
+		wantErr  bool
+	}{
+		{"defaults", "", "", OutputOptions{Layout: LayoutTranslation, Format: FormatMarkdown}, false},
+		{"bilingual html", "table", "html", OutputOptions{Layout: LayoutTable, Format: FormatHTML}, false},
+		{"unknown layout", "columns", "md", OutputOptions{}, true},
+		{"unknown format", "alternating", "pdf", OutputOptions{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got, err := ParseOutputOptions(tt.layout, tt.format)
+				if tt.wantErr {
+					assert.ErrorIs(t, err, ErrInvalidOutput)
+				} else {
+					assert.NoError(t, err)
+				}
+				assert.Equal(t, tt.expected, got)
+			},
+		)
+	}
+}
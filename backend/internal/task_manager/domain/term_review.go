@@ -0,0 +1,44 @@
+package domain
+
+import "strings"
+
+// ReviewTerm is a term proposed for a document together with its translation, as reviewed by the user.
+// Locked terms are enforced like glossary terms when the document is translated, while unlocked terms are discarded.
+type ReviewTerm struct {
+	Source string `json:"source" binding:"required"`
+	Target string `json:"target" binding:"required"`
+	Locked bool   `json:"locked"`
+}
+
+// TermReview is the state of a task waiting for the review of its terms: the OCR text of the document, the options
+// selected when the task was submitted and the terms under review.
+type TermReview struct {
+	Text    string
+	Options TaskOptions
+	Terms   []ReviewTerm
+}
+
+// ReviewedOptions returns the options of the task with the locked terms added to its glossary. A locked term replaces
+// a glossary term with the same source, compared case-insensitively, since it was chosen for this very document.
+func (r *TermReview) ReviewedOptions() TaskOptions {
+	options := r.Options
+	locked := make(map[string]bool)
+	for _, term := range r.Terms {
+		if term.Locked {
+			locked[strings.ToLower(term.Source)] = true
+		}
+	}
+	var glossary []GlossaryTerm
+	for _, term := range r.Options.Glossary {
+		if !locked[strings.ToLower(term.Source)] {
+			glossary = append(glossary, term)
+		}
+	}
+	for _, term := range r.Terms {
+		if term.Locked {
+			glossary = append(glossary, GlossaryTerm{Source: term.Source, Target: term.Target})
+		}
+	}
+	options.Glossary = glossary
+	return options
+}
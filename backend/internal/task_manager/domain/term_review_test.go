@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReviewedOptions(t *testing.T) {
+	review := &TermReview{
+		Options: TaskOptions{
+			Lang:     "eng",
+			Glossary: []GlossaryTerm{{ID: 1, Source: "plaintiff", Target: "原告"}, {ID: 2, Source: "GDPR", Target: "GDPR"}},
+		},
+		Terms: []ReviewTerm{
+			{Source: "gdpr", Target: "通用数据保护条例", Locked: true},
+			{Source: "Acme Corp", Target: "艾克米公司", Locked: true},
+			{Source: "data controller", Target: "数据控制者"},
+		},
+	}
+
+	options := review.ReviewedOptions()
+	assert.Equal(t, "eng", options.Lang)
+	assert.Equal(
+		t, []GlossaryTerm{
+			{ID: 1, Source: "plaintiff", Target: "原告"},
+			{Source: "gdpr", Target: "通用数据保护条例"},
+			{Source: "Acme Corp", Target: "艾克米公司"},
+		}, options.Glossary,
+	)
+	assert.Len(t, review.Options.Glossary, 2)
+}
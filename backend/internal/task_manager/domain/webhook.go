@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// WebhookTaskCompleted and WebhookTaskFailed are the events webhooks are notified of: a task completed with its
+// download link, and a task failed with the reason it failed.
+const (
+	WebhookTaskCompleted = "task.completed"
+	WebhookTaskFailed    = "task.failed"
+)
+
+// DeliveryPending marks a webhook delivery waiting for its next attempt.
+// DeliveryDelivered marks a webhook delivery the receiver accepted with a 2xx response.
+// DeliveryFailed marks a webhook delivery given up after its last attempt failed.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// Webhook is a URL registered by a user to receive a POST request when one of their tasks completes or fails.
+type Webhook struct {
+	ID        int       `json:"id"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookRequest represents the structure for registering a webhook.
+type WebhookRequest struct {
+	URL string `json:"url" binding:"required"`
+}
+
+// WebhookPayload is the JSON body posted to webhooks: the event, the detail of the task at the time of the event,
+// including its download link or the reason it failed, and the time of the event.
+type WebhookPayload struct {
+	Event string      `json:"event"`
+	Task  *TaskDetail `json:"task"`
+	Time  time.Time   `json:"time"`
+}
+
+// WebhookDelivery is an entry of the delivery log of the webhooks of a user: a payload to post to a webhook and the
+// outcome of the attempts made so far. NextAttemptAt is when a pending delivery is attempted next, LastStatusCode and
+// LastError describe the outcome of the last failed attempt, and DeliveredAt is set once the receiver accepted the
+// payload. ReplayOf is the ID of the delivery a replayed delivery was copied from.
+type WebhookDelivery struct {
+	ID             int             `json:"id"`
+	WebhookID      int             `json:"webhook_id"`
+	URL            string          `json:"url"`
+	Username       string          `json:"-"`
+	Event          string          `json:"event"`
+	TaskID         string          `json:"task_id"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastStatusCode int             `json:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	ReplayOf       *int            `json:"replay_of,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+}
+
+// DeliveryFilter selects the entries of the delivery log of a user: those of the webhook WebhookID, or of all webhooks
+// if it is zero, with the given Status, or any status if it is empty, newest first and at most Limit of them.
+type DeliveryFilter struct {
+	WebhookID int
+	Status    string
+	Limit     int
+}
+
+// SignWebhookPayload returns the hex encoded HMAC-SHA256 of the payload posted at the given Unix timestamp, keyed
+// with the webhook secret of the user. The timestamp is signed along with the payload, as "<timestamp>.<payload>", so
+// that receivers can reject payloads replayed long after they were signed.
+func SignWebhookPayload(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
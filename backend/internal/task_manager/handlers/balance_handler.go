@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/oOSomnus/transflate/internal/task_manager/domain"
+	"github.com/oOSomnus/transflate/internal/task_manager/usecase"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// errInvalidTransactionFilter is the response message for transaction history queries with a malformed limit,
+// offset or date.
+// errTransactionFailure is the response message for unexpected errors while listing balance transactions.
+const (
+	errInvalidTransactionFilter = "Invalid transaction filter"
+	errTransactionFailure       = "Failed to list balance transactions"
+)
+
+// transactionDateLayout is the layout of dates without a time accepted by the from and to query parameters.
+const transactionDateLayout = "2006-01-02"
+
+// BalanceHandler defines methods for handling the balance HTTP requests of the authenticated user.
+// Transactions lists the transaction history of the balance.
+type BalanceHandler interface {
+	Transactions(c *gin.Context)
+}
+
+// BalanceHandlerImpl handles HTTP requests related to balances, delegating logic to the associated BalanceUsecase.
+type BalanceHandlerImpl struct {
+	Usecase usecase.BalanceUsecase
+}
+
+// NewBalanceHandler initializes and returns a new instance of BalanceHandlerImpl with the provided BalanceUsecase.
+func NewBalanceHandler(u usecase.BalanceUsecase) *BalanceHandlerImpl {
+	return &BalanceHandlerImpl{Usecase: u}
+}
+
+// Transactions responds with the balance transactions of the authenticated user, newest first, along with their total
+// number. The limit and offset query parameters page through them, and the from and to query parameters, either
+// RFC 3339 times or dates, select the transactions created from and until then. A date in to includes the whole day.
+func (h *BalanceHandlerImpl) Transactions(c *gin.Context) {
+	usernameStr, err := getAuthenticatedUsername(c)
+	if err != nil {
+		handleError(c, http.StatusUnauthorized, errUserUnauthorized)
+		return
+	}
+	var filter domain.TransactionFilter
+	if limit := c.Query("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil || filter.Limit <= 0 {
+			handleError(c, http.StatusBadRequest, errInvalidTransactionFilter)
+			return
+		}
+	}
+	if offset := c.Query("offset"); offset != "" {
+		if filter.Offset, err = strconv.Atoi(offset); err != nil || filter.Offset < 0 {
+			handleError(c, http.StatusBadRequest, errInvalidTransactionFilter)
+			return
+		}
+	}
+	if filter.From, err = parseTransactionTime(c.Query("from"), false); err != nil {
+		handleError(c, http.StatusBadRequest, errInvalidTransactionFilter)
+		return
+	}
+	if filter.To, err = parseTransactionTime(c.Query("to"), true); err != nil {
+		handleError(c, http.StatusBadRequest, errInvalidTransactionFilter)
+		return
+	}
+	transactions, total, err := h.Usecase.ListTransactions(usernameStr, filter)
+	if err != nil {
+		if err.Error() == usecase.ErrInvalidTransactionRange {
+			handleError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Printf("Error listing balance transactions: %v", err)
+		handleError(c, http.StatusInternalServerError, errTransactionFailure)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": transactions, "total": total})
+}
+
+// parseTransactionTime parses an RFC 3339 time or a date, returning the zero time for an empty value. A date is the
+// start of that day in UTC, or the start of the next day if endOfDay is set, since the end of the range is exclusive.
+func parseTransactionTime(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(transactionDateLayout, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/oOSomnus/transflate/internal/task_manager/domain"
+	"github.com/oOSomnus/transflate/internal/task_manager/repository"
+	"github.com/oOSomnus/transflate/internal/task_manager/usecase"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// errGlossaryNotFound is the response message for glossaries that do not exist or belong to another user.
+// errGlossaryTermNotFound is the response message for terms that do not exist in the glossary.
+// errInvalidGlossaryId is the response message for glossary or term IDs that are not positive integers.
+// errGlossaryFailure is the response message for unexpected errors while accessing glossaries.
+const (
+	errGlossaryNotFound     = "Glossary not found"
+	errGlossaryTermNotFound = "Glossary term not found"
+	errInvalidGlossaryId    = "Invalid glossary ID"
+	errGlossaryFailure      = "Failed to access glossary"
+)
+
+// GlossaryHandler defines methods for handling glossary-related HTTP requests of the authenticated user.
+// List, Create, Get, Update and Delete manage whole glossaries.
+// AddTerms and DeleteTerm manage single terms of a glossary.
+// Import adds the terms of an uploaded CSV or TBX file to a glossary.
+type GlossaryHandler interface {
+	List(c *gin.Context)
+	Create(c *gin.Context)
+	Get(c *gin.Context)
+	Update(c *gin.Context)
+	Delete(c *gin.Context)
+	AddTerms(c *gin.Context)
+	DeleteTerm(c *gin.Context)
+	Import(c *gin.Context)
+}
+
+// GlossaryHandlerImpl handles HTTP requests related to glossaries, delegating logic to the associated GlossaryUsecase.
+type GlossaryHandlerImpl struct {
+	Usecase usecase.GlossaryUsecase
+}
+
+// NewGlossaryHandler initializes and returns a new instance of GlossaryHandlerImpl with the provided GlossaryUsecase.
+func NewGlossaryHandler(u usecase.GlossaryUsecase) *GlossaryHandlerImpl {
+	return &GlossaryHandlerImpl{Usecase: u}
+}
+
+// List responds with all glossaries of the authenticated user, without their terms.
+func (h *GlossaryHandlerImpl) List(c *gin.Context) {
+	usernameStr, err := getAuthenticatedUsername(c)
+	if err != nil {
+		handleError(c, http.StatusUnauthorized, errUserUnauthorized)
+		return
+	}
+	glossaries, err := h.Usecase.ListGlossaries(usernameStr)
+	if err != nil {
+		log.Printf("Error listing glossaries: %v", err)
+		handleError(c, http.StatusInternalServerError, errGlossaryFailure)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": glossaries})
+}
+
+// Create creates a glossary for the authenticated user from a JSON body with a name and optional terms.
+func (h *GlossaryHandlerImpl) Create(c *gin.Context) {
+	usernameStr, err := getAuthenticatedUsername(c)
+	if err != nil {
+		handleError(c, http.StatusUnauthorized, errUserUnauthorized)
+		return
+	}
+	var req domain.GlossaryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleError(c, http.StatusBadRequest, errInvalidRequest)
+		return
+	}
+	glossary, err := h.Usecase.CreateGlossary(usernameStr, req)
+	if err != nil {
+		h.handleGlossaryError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": glossary})
+}
+
+// Get responds with a glossary of the authenticated user including all of its terms.
+func (h *GlossaryHandlerImpl) Get(c *gin.Context) {
+	usernameStr, glossaryId, ok := glossaryParams(c)
+	if !ok {
+		return
+	}
+	glossary, err := h.Usecase.GetGlossary(usernameStr, glossaryId)
+	if err != nil {
+		h.handleGlossaryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": glossary})
+}
+
+// Update renames a glossary of the authenticated user and replaces all of its terms with those in the JSON body.
+func (h *GlossaryHandlerImpl) Update(c *gin.Context) {
+	usernameStr, glossaryId, ok := glossaryParams(c)
+	if !ok {
+		return
+	}
+	var req domain.GlossaryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleError(c, http.StatusBadRequest, errInvalidRequest)
+		return
+	}
+	if err := h.Usecase.UpdateGlossary(usernameStr, glossaryId, req); err != nil {
+		h.handleGlossaryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": "ok"})
+}
+
+// Delete removes a glossary of the authenticated user together with its terms.
+func (h *GlossaryHandlerImpl) Delete(c *gin.Context) {
+	usernameStr, glossaryId, ok := glossaryParams(c)
+	if !ok {
+		return
+	}
+	if err := h.Usecase.DeleteGlossary(usernameStr, glossaryId); err != nil {
+		h.handleGlossaryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": "ok"})
+}
+
+// AddTerms adds the terms of the JSON array body to a glossary of the authenticated user.
+func (h *GlossaryHandlerImpl) AddTerms(c *gin.Context) {
+	usernameStr, glossaryId, ok := glossaryParams(c)
+	if !ok {
+		return
+	}
+	var terms []domain.GlossaryTerm
+	if err := c.ShouldBindJSON(&terms); err != nil {
+		handleError(c, http.StatusBadRequest, errInvalidRequest)
+		return
+	}
+	if err := h.Usecase.AddTerms(usernameStr, glossaryId, terms); err != nil {
+		h.handleGlossaryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": "ok"})
+}
+
+// DeleteTerm removes a single term from a glossary of the authenticated user.
+func (h *GlossaryHandlerImpl) DeleteTerm(c *gin.Context) {
+	usernameStr, glossaryId, ok := glossaryParams(c)
+	if !ok {
+		return
+	}
+	termId, err := strconv.Atoi(c.Param("termId"))
+	if err != nil || termId <= 0 {
+		handleError(c, http.StatusBadRequest, errInvalidGlossaryId)
+		return
+	}
+	if err := h.Usecase.DeleteTerm(usernameStr, glossaryId, termId); err != nil {
+		h.handleGlossaryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": "ok"})
+}
+
+// Import adds the terms of the uploaded "file" form field to a glossary of the authenticated user.
+// The format is taken from the "format" form field, or from the file extension (.csv or .tbx) if it is not set.
+func (h *GlossaryHandlerImpl) Import(c *gin.Context) {
+	usernameStr, glossaryId, ok := glossaryParams(c)
+	if !ok {
+		return
+	}
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		handleError(c, http.StatusBadRequest, "invalid glossary file")
+		return
+	}
+	format := c.PostForm("format")
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(fileHeader.Filename), ".")
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		handleError(c, http.StatusBadRequest, "failed to read glossary file")
+		return
+	}
+	defer file.Close()
+
+	imported, err := h.Usecase.ImportTerms(usernameStr, glossaryId, format, file)
+	if err != nil {
+		h.handleGlossaryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"imported": imported}})
+}
+
+// glossaryParams retrieves the authenticated username and the glossary ID path parameter of the request.
+// It responds with 401 or 400 and returns false if either is missing or invalid.
+func glossaryParams(c *gin.Context) (string, int, bool) {
+	usernameStr, err := getAuthenticatedUsername(c)
+	if err != nil {
+		handleError(c, http.StatusUnauthorized, errUserUnauthorized)
+		return "", 0, false
+	}
+	glossaryId, err := strconv.Atoi(c.Param("id"))
+	if err != nil || glossaryId <= 0 {
+		handleError(c, http.StatusBadRequest, errInvalidGlossaryId)
+		return "", 0, false
+	}
+	return usernameStr, glossaryId, true
+}
+
+// handleGlossaryError maps errors of the glossary usecase to HTTP responses.
+// Missing glossaries and terms yield 404, parsing and validation errors yield 400, and anything else yields 500.
+func (h *GlossaryHandlerImpl) handleGlossaryError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrGlossaryNotFound):
+		handleError(c, http.StatusNotFound, errGlossaryNotFound)
+	case errors.Is(err, repository.ErrGlossaryTermNotFound):
+		handleError(c, http.StatusNotFound, errGlossaryTermNotFound)
+	case isGlossaryInputError(err):
+		handleError(c, http.StatusBadRequest, err.Error())
+	default:
+		log.Printf("Glossary error: %v", err)
+		handleError(c, http.StatusInternalServerError, errGlossaryFailure)
+	}
+}
+
+// isGlossaryInputError reports whether err was caused by invalid user input rather than a storage failure.
+func isGlossaryInputError(err error) bool {
+	switch err.Error() {
+	case usecase.ErrInvalidGlossary, usecase.ErrUnsupportedFormat, usecase.ErrEmptyImport:
+		return true
+	}
+	return strings.HasPrefix(err.Error(), "invalid csv glossary") || strings.HasPrefix(err.Error(), "invalid tbx glossary")
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/oOSomnus/transflate/internal/task_manager/domain"
+	"github.com/oOSomnus/transflate/internal/task_manager/repository"
+	"github.com/oOSomnus/transflate/internal/task_manager/usecase"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// errStyleProfileNotFound is the response message for style profiles that do not exist or belong to another user.
+// errInvalidStyleProfileId is the response message for style profile IDs that are not positive integers.
+// errStyleProfileFailure is the response message for unexpected errors while accessing style profiles.
+const (
+	errStyleProfileNotFound  = "Style profile not found"
+	errInvalidStyleProfileId = "Invalid style profile ID"
+	errStyleProfileFailure   = "Failed to access style profile"
+)
+
+// StyleProfileHandler defines methods for handling style profile HTTP requests of the authenticated user.
+// List, Create, Get, Update and Delete manage whole style profiles.
+type StyleProfileHandler interface {
+	List(c *gin.Context)
+	Create(c *gin.Context)
+	Get(c *gin.Context)
+	Update(c *gin.Context)
+	Delete(c *gin.Context)
+}
+
+// StyleProfileHandlerImpl handles HTTP requests related to style profiles, delegating logic to the associated
+// StyleProfileUsecase.
+type StyleProfileHandlerImpl struct {
+	Usecase usecase.StyleProfileUsecase
+}
+
+// NewStyleProfileHandler initializes and returns a new instance of StyleProfileHandlerImpl with the provided
+// StyleProfileUsecase.
+func NewStyleProfileHandler(u usecase.StyleProfileUsecase) *StyleProfileHandlerImpl {
+	return &StyleProfileHandlerImpl{Usecase: u}
+}
+
+// List responds with all style profiles of the authenticated user.
+func (h *StyleProfileHandlerImpl) List(c *gin.Context) {
+	usernameStr, err := getAuthenticatedUsername(c)
+	if err != nil {
+		handleError(c, http.StatusUnauthorized, errUserUnauthorized)
+		return
+	}
+	profiles, err := h.Usecase.ListStyleProfiles(usernameStr)
+	if err != nil {
+		log.Printf("Error listing style profiles: %v", err)
+		handleError(c, http.StatusInternalServerError, errStyleProfileFailure)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": profiles})
+}
+
+// Create creates a style profile for the authenticated user from a JSON body with a name and the style fields.
+func (h *StyleProfileHandlerImpl) Create(c *gin.Context) {
+	usernameStr, err := getAuthenticatedUsername(c)
+	if err != nil {
+		handleError(c, http.StatusUnauthorized, errUserUnauthorized)
+		return
+	}
+	var req domain.StyleProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleError(c, http.StatusBadRequest, errInvalidRequest)
+		return
+	}
+	profile, err := h.Usecase.CreateStyleProfile(usernameStr, req)
+	if err != nil {
+		h.handleStyleProfileError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": profile})
+}
+
+// Get responds with a style profile of the authenticated user.
+func (h *StyleProfileHandlerImpl) Get(c *gin.Context) {
+	usernameStr, profileId, ok := styleProfileParams(c)
+	if !ok {
+		return
+	}
+	profile, err := h.Usecase.GetStyleProfile(usernameStr, profileId)
+	if err != nil {
+		h.handleStyleProfileError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": profile})
+}
+
+// Update replaces the name and style of a style profile of the authenticated user with those in the JSON body.
+func (h *StyleProfileHandlerImpl) Update(c *gin.Context) {
+	usernameStr, profileId, ok := styleProfileParams(c)
+	if !ok {
+		return
+	}
+	var req domain.StyleProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleError(c, http.StatusBadRequest, errInvalidRequest)
+		return
+	}
+	if err := h.Usecase.UpdateStyleProfile(usernameStr, profileId, req); err != nil {
+		h.handleStyleProfileError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": "ok"})
+}
+
+// Delete removes a style profile of the authenticated user.
+func (h *StyleProfileHandlerImpl) Delete(c *gin.Context) {
+	usernameStr, profileId, ok := styleProfileParams(c)
+	if !ok {
+		return
+	}
+	if err := h.Usecase.DeleteStyleProfile(usernameStr, profileId); err != nil {
+		h.handleStyleProfileError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": "ok"})
+}
+
+// styleProfileParams retrieves the authenticated username and the style profile ID path parameter of the request.
+// It responds with 401 or 400 and returns false if either is missing or invalid.
+func styleProfileParams(c *gin.Context) (string, int, bool) {
+	usernameStr, err := getAuthenticatedUsername(c)
+	if err != nil {
+		handleError(c, http.StatusUnauthorized, errUserUnauthorized)
+		return "", 0, false
+	}
+	profileId, err := strconv.Atoi(c.Param("id"))
+	if err != nil || profileId <= 0 {
+		handleError(c, http.StatusBadRequest, errInvalidStyleProfileId)
+		return "", 0, false
+	}
+	return usernameStr, profileId, true
+}
+
+// handleStyleProfileError maps errors of the style profile usecase to HTTP responses.
+// Missing profiles yield 404, validation errors yield 400, and anything else yields 500.
+func (h *StyleProfileHandlerImpl) handleStyleProfileError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrStyleProfileNotFound):
+		handleError(c, http.StatusNotFound, errStyleProfileNotFound)
+	case errors.Is(err, domain.ErrInvalidStyle) || err.Error() == usecase.ErrInvalidStyleProfile:
+		handleError(c, http.StatusBadRequest, err.Error())
+	default:
+		log.Printf("Style profile error: %v", err)
+		handleError(c, http.StatusInternalServerError, errStyleProfileFailure)
+	}
+}
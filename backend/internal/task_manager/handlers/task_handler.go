@@ -1,14 +1,20 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/oOSomnus/transflate/internal/task_manager/domain"
+	"github.com/oOSomnus/transflate/internal/task_manager/repository"
 	"github.com/oOSomnus/transflate/internal/task_manager/service"
 	"github.com/oOSomnus/transflate/internal/task_manager/usecase"
 	"github.com/oOSomnus/transflate/pkg/utils"
+	"io"
 	"log"
 	"net/http"
 	"path/filepath"
+	"strconv"
+	"time"
 )
 
 // init initializes the log package with specific flags and a custom prefix for task handler logging.
@@ -20,23 +26,60 @@ func init() {
 // TaskHandler defines an interface for handling task-related operations.
 // TaskSubmit processes the submission of a task from the request context.
 // TaskStatusCheckHandler retrieves the status of a task based on the request context.
+// TaskDetail responds with the full state of a single task.
+// StreamTasks pushes the events of the tasks of the user as they happen.
+// TaskTerms, UpdateTaskTerms and ConfirmTaskTerms let the user review the terms of a task waiting for review.
+// CancelTask stops a task that has not finished yet, and RetryTask resumes a failed task.
 type TaskHandler interface {
 	TaskSubmit(c *gin.Context)
 	TaskStatusCheckHandler(c *gin.Context)
+	TaskDetail(c *gin.Context)
+	StreamTasks(c *gin.Context)
+	TaskTerms(c *gin.Context)
+	UpdateTaskTerms(c *gin.Context)
+	ConfirmTaskTerms(c *gin.Context)
+	CancelTask(c *gin.Context)
+	RetryTask(c *gin.Context)
 }
 
+// errTaskNotFound is the response message for tasks that do not exist or have expired.
+// errTermReviewFailure is the response message for unexpected errors while reviewing the terms of a task.
+// errTaskCancelFailure is the response message for unexpected errors while cancelling a task.
+// errTaskQueueFailure is the response message for tasks that cannot be queued, and the reason recorded on them.
+// errTaskDetailFailure is the response message for unexpected errors while retrieving the detail of a task.
+// errTaskStreamFailure is the response message for task event streams that cannot be opened.
+// errInsufficientBalance is the response message for retried tasks whose pages the balance no longer covers.
+const (
+	errTaskNotFound        = "Task not found"
+	errTermReviewFailure   = "Failed to access term review"
+	errTaskCancelFailure   = "Failed to cancel task"
+	errTaskQueueFailure    = "Failed to queue task"
+	errTaskDetailFailure   = "Failed to get task"
+	errTaskStreamFailure   = "Failed to stream task events"
+	errInsufficientBalance = "Insufficient balance"
+)
+
+// streamHeartbeatInterval is how often a comment is sent on an idle task event stream, so that proxies do not close it.
+const streamHeartbeatInterval = 15 * time.Second
+
 // TaskHandlerImpl handles task-related operations, connecting the use case and task status service layers.
+// GlossaryUsecase resolves the glossary and StyleProfileUsecase the style profile selected for a task.
 type TaskHandlerImpl struct {
-	Usecase           usecase.TaskUsecase
-	TaskStatusService service.TaskStatusService
+	Usecase             usecase.TaskUsecase
+	TaskStatusService   service.TaskStatusService
+	GlossaryUsecase     usecase.GlossaryUsecase
+	StyleProfileUsecase usecase.StyleProfileUsecase
 }
 
-// NewTaskHandler initializes and returns a new instance of TaskHandlerImpl with the provided usecase and service.
-func NewTaskHandler(u usecase.TaskUsecase, tss service.TaskStatusService) *TaskHandlerImpl {
-	return &TaskHandlerImpl{Usecase: u, TaskStatusService: tss}
+// NewTaskHandler initializes and returns a new instance of TaskHandlerImpl with the provided usecases and service.
+func NewTaskHandler(
+	u usecase.TaskUsecase, tss service.TaskStatusService, gu usecase.GlossaryUsecase, su usecase.StyleProfileUsecase,
+) *TaskHandlerImpl {
+	return &TaskHandlerImpl{Usecase: u, TaskStatusService: tss, GlossaryUsecase: gu, StyleProfileUsecase: su}
 }
 
-// TaskSubmit handles the submission of a task, including file upload, processing, status updates, and download link generation.
+// TaskSubmit handles the submission of a task: the uploaded file and the options of the task are validated, and the task
+// is created and queued for a worker, which processes it, updates its status and generates its download link.
 func (h *TaskHandlerImpl) TaskSubmit(c *gin.Context) {
 	log.Println("Processing new task submission...")
 
@@ -52,58 +95,256 @@ func (h *TaskHandlerImpl) TaskSubmit(c *gin.Context) {
 		return
 	}
 
-	lang := c.DefaultPostForm("lang", "eng")
-
-	taskId, err := h.TaskStatusService.CreateNewTask(usernameStr, fileName)
+	output, err := domain.ParseOutputOptions(c.PostForm("output"), c.PostForm("format"))
 	if err != nil {
-		handleError(c, http.StatusInternalServerError, "Failed to create new task")
+		handleError(c, http.StatusBadRequest, err.Error())
 		return
 	}
-
-	log.Printf("Created new task with ID %s", taskId)
-	c.JSON(http.StatusOK, gin.H{"data": "ok"})
-	go func() {
-		err = h.TaskStatusService.UpdateTaskStatus(usernameStr, taskId, service.Translating)
+	options := domain.TaskOptions{Lang: c.DefaultPostForm("lang", "eng"), Output: output}
+	if consistencyStr := c.PostForm("consistency"); consistencyStr != "" {
+		options.Consistency, err = strconv.ParseBool(consistencyStr)
 		if err != nil {
-			log.Printf(
-				"Error updating task status: %v", err,
-			)
-			handleTaskStatusError(usernameStr, taskId, h.TaskStatusService)
+			handleError(c, http.StatusBadRequest, "invalid consistency option")
 			return
 		}
-		// Process OCR and Translation
-		transResponse, err := h.Usecase.ProcessOCRAndTranslate(usernameStr, fileContent, lang)
+	}
+	if reviewStr := c.PostForm("review_terms"); reviewStr != "" {
+		options.ReviewTerms, err = strconv.ParseBool(reviewStr)
 		if err != nil {
-			log.Printf("Error processing OCR and translation: %v", err)
-			handleTaskStatusError(usernameStr, taskId, h.TaskStatusService)
+			handleError(c, http.StatusBadRequest, "invalid review_terms option")
 			return
 		}
-		err = h.TaskStatusService.UpdateTaskStatus(usernameStr, taskId, service.Uploading)
+	}
+	if glossaryIdStr := c.PostForm("glossary_id"); glossaryIdStr != "" {
+		glossary, status, err := h.resolveGlossary(usernameStr, glossaryIdStr)
 		if err != nil {
-			log.Printf("Error updating task status: %v", err)
-			handleTaskStatusError(usernameStr, taskId, h.TaskStatusService)
+			handleError(c, status, err.Error())
 			return
 		}
-		// Create download link
-		downLink, err := h.Usecase.CreateDownloadLinkWithMdString(transResponse)
+		options.Glossary = glossary.Terms
+	}
+	options.Style, err = domain.ParseTranslationStyle(
+		c.PostForm("formality"), c.PostForm("domain"), c.PostForm("instructions"),
+	)
+	if err != nil {
+		handleError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if profileIdStr := c.PostForm("style_profile_id"); profileIdStr != "" {
+		profile, status, err := h.resolveStyleProfile(usernameStr, profileIdStr)
 		if err != nil {
-			log.Printf("Error generating download link: %v", err)
-			handleTaskStatusError(usernameStr, taskId, h.TaskStatusService)
+			handleError(c, status, err.Error())
 			return
 		}
-		err = h.TaskStatusService.UpdateTaskStatus(usernameStr, taskId, service.Done)
-		if err != nil {
-			log.Printf("Error updating task status: %v", err)
-			handleTaskStatusError(usernameStr, taskId, h.TaskStatusService)
-			return
+		// Style options given with the task take precedence over the profile
+		options.Style = profile.TranslationStyle.Override(options.Style)
+	}
+
+	taskId, err := h.TaskStatusService.CreateNewTask(usernameStr, fileName)
+	if err != nil {
+		handleError(c, http.StatusInternalServerError, "Failed to create new task")
+		return
+	}
+
+	job := domain.TaskJob{Kind: domain.JobProcessDocument, TaskID: taskId, Username: usernameStr, Options: options}
+	if err := h.Usecase.EnqueueTask(job, fileContent, ".pdf"); err != nil {
+		log.Printf("Error enqueueing task: %v", err)
+		handleTaskStatusError(usernameStr, taskId, errTaskQueueFailure, h.TaskStatusService)
+		handleError(c, http.StatusInternalServerError, errTaskQueueFailure)
+		return
+	}
+
+	log.Printf("Queued new task with ID %s", taskId)
+	c.JSON(http.StatusOK, gin.H{"data": "ok"})
+}
+
+// TaskTerms responds with the terms under review of a task of the authenticated user.
+// The task is identified by the ID listed by TaskStatusCheckHandler.
+func (h *TaskHandlerImpl) TaskTerms(c *gin.Context) {
+	usernameStr, err := getAuthenticatedUsername(c)
+	if err != nil {
+		handleError(c, http.StatusUnauthorized, errUserUnauthorized)
+		return
+	}
+	review, err := h.TaskStatusService.GetTermReview(usernameStr, usernameStr+"-"+c.Param("id"))
+	if err != nil {
+		handleTermReviewError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": review.Terms})
+}
+
+// UpdateTaskTerms replaces the terms under review of a task of the authenticated user with the JSON array body, in
+// which the user edits translations, adds terms of their own and locks the terms to enforce.
+func (h *TaskHandlerImpl) UpdateTaskTerms(c *gin.Context) {
+	usernameStr, err := getAuthenticatedUsername(c)
+	if err != nil {
+		handleError(c, http.StatusUnauthorized, errUserUnauthorized)
+		return
+	}
+	var terms []domain.ReviewTerm
+	if err := c.ShouldBindJSON(&terms); err != nil {
+		handleError(c, http.StatusBadRequest, errInvalidRequest)
+		return
+	}
+	if err := h.TaskStatusService.UpdateReviewTerms(usernameStr, usernameStr+"-"+c.Param("id"), terms); err != nil {
+		handleTermReviewError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": "ok"})
+}
+
+// ConfirmTaskTerms ends the term review of a task of the authenticated user and queues the task again: the locked terms
+// are added to its glossary and the document is translated, uploaded and delivered as for any other task.
+func (h *TaskHandlerImpl) ConfirmTaskTerms(c *gin.Context) {
+	usernameStr, err := getAuthenticatedUsername(c)
+	if err != nil {
+		handleError(c, http.StatusUnauthorized, errUserUnauthorized)
+		return
+	}
+	taskId := usernameStr + "-" + c.Param("id")
+	review, err := h.TaskStatusService.ConfirmTermReview(usernameStr, taskId)
+	if err != nil {
+		handleTermReviewError(c, err)
+		return
+	}
+
+	job := domain.TaskJob{
+		Kind: domain.JobTranslateReviewed, TaskID: taskId, Username: usernameStr, Options: review.ReviewedOptions(),
+	}
+	if err := h.Usecase.EnqueueTask(job, []byte(review.Text), ".txt"); err != nil {
+		log.Printf("Error enqueueing reviewed task: %v", err)
+		handleTaskStatusError(usernameStr, taskId, errTaskQueueFailure, h.TaskStatusService)
+		handleError(c, http.StatusInternalServerError, errTaskQueueFailure)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": "ok"})
+}
+
+// CancelTask cancels a task of the authenticated user that is queued, in progress or waiting for its term review, and
+// refunds the pages billed for it. The worker processing the task aborts its OCR and translation, and refunds the
+// pages it bills while the cancellation is on its way.
+// Responds with 404 if the task does not exist and 409 if it is already being delivered, done, failed or cancelled.
+func (h *TaskHandlerImpl) CancelTask(c *gin.Context) {
+	usernameStr, err := getAuthenticatedUsername(c)
+	if err != nil {
+		handleError(c, http.StatusUnauthorized, errUserUnauthorized)
+		return
+	}
+	taskId := usernameStr + "-" + c.Param("id")
+	if err := h.TaskStatusService.CancelTask(usernameStr, taskId); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrTaskNotFound):
+			handleError(c, http.StatusNotFound, errTaskNotFound)
+		case errors.Is(err, service.ErrTaskFinished):
+			handleError(c, http.StatusConflict, err.Error())
+		default:
+			log.Printf("Error cancelling task %s: %v", taskId, err)
+			handleError(c, http.StatusInternalServerError, errTaskCancelFailure)
 		}
+		return
+	}
+	pages, err := h.Usecase.RefundTask(usernameStr, taskId, domain.ReasonTaskCancelled)
+	if err != nil {
+		log.Printf("Error refunding cancelled task %s: %v", taskId, err)
+	}
+	log.Printf("Cancelled task %s, refunding %d pages", taskId, pages)
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"refunded_pages": pages}})
+}
 
-		if err = h.TaskStatusService.UpdateTaskDownloadLink(taskId, downLink); err != nil {
-			log.Printf("Error updating task download link: %v", err)
-			handleTaskStatusError(usernameStr, taskId, h.TaskStatusService)
+// RetryTask queues a failed task of the authenticated user again without uploading its document again. The task
+// resumes from its last completed stage: the OCR text and the translation stored by its previous run are reused, the
+// chunks translated before a failure come from the translation memory, and the pages released when the task failed
+// are reserved again.
+// Responds with 404 if the task does not exist, 409 if it has not failed or cannot be retried, and 402 if the balance
+// no longer covers its pages.
+func (h *TaskHandlerImpl) RetryTask(c *gin.Context) {
+	usernameStr, err := getAuthenticatedUsername(c)
+	if err != nil {
+		handleError(c, http.StatusUnauthorized, errUserUnauthorized)
+		return
+	}
+	taskId := usernameStr + "-" + c.Param("id")
+	reason, err := h.TaskStatusService.RetryTask(usernameStr, taskId)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrTaskNotFound):
+			handleError(c, http.StatusNotFound, errTaskNotFound)
+		case errors.Is(err, service.ErrTaskNotFailed):
+			handleError(c, http.StatusConflict, err.Error())
+		default:
+			log.Printf("Error retrying task %s: %v", taskId, err)
+			handleError(c, http.StatusInternalServerError, errTaskQueueFailure)
+		}
+		return
+	}
+	if err := h.Usecase.RetryTask(usernameStr, taskId); err != nil {
+		// The task stays failed for the reason it failed before, so that it can be retried again
+		handleTaskStatusError(usernameStr, taskId, reason, h.TaskStatusService)
+		if errors.Is(err, usecase.ErrTaskNotRetryable) {
+			handleError(c, http.StatusConflict, err.Error())
+			return
+		}
+		if errors.Is(err, repository.ErrInsufficientBalance) {
+			handleError(c, http.StatusPaymentRequired, errInsufficientBalance)
 			return
 		}
-	}()
+		log.Printf("Error queueing retried task %s: %v", taskId, err)
+		handleError(c, http.StatusInternalServerError, errTaskQueueFailure)
+		return
+	}
+	log.Printf("Queued retried task %s", taskId)
+	c.JSON(http.StatusOK, gin.H{"data": "ok"})
+}
+
+// handleTermReviewError maps errors of a term review to HTTP responses.
+// Missing tasks yield 404, tasks not waiting for the review yield 409, and anything else yields 500.
+func handleTermReviewError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrTaskNotFound):
+		handleError(c, http.StatusNotFound, errTaskNotFound)
+	case errors.Is(err, service.ErrNotWaitingForReview):
+		handleError(c, http.StatusConflict, err.Error())
+	default:
+		log.Printf("Term review error: %v", err)
+		handleError(c, http.StatusInternalServerError, errTermReviewFailure)
+	}
+}
+
+// resolveGlossary loads the glossary with the given ID selected by the user for a task.
+// It returns the HTTP status code to respond with if the ID is invalid or the glossary cannot be loaded.
+func (h *TaskHandlerImpl) resolveGlossary(username, glossaryIdStr string) (*domain.Glossary, int, error) {
+	glossaryId, err := strconv.Atoi(glossaryIdStr)
+	if err != nil || glossaryId <= 0 {
+		return nil, http.StatusBadRequest, errors.New(errInvalidGlossaryId)
+	}
+	glossary, err := h.GlossaryUsecase.GetGlossary(username, glossaryId)
+	if err != nil {
+		if errors.Is(err, repository.ErrGlossaryNotFound) {
+			return nil, http.StatusNotFound, errors.New(errGlossaryNotFound)
+		}
+		log.Printf("Error loading glossary %d: %v", glossaryId, err)
+		return nil, http.StatusInternalServerError, errors.New(errGlossaryFailure)
+	}
+	return glossary, http.StatusOK, nil
+}
+
+// resolveStyleProfile loads the style profile with the given ID selected by the user for a task.
+// It returns the HTTP status code to respond with if the ID is invalid or the profile cannot be loaded.
+func (h *TaskHandlerImpl) resolveStyleProfile(username, profileIdStr string) (*domain.StyleProfile, int, error) {
+	profileId, err := strconv.Atoi(profileIdStr)
+	if err != nil || profileId <= 0 {
+		return nil, http.StatusBadRequest, errors.New(errInvalidStyleProfileId)
+	}
+	profile, err := h.StyleProfileUsecase.GetStyleProfile(username, profileId)
+	if err != nil {
+		if errors.Is(err, repository.ErrStyleProfileNotFound) {
+			return nil, http.StatusNotFound, errors.New(errStyleProfileNotFound)
+		}
+		log.Printf("Error loading style profile %d: %v", profileId, err)
+		return nil, http.StatusInternalServerError, errors.New(errStyleProfileFailure)
+	}
+	return profile, http.StatusOK, nil
 }
 
 // getAuthenticatedUsername retrieves the authenticated username from the given context.
@@ -145,13 +386,20 @@ func handleError(c *gin.Context, statusCode int, message string) {
 	c.JSON(statusCode, gin.H{"error": message})
 }
 
-// handleTaskStatusError updates the task status to error state and logs any error encountered during the update process.
-func handleTaskStatusError(username string, taskId string, taskHandler service.TaskStatusService) {
+// handleTaskStatusError updates the status of a task that could not be queued to error state, records the reason it
+// failed, unless it is empty, and logs any error encountered during the update process.
+func handleTaskStatusError(username string, taskId string, reason string, taskHandler service.TaskStatusService) {
 	err := taskHandler.UpdateTaskStatus(username, taskId, service.Error)
 	if err != nil {
 		log.Printf("Error updating task status: %v", err)
 		return
 	}
+	if reason == "" {
+		return
+	}
+	if err := taskHandler.UpdateTaskError(taskId, reason); err != nil {
+		log.Printf("Error updating task error: %v", err)
+	}
 }
 
 // TaskStatusCheckHandler handles the retrieval of all tasks for an authenticated user and returns the results as JSON.
@@ -172,3 +420,73 @@ func (h *TaskHandlerImpl) TaskStatusCheckHandler(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, gin.H{"data": results})
 }
+
+// TaskDetail responds with the full state of a task of the authenticated user: its status, file, download link, OCR
+// language, page count, pages billed, the start and end of every stage run so far and the reason it failed, if it did.
+// The task is identified by the ID listed by TaskStatusCheckHandler.
+// Responds with 404 if the task does not exist.
+func (h *TaskHandlerImpl) TaskDetail(c *gin.Context) {
+	usernameStr, err := getAuthenticatedUsername(c)
+	if err != nil {
+		handleError(c, http.StatusUnauthorized, errUserUnauthorized)
+		return
+	}
+	detail, err := h.TaskStatusService.GetTaskDetail(usernameStr, usernameStr+"-"+c.Param("id"))
+	if errors.Is(err, repository.ErrTaskNotFound) {
+		handleError(c, http.StatusNotFound, errTaskNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting task detail: %v", err)
+		handleError(c, http.StatusInternalServerError, errTaskDetailFailure)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": detail})
+}
+
+// StreamTasks pushes the events of the tasks of the authenticated user as Server-Sent Events until the client
+// disconnects, so that the task list can be kept up to date without polling. The stream starts with a snapshot event
+// holding the tasks as listed by TaskStatusCheckHandler, followed by the events of domain.TaskEvent as they happen on
+// any task manager replica or task worker. Every event is named after its type.
+func (h *TaskHandlerImpl) StreamTasks(c *gin.Context) {
+	usernameStr, err := getAuthenticatedUsername(c)
+	if err != nil {
+		handleError(c, http.StatusUnauthorized, errUserUnauthorized)
+		return
+	}
+	// Subscribing before taking the snapshot ensures that no change after the snapshot is missed
+	events, err := h.TaskStatusService.SubscribeTaskEvents(c.Request.Context(), usernameStr)
+	if err != nil {
+		handleError(c, http.StatusInternalServerError, errTaskStreamFailure)
+		return
+	}
+	tasks, err := h.TaskStatusService.GetAllTask(usernameStr)
+	if err != nil {
+		handleError(c, http.StatusInternalServerError, errTaskStreamFailure)
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	// Disables the response buffering of nginx, which would hold the events back
+	c.Header("X-Accel-Buffering", "no")
+	c.SSEvent("snapshot", tasks)
+	c.Writer.Flush()
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+	c.Stream(
+		func(w io.Writer) bool {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					// The subscription ended, such as when the client disconnected
+					return false
+				}
+				c.SSEvent(event.Type, event)
+				return true
+			case <-heartbeat.C:
+				_, err := io.WriteString(w, ": heartbeat\n\n")
+				return err == nil
+			}
+		},
+	)
+}
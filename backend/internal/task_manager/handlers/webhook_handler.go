@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/oOSomnus/transflate/internal/task_manager/domain"
+	"github.com/oOSomnus/transflate/internal/task_manager/repository"
+	"github.com/oOSomnus/transflate/internal/task_manager/usecase"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// errWebhookNotFound is the response message for webhooks that do not exist or belong to another user.
+// errDeliveryNotFound is the response message for webhook deliveries that do not exist or belong to another user.
+// errInvalidWebhookId is the response message for webhook and delivery IDs that are not positive integers.
+// errInvalidDeliveryFilter is the response message for delivery log queries with a malformed webhook ID or limit.
+// errWebhookFailure is the response message for unexpected errors while accessing webhooks.
+const (
+	errWebhookNotFound       = "Webhook not found"
+	errDeliveryNotFound      = "Webhook delivery not found"
+	errInvalidWebhookId      = "Invalid webhook ID"
+	errInvalidDeliveryFilter = "Invalid delivery filter"
+	errWebhookFailure        = "Failed to access webhooks"
+)
+
+// WebhookHandler defines methods for handling webhook HTTP requests of the authenticated user.
+// List, Create and Delete manage the registered webhooks.
+// Secret and RotateSecret return and replace the secret signing the webhook payloads.
+// Deliveries lists the delivery log and Replay posts a logged delivery again.
+type WebhookHandler interface {
+	List(c *gin.Context)
+	Create(c *gin.Context)
+	Delete(c *gin.Context)
+	Secret(c *gin.Context)
+	RotateSecret(c *gin.Context)
+	Deliveries(c *gin.Context)
+	Replay(c *gin.Context)
+}
+
+// WebhookHandlerImpl handles HTTP requests related to webhooks, delegating logic to the associated WebhookUsecase.
+type WebhookHandlerImpl struct {
+	Usecase usecase.WebhookUsecase
+}
+
+// NewWebhookHandler initializes and returns a new instance of WebhookHandlerImpl with the provided WebhookUsecase.
+func NewWebhookHandler(u usecase.WebhookUsecase) *WebhookHandlerImpl {
+	return &WebhookHandlerImpl{Usecase: u}
+}
+
+// List responds with all webhooks of the authenticated user.
+func (h *WebhookHandlerImpl) List(c *gin.Context) {
+	usernameStr, err := getAuthenticatedUsername(c)
+	if err != nil {
+		handleError(c, http.StatusUnauthorized, errUserUnauthorized)
+		return
+	}
+	webhooks, err := h.Usecase.ListWebhooks(usernameStr)
+	if err != nil {
+		log.Printf("Error listing webhooks: %v", err)
+		handleError(c, http.StatusInternalServerError, errWebhookFailure)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": webhooks})
+}
+
+// Create registers a webhook for the authenticated user from a JSON body with its URL.
+func (h *WebhookHandlerImpl) Create(c *gin.Context) {
+	usernameStr, err := getAuthenticatedUsername(c)
+	if err != nil {
+		handleError(c, http.StatusUnauthorized, errUserUnauthorized)
+		return
+	}
+	var req domain.WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleError(c, http.StatusBadRequest, errInvalidRequest)
+		return
+	}
+	webhook, err := h.Usecase.CreateWebhook(usernameStr, req)
+	if err != nil {
+		h.handleWebhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": webhook})
+}
+
+// Delete removes a webhook of the authenticated user together with its deliveries.
+func (h *WebhookHandlerImpl) Delete(c *gin.Context) {
+	usernameStr, webhookId, ok := webhookParams(c)
+	if !ok {
+		return
+	}
+	if err := h.Usecase.DeleteWebhook(usernameStr, webhookId); err != nil {
+		h.handleWebhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": "ok"})
+}
+
+// Secret responds with the secret signing the webhook payloads of the authenticated user.
+func (h *WebhookHandlerImpl) Secret(c *gin.Context) {
+	usernameStr, err := getAuthenticatedUsername(c)
+	if err != nil {
+		handleError(c, http.StatusUnauthorized, errUserUnauthorized)
+		return
+	}
+	secret, err := h.Usecase.GetWebhookSecret(usernameStr)
+	if err != nil {
+		h.handleWebhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"secret": secret}})
+}
+
+// RotateSecret replaces the secret signing the webhook payloads of the authenticated user and responds with the new
+// secret.
+func (h *WebhookHandlerImpl) RotateSecret(c *gin.Context) {
+	usernameStr, err := getAuthenticatedUsername(c)
+	if err != nil {
+		handleError(c, http.StatusUnauthorized, errUserUnauthorized)
+		return
+	}
+	secret, err := h.Usecase.RotateWebhookSecret(usernameStr)
+	if err != nil {
+		h.handleWebhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"secret": secret}})
+}
+
+// Deliveries responds with the delivery log of the authenticated user, newest first, optionally filtered by the
+// webhook_id and status query parameters and limited by the limit query parameter.
+func (h *WebhookHandlerImpl) Deliveries(c *gin.Context) {
+	usernameStr, err := getAuthenticatedUsername(c)
+	if err != nil {
+		handleError(c, http.StatusUnauthorized, errUserUnauthorized)
+		return
+	}
+	filter := domain.DeliveryFilter{Status: c.Query("status")}
+	if webhookId := c.Query("webhook_id"); webhookId != "" {
+		if filter.WebhookID, err = strconv.Atoi(webhookId); err != nil || filter.WebhookID <= 0 {
+			handleError(c, http.StatusBadRequest, errInvalidDeliveryFilter)
+			return
+		}
+	}
+	if limit := c.Query("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil || filter.Limit <= 0 {
+			handleError(c, http.StatusBadRequest, errInvalidDeliveryFilter)
+			return
+		}
+	}
+	deliveries, err := h.Usecase.ListDeliveries(usernameStr, filter)
+	if err != nil {
+		h.handleWebhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": deliveries})
+}
+
+// Replay queues a new delivery of the payload of a delivery of the authenticated user and responds with it.
+func (h *WebhookHandlerImpl) Replay(c *gin.Context) {
+	usernameStr, deliveryId, ok := webhookParams(c)
+	if !ok {
+		return
+	}
+	delivery, err := h.Usecase.ReplayDelivery(usernameStr, deliveryId)
+	if err != nil {
+		h.handleWebhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"data": delivery})
+}
+
+// webhookParams retrieves the authenticated username and the webhook or delivery ID path parameter of the request.
+// It responds with 401 or 400 and returns false if either is missing or invalid.
+func webhookParams(c *gin.Context) (string, int, bool) {
+	usernameStr, err := getAuthenticatedUsername(c)
+	if err != nil {
+		handleError(c, http.StatusUnauthorized, errUserUnauthorized)
+		return "", 0, false
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		handleError(c, http.StatusBadRequest, errInvalidWebhookId)
+		return "", 0, false
+	}
+	return usernameStr, id, true
+}
+
+// handleWebhookError maps errors of the webhook usecase to HTTP responses.
+// Missing webhooks and deliveries yield 404, validation errors yield 400, and anything else yields 500.
+func (h *WebhookHandlerImpl) handleWebhookError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrWebhookNotFound):
+		handleError(c, http.StatusNotFound, errWebhookNotFound)
+	case errors.Is(err, repository.ErrWebhookDeliveryNotFound):
+		handleError(c, http.StatusNotFound, errDeliveryNotFound)
+	case err.Error() == usecase.ErrInvalidWebhook || err.Error() == usecase.ErrWebhookAddress ||
+		err.Error() == usecase.ErrTooManyWebhooks || err.Error() == usecase.ErrInvalidDeliveryStatus:
+		handleError(c, http.StatusBadRequest, err.Error())
+	default:
+		log.Printf("Webhook error: %v", err)
+		handleError(c, http.StatusInternalServerError, errWebhookFailure)
+	}
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/oOSomnus/transflate/internal/task_manager/domain"
+	"strings"
+)
+
+// BalanceRepository defines methods for reading the ledger of the balances of users, which UserRepository writes along
+// with every change of a balance.
+// ListTransactions retrieves the transactions of the user selected by the filter and the number of transactions the
+// filter selects regardless of its limit and offset.
+// FindBalanceDrift retrieves the users whose stored balance differs from the sum of their ledger.
+type BalanceRepository interface {
+	ListTransactions(username string, filter domain.TransactionFilter) ([]domain.BalanceTransaction, int, error)
+	FindBalanceDrift() ([]domain.BalanceDrift, error)
+}
+
+// BalanceRepositoryImpl reads the balance_transactions ledger from PostgreSQL.
+type BalanceRepositoryImpl struct {
+	DB *sql.DB
+}
+
+// NewBalanceRepository initializes a new BalanceRepositoryImpl with a given sql.DB connection and returns its instance.
+func NewBalanceRepository(db *sql.DB) *BalanceRepositoryImpl {
+	return &BalanceRepositoryImpl{
+		DB: db,
+	}
+}
+
+// ListTransactions retrieves the transactions of username selected by the filter, newest first, along with the number
+// of transactions in the selected date range.
+func (r *BalanceRepositoryImpl) ListTransactions(
+	username string, filter domain.TransactionFilter,
+) ([]domain.BalanceTransaction, int, error) {
+	conditions := []string{"username = $1"}
+	args := []interface{}{username}
+	if !filter.From.IsZero() {
+		args = append(args, filter.From)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+	if !filter.To.IsZero() {
+		args = append(args, filter.To)
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	if err := r.DB.QueryRow("SELECT COUNT(*) FROM balance_transactions WHERE "+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	query := fmt.Sprintf(
+		`SELECT transaction_id, kind, amount, balance_after, task_id, reason, created_at FROM balance_transactions
+		WHERE %s ORDER BY created_at DESC, transaction_id DESC LIMIT $%d OFFSET $%d`,
+		where, len(args)-1, len(args),
+	)
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	transactions := make([]domain.BalanceTransaction, 0)
+	for rows.Next() {
+		var transaction domain.BalanceTransaction
+		err := rows.Scan(
+			&transaction.ID, &transaction.Kind, &transaction.Amount, &transaction.BalanceAfter, &transaction.TaskID,
+			&transaction.Reason, &transaction.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		transactions = append(transactions, transaction)
+	}
+	return transactions, total, rows.Err()
+}
+
+// FindBalanceDrift retrieves the users whose balance differs from the sum of the amounts of their transactions,
+// ordered by username. Users without transactions have a ledger balance of zero.
+func (r *BalanceRepositoryImpl) FindBalanceDrift() ([]domain.BalanceDrift, error) {
+	query := `SELECT u.username, COALESCE(u.balance, 0), COALESCE(SUM(t.amount), 0)
+		FROM users u LEFT JOIN balance_transactions t ON t.username = u.username
+		GROUP BY u.username, u.balance
+		HAVING COALESCE(u.balance, 0) <> COALESCE(SUM(t.amount), 0)
+		ORDER BY u.username`
+	rows, err := r.DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	drifts := make([]domain.BalanceDrift, 0)
+	for rows.Next() {
+		var drift domain.BalanceDrift
+		if err := rows.Scan(&drift.Username, &drift.Balance, &drift.LedgerBalance); err != nil {
+			return nil, err
+		}
+		drifts = append(drifts, drift)
+	}
+	return drifts, rows.Err()
+}
+
+// recordTransaction appends a transaction to the ledger within tx, which must also hold the change of the balance it
+// records, so that the ledger never misses nor invents a change.
+func recordTransaction(ctx context.Context, tx *sql.Tx, transaction domain.BalanceTransaction) error {
+	query := `INSERT INTO balance_transactions (username, kind, amount, balance_after, task_id, reason)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err := tx.ExecContext(
+		ctx, query, transaction.Username, transaction.Kind, transaction.Amount, transaction.BalanceAfter,
+		transaction.TaskID, transaction.Reason,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record balance transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/oOSomnus/transflate/internal/task_manager/domain"
+	"log"
+	"time"
+)
+
+// ErrGlossaryNotFound indicates that the requested glossary does not exist or belongs to another user.
+// ErrGlossaryTermNotFound indicates that the requested term does not exist in the glossary.
+var (
+	ErrGlossaryNotFound     = errors.New("glossary not found")
+	ErrGlossaryTermNotFound = errors.New("glossary term not found")
+)
+
+// GlossaryRepository defines methods for managing per-user glossaries and their terms.
+// CreateGlossary creates an empty glossary for the user and returns its ID.
+// ListGlossaries retrieves all glossaries of the user without their terms.
+// GetGlossary retrieves a glossary of the user including its terms.
+// UpdateGlossary renames a glossary of the user and replaces all of its terms.
+// DeleteGlossary removes a glossary of the user together with its terms.
+// UpsertTerms adds terms to a glossary of the user, overwriting the target of terms whose source already exists.
+// DeleteTerm removes a single term from a glossary of the user.
+type GlossaryRepository interface {
+	CreateGlossary(username, name string) (int, error)
+	ListGlossaries(username string) ([]domain.Glossary, error)
+	GetGlossary(username string, glossaryId int) (*domain.Glossary, error)
+	UpdateGlossary(username string, glossaryId int, name string, terms []domain.GlossaryTerm) error
+	DeleteGlossary(username string, glossaryId int) error
+	UpsertTerms(username string, glossaryId int, terms []domain.GlossaryTerm) error
+	DeleteTerm(username string, glossaryId, termId int) error
+}
+
+// GlossaryRepositoryImpl stores glossaries and glossary terms in PostgreSQL.
+type GlossaryRepositoryImpl struct {
+	DB *sql.DB
+}
+
+// NewGlossaryRepository initializes a new GlossaryRepositoryImpl with a given sql.DB connection and returns its instance.
+func NewGlossaryRepository(db *sql.DB) *GlossaryRepositoryImpl {
+	return &GlossaryRepositoryImpl{
+		DB: db,
+	}
+}
+
+// CreateGlossary inserts a new glossary owned by username and returns the generated glossary ID.
+func (r *GlossaryRepositoryImpl) CreateGlossary(username, name string) (int, error) {
+	query := "INSERT INTO glossaries (username, name) VALUES ($1, $2) RETURNING glossary_id"
+	var glossaryId int
+	if err := r.DB.QueryRow(query, username, name).Scan(&glossaryId); err != nil {
+		return 0, err
+	}
+	return glossaryId, nil
+}
+
+// ListGlossaries retrieves the glossaries owned by username ordered by creation time, without their terms.
+func (r *GlossaryRepositoryImpl) ListGlossaries(username string) ([]domain.Glossary, error) {
+	query := "SELECT glossary_id, name, created_at FROM glossaries WHERE username = $1 ORDER BY created_at, glossary_id"
+	rows, err := r.DB.Query(query, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	glossaries := make([]domain.Glossary, 0)
+	for rows.Next() {
+		var glossary domain.Glossary
+		if err := rows.Scan(&glossary.ID, &glossary.Name, &glossary.CreatedAt); err != nil {
+			return nil, err
+		}
+		glossaries = append(glossaries, glossary)
+	}
+	return glossaries, rows.Err()
+}
+
+// GetGlossary retrieves a glossary owned by username together with all of its terms.
+// Returns ErrGlossaryNotFound if the glossary does not exist or is owned by another user.
+func (r *GlossaryRepositoryImpl) GetGlossary(username string, glossaryId int) (*domain.Glossary, error) {
+	query := "SELECT glossary_id, name, created_at FROM glossaries WHERE glossary_id = $1 AND username = $2"
+	var glossary domain.Glossary
+	err := r.DB.QueryRow(query, glossaryId, username).Scan(&glossary.ID, &glossary.Name, &glossary.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrGlossaryNotFound
+		}
+		return nil, err
+	}
+
+	rows, err := r.DB.Query(
+		"SELECT term_id, source_term, target_term FROM glossary_terms WHERE glossary_id = $1 ORDER BY term_id",
+		glossaryId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	glossary.Terms = make([]domain.GlossaryTerm, 0)
+	for rows.Next() {
+		var term domain.GlossaryTerm
+		if err := rows.Scan(&term.ID, &term.Source, &term.Target); err != nil {
+			return nil, err
+		}
+		glossary.Terms = append(glossary.Terms, term)
+	}
+	return &glossary, rows.Err()
+}
+
+// UpdateGlossary renames a glossary owned by username and replaces all of its terms in a single transaction.
+// Returns ErrGlossaryNotFound if the glossary does not exist or is owned by another user.
+func (r *GlossaryRepositoryImpl) UpdateGlossary(
+	username string, glossaryId int, name string, terms []domain.GlossaryTerm,
+) error {
+	return r.withOwnedGlossary(
+		username, glossaryId, func(ctx context.Context, tx *sql.Tx) error {
+			if _, err := tx.ExecContext(
+				ctx, "UPDATE glossaries SET name = $1 WHERE glossary_id = $2", name, glossaryId,
+			); err != nil {
+				return fmt.Errorf("failed to rename glossary: %w", err)
+			}
+			if _, err := tx.ExecContext(
+				ctx, "DELETE FROM glossary_terms WHERE glossary_id = $1", glossaryId,
+			); err != nil {
+				return fmt.Errorf("failed to clear glossary terms: %w", err)
+			}
+			return upsertTerms(ctx, tx, glossaryId, terms)
+		},
+	)
+}
+
+// DeleteGlossary removes a glossary owned by username; its terms are removed by the cascading foreign key.
+// Returns ErrGlossaryNotFound if the glossary does not exist or is owned by another user.
+func (r *GlossaryRepositoryImpl) DeleteGlossary(username string, glossaryId int) error {
+	result, err := r.DB.Exec("DELETE FROM glossaries WHERE glossary_id = $1 AND username = $2", glossaryId, username)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrGlossaryNotFound
+	}
+	return nil
+}
+
+// UpsertTerms adds terms to a glossary owned by username in a single transaction.
+// Terms whose source already exists in the glossary get their target overwritten.
+// Returns ErrGlossaryNotFound if the glossary does not exist or is owned by another user.
+func (r *GlossaryRepositoryImpl) UpsertTerms(username string, glossaryId int, terms []domain.GlossaryTerm) error {
+	return r.withOwnedGlossary(
+		username, glossaryId, func(ctx context.Context, tx *sql.Tx) error {
+			return upsertTerms(ctx, tx, glossaryId, terms)
+		},
+	)
+}
+
+// DeleteTerm removes a term from a glossary owned by username.
+// Returns ErrGlossaryTermNotFound if no such term exists in a glossary of the user.
+func (r *GlossaryRepositoryImpl) DeleteTerm(username string, glossaryId, termId int) error {
+	query := `DELETE FROM glossary_terms t USING glossaries g
+		WHERE t.glossary_id = g.glossary_id AND t.term_id = $1 AND g.glossary_id = $2 AND g.username = $3`
+	result, err := r.DB.Exec(query, termId, glossaryId, username)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrGlossaryTermNotFound
+	}
+	return nil
+}
+
+// withOwnedGlossary runs fn in a transaction after locking the glossary row and verifying that username owns it.
+// The transaction is committed if fn succeeds and rolled back otherwise.
+func (r *GlossaryRepositoryImpl) withOwnedGlossary(
+	username string, glossaryId int, fn func(ctx context.Context, tx *sql.Tx) error,
+) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			log.Println("recovered from panic:", p)
+		} else if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	var owner string
+	query := "SELECT username FROM glossaries WHERE glossary_id = $1 FOR UPDATE"
+	err = tx.QueryRowContext(ctx, query, glossaryId).Scan(&owner)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrGlossaryNotFound
+		}
+		return fmt.Errorf("failed to get glossary: %w", err)
+	}
+	if owner != username {
+		return ErrGlossaryNotFound
+	}
+
+	err = fn(ctx, tx)
+	return err
+}
+
+// upsertTerms inserts terms into the glossary within tx, overwriting the target of terms whose source already exists.
+func upsertTerms(ctx context.Context, tx *sql.Tx, glossaryId int, terms []domain.GlossaryTerm) error {
+	query := `INSERT INTO glossary_terms (glossary_id, source_term, target_term) VALUES ($1, $2, $3)
+		ON CONFLICT (glossary_id, source_term) DO UPDATE SET target_term = EXCLUDED.target_term`
+	for _, term := range terms {
+		if _, err := tx.ExecContext(ctx, query, glossaryId, term.Source, term.Target); err != nil {
+			return fmt.Errorf("failed to save glossary term %q: %w", term.Source, err)
+		}
+	}
+	return nil
+}
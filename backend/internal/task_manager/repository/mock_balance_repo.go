@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/task_manager/repository/balance_repo.go
+
+// Package repository is a generated GoMock package.
+package repository
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	domain "github.com/oOSomnus/transflate/internal/task_manager/domain"
+)
+
+// MockBalanceRepository is a mock of BalanceRepository interface.
+type MockBalanceRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockBalanceRepositoryMockRecorder
+}
+
+// MockBalanceRepositoryMockRecorder is the mock recorder for MockBalanceRepository.
+type MockBalanceRepositoryMockRecorder struct {
+	mock *MockBalanceRepository
+}
+
+// NewMockBalanceRepository creates a new mock instance.
+func NewMockBalanceRepository(ctrl *gomock.Controller) *MockBalanceRepository {
+	mock := &MockBalanceRepository{ctrl: ctrl}
+	mock.recorder = &MockBalanceRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBalanceRepository) EXPECT() *MockBalanceRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindBalanceDrift mocks base method.
+func (m *MockBalanceRepository) FindBalanceDrift() ([]domain.BalanceDrift, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindBalanceDrift")
+	ret0, _ := ret[0].([]domain.BalanceDrift)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindBalanceDrift indicates an expected call of FindBalanceDrift.
+func (mr *MockBalanceRepositoryMockRecorder) FindBalanceDrift() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBalanceDrift", reflect.TypeOf((*MockBalanceRepository)(nil).FindBalanceDrift))
+}
+
+// ListTransactions mocks base method.
+func (m *MockBalanceRepository) ListTransactions(username string, filter domain.TransactionFilter) ([]domain.BalanceTransaction, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTransactions", username, filter)
+	ret0, _ := ret[0].([]domain.BalanceTransaction)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListTransactions indicates an expected call of ListTransactions.
+func (mr *MockBalanceRepositoryMockRecorder) ListTransactions(username, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransactions", reflect.TypeOf((*MockBalanceRepository)(nil).ListTransactions), username, filter)
+}
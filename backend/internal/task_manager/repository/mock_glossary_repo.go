@@ -0,0 +1,136 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/task_manager/repository/glossary_repo.go
+
+// Package repository is a generated GoMock package.
+package repository
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	domain "github.com/oOSomnus/transflate/internal/task_manager/domain"
+)
+
+// MockGlossaryRepository is a mock of GlossaryRepository interface.
+type MockGlossaryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockGlossaryRepositoryMockRecorder
+}
+
+// MockGlossaryRepositoryMockRecorder is the mock recorder for MockGlossaryRepository.
+type MockGlossaryRepositoryMockRecorder struct {
+	mock *MockGlossaryRepository
+}
+
+// NewMockGlossaryRepository creates a new mock instance.
+func NewMockGlossaryRepository(ctrl *gomock.Controller) *MockGlossaryRepository {
+	mock := &MockGlossaryRepository{ctrl: ctrl}
+	mock.recorder = &MockGlossaryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGlossaryRepository) EXPECT() *MockGlossaryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateGlossary mocks base method.
+func (m *MockGlossaryRepository) CreateGlossary(username, name string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateGlossary", username, name)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateGlossary indicates an expected call of CreateGlossary.
+func (mr *MockGlossaryRepositoryMockRecorder) CreateGlossary(username, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGlossary", reflect.TypeOf((*MockGlossaryRepository)(nil).CreateGlossary), username, name)
+}
+
+// DeleteGlossary mocks base method.
+func (m *MockGlossaryRepository) DeleteGlossary(username string, glossaryId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteGlossary", username, glossaryId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteGlossary indicates an expected call of DeleteGlossary.
+func (mr *MockGlossaryRepositoryMockRecorder) DeleteGlossary(username, glossaryId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteGlossary", reflect.TypeOf((*MockGlossaryRepository)(nil).DeleteGlossary), username, glossaryId)
+}
+
+// DeleteTerm mocks base method.
+func (m *MockGlossaryRepository) DeleteTerm(username string, glossaryId, termId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTerm", username, glossaryId, termId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTerm indicates an expected call of DeleteTerm.
+func (mr *MockGlossaryRepositoryMockRecorder) DeleteTerm(username, glossaryId, termId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTerm", reflect.TypeOf((*MockGlossaryRepository)(nil).DeleteTerm), username, glossaryId, termId)
+}
+
+// GetGlossary mocks base method.
+func (m *MockGlossaryRepository) GetGlossary(username string, glossaryId int) (*domain.Glossary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGlossary", username, glossaryId)
+	ret0, _ := ret[0].(*domain.Glossary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGlossary indicates an expected call of GetGlossary.
+func (mr *MockGlossaryRepositoryMockRecorder) GetGlossary(username, glossaryId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGlossary", reflect.TypeOf((*MockGlossaryRepository)(nil).GetGlossary), username, glossaryId)
+}
+
+// ListGlossaries mocks base method.
+func (m *MockGlossaryRepository) ListGlossaries(username string) ([]domain.Glossary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListGlossaries", username)
+	ret0, _ := ret[0].([]domain.Glossary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListGlossaries indicates an expected call of ListGlossaries.
+func (mr *MockGlossaryRepositoryMockRecorder) ListGlossaries(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListGlossaries", reflect.TypeOf((*MockGlossaryRepository)(nil).ListGlossaries), username)
+}
+
+// UpdateGlossary mocks base method.
+func (m *MockGlossaryRepository) UpdateGlossary(username string, glossaryId int, name string, terms []domain.GlossaryTerm) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateGlossary", username, glossaryId, name, terms)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateGlossary indicates an expected call of UpdateGlossary.
+func (mr *MockGlossaryRepositoryMockRecorder) UpdateGlossary(username, glossaryId, name, terms interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateGlossary", reflect.TypeOf((*MockGlossaryRepository)(nil).UpdateGlossary), username, glossaryId, name, terms)
+}
+
+// UpsertTerms mocks base method.
+func (m *MockGlossaryRepository) UpsertTerms(username string, glossaryId int, terms []domain.GlossaryTerm) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertTerms", username, glossaryId, terms)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertTerms indicates an expected call of UpsertTerms.
+func (mr *MockGlossaryRepositoryMockRecorder) UpsertTerms(username, glossaryId, terms interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertTerms", reflect.TypeOf((*MockGlossaryRepository)(nil).UpsertTerms), username, glossaryId, terms)
+}
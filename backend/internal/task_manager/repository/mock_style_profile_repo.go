@@ -0,0 +1,108 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/task_manager/repository/style_profile_repo.go
+
+// Package repository is a generated GoMock package.
+package repository
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	domain "github.com/oOSomnus/transflate/internal/task_manager/domain"
+)
+
+// MockStyleProfileRepository is a mock of StyleProfileRepository interface.
+type MockStyleProfileRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockStyleProfileRepositoryMockRecorder
+}
+
+// MockStyleProfileRepositoryMockRecorder is the mock recorder for MockStyleProfileRepository.
+type MockStyleProfileRepositoryMockRecorder struct {
+	mock *MockStyleProfileRepository
+}
+
+// NewMockStyleProfileRepository creates a new mock instance.
+func NewMockStyleProfileRepository(ctrl *gomock.Controller) *MockStyleProfileRepository {
+	mock := &MockStyleProfileRepository{ctrl: ctrl}
+	mock.recorder = &MockStyleProfileRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStyleProfileRepository) EXPECT() *MockStyleProfileRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateStyleProfile mocks base method.
+func (m *MockStyleProfileRepository) CreateStyleProfile(username, name string, style domain.TranslationStyle) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateStyleProfile", username, name, style)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateStyleProfile indicates an expected call of CreateStyleProfile.
+func (mr *MockStyleProfileRepositoryMockRecorder) CreateStyleProfile(username, name, style interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateStyleProfile", reflect.TypeOf((*MockStyleProfileRepository)(nil).CreateStyleProfile), username, name, style)
+}
+
+// DeleteStyleProfile mocks base method.
+func (m *MockStyleProfileRepository) DeleteStyleProfile(username string, profileId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteStyleProfile", username, profileId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteStyleProfile indicates an expected call of DeleteStyleProfile.
+func (mr *MockStyleProfileRepositoryMockRecorder) DeleteStyleProfile(username, profileId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStyleProfile", reflect.TypeOf((*MockStyleProfileRepository)(nil).DeleteStyleProfile), username, profileId)
+}
+
+// GetStyleProfile mocks base method.
+func (m *MockStyleProfileRepository) GetStyleProfile(username string, profileId int) (*domain.StyleProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStyleProfile", username, profileId)
+	ret0, _ := ret[0].(*domain.StyleProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStyleProfile indicates an expected call of GetStyleProfile.
+func (mr *MockStyleProfileRepositoryMockRecorder) GetStyleProfile(username, profileId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStyleProfile", reflect.TypeOf((*MockStyleProfileRepository)(nil).GetStyleProfile), username, profileId)
+}
+
+// ListStyleProfiles mocks base method.
+func (m *MockStyleProfileRepository) ListStyleProfiles(username string) ([]domain.StyleProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListStyleProfiles", username)
+	ret0, _ := ret[0].([]domain.StyleProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListStyleProfiles indicates an expected call of ListStyleProfiles.
+func (mr *MockStyleProfileRepositoryMockRecorder) ListStyleProfiles(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListStyleProfiles", reflect.TypeOf((*MockStyleProfileRepository)(nil).ListStyleProfiles), username)
+}
+
+// UpdateStyleProfile mocks base method.
+func (m *MockStyleProfileRepository) UpdateStyleProfile(username string, profileId int, name string, style domain.TranslationStyle) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStyleProfile", username, profileId, name, style)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateStyleProfile indicates an expected call of UpdateStyleProfile.
+func (mr *MockStyleProfileRepositoryMockRecorder) UpdateStyleProfile(username, profileId, name, style interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStyleProfile", reflect.TypeOf((*MockStyleProfileRepository)(nil).UpdateStyleProfile), username, profileId, name, style)
+}
@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/task_manager/repository/task_events.go
+
+// Package repository is a generated GoMock package.
+package repository
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	domain "github.com/oOSomnus/transflate/internal/task_manager/domain"
+)
+
+// MockTaskEventBus is a mock of TaskEventBus interface.
+type MockTaskEventBus struct {
+	ctrl     *gomock.Controller
+	recorder *MockTaskEventBusMockRecorder
+}
+
+// MockTaskEventBusMockRecorder is the mock recorder for MockTaskEventBus.
+type MockTaskEventBusMockRecorder struct {
+	mock *MockTaskEventBus
+}
+
+// NewMockTaskEventBus creates a new mock instance.
+func NewMockTaskEventBus(ctrl *gomock.Controller) *MockTaskEventBus {
+	mock := &MockTaskEventBus{ctrl: ctrl}
+	mock.recorder = &MockTaskEventBusMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTaskEventBus) EXPECT() *MockTaskEventBusMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockTaskEventBus) Publish(ctx context.Context, username string, event domain.TaskEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", ctx, username, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockTaskEventBusMockRecorder) Publish(ctx, username, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockTaskEventBus)(nil).Publish), ctx, username, event)
+}
+
+// Subscribe mocks base method.
+func (m *MockTaskEventBus) Subscribe(ctx context.Context, username string) (<-chan domain.TaskEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", ctx, username)
+	ret0, _ := ret[0].(<-chan domain.TaskEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockTaskEventBusMockRecorder) Subscribe(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockTaskEventBus)(nil).Subscribe), ctx, username)
+}
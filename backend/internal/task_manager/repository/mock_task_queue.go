@@ -0,0 +1,109 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/task_manager/repository/task_queue.go
+
+// Package repository is a generated GoMock package.
+package repository
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	domain "github.com/oOSomnus/transflate/internal/task_manager/domain"
+)
+
+// MockTaskQueue is a mock of TaskQueue interface.
+type MockTaskQueue struct {
+	ctrl     *gomock.Controller
+	recorder *MockTaskQueueMockRecorder
+}
+
+// MockTaskQueueMockRecorder is the mock recorder for MockTaskQueue.
+type MockTaskQueueMockRecorder struct {
+	mock *MockTaskQueue
+}
+
+// NewMockTaskQueue creates a new mock instance.
+func NewMockTaskQueue(ctrl *gomock.Controller) *MockTaskQueue {
+	mock := &MockTaskQueue{ctrl: ctrl}
+	mock.recorder = &MockTaskQueueMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTaskQueue) EXPECT() *MockTaskQueueMockRecorder {
+	return m.recorder
+}
+
+// Ack mocks base method.
+func (m *MockTaskQueue) Ack(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ack", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ack indicates an expected call of Ack.
+func (mr *MockTaskQueueMockRecorder) Ack(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ack", reflect.TypeOf((*MockTaskQueue)(nil).Ack), ctx, id)
+}
+
+// Enqueue mocks base method.
+func (m *MockTaskQueue) Enqueue(ctx context.Context, job domain.TaskJob) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enqueue", ctx, job)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Enqueue indicates an expected call of Enqueue.
+func (mr *MockTaskQueueMockRecorder) Enqueue(ctx, job interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enqueue", reflect.TypeOf((*MockTaskQueue)(nil).Enqueue), ctx, job)
+}
+
+// Read mocks base method.
+func (m *MockTaskQueue) Read(ctx context.Context, consumer string, count int64, block time.Duration) ([]domain.QueuedJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Read", ctx, consumer, count, block)
+	ret0, _ := ret[0].([]domain.QueuedJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Read indicates an expected call of Read.
+func (mr *MockTaskQueueMockRecorder) Read(ctx, consumer, count, block interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockTaskQueue)(nil).Read), ctx, consumer, count, block)
+}
+
+// Reclaim mocks base method.
+func (m *MockTaskQueue) Reclaim(ctx context.Context, consumer string, minIdle time.Duration, count int64) ([]domain.QueuedJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reclaim", ctx, consumer, minIdle, count)
+	ret0, _ := ret[0].([]domain.QueuedJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reclaim indicates an expected call of Reclaim.
+func (mr *MockTaskQueueMockRecorder) Reclaim(ctx, consumer, minIdle, count interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reclaim", reflect.TypeOf((*MockTaskQueue)(nil).Reclaim), ctx, consumer, minIdle, count)
+}
+
+// Touch mocks base method.
+func (m *MockTaskQueue) Touch(ctx context.Context, consumer, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Touch", ctx, consumer, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Touch indicates an expected call of Touch.
+func (mr *MockTaskQueueMockRecorder) Touch(ctx, consumer, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Touch", reflect.TypeOf((*MockTaskQueue)(nil).Touch), ctx, consumer, id)
+}
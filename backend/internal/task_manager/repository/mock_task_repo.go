@@ -0,0 +1,195 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/task_manager/repository/task_repo.go
+
+// Package repository is a generated GoMock package.
+package repository
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockTaskRepository is a mock of TaskRepository interface.
+type MockTaskRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTaskRepositoryMockRecorder
+}
+
+// MockTaskRepositoryMockRecorder is the mock recorder for MockTaskRepository.
+type MockTaskRepositoryMockRecorder struct {
+	mock *MockTaskRepository
+}
+
+// NewMockTaskRepository creates a new mock instance.
+func NewMockTaskRepository(ctrl *gomock.Controller) *MockTaskRepository {
+	mock := &MockTaskRepository{ctrl: ctrl}
+	mock.recorder = &MockTaskRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTaskRepository) EXPECT() *MockTaskRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteTaskFields mocks base method.
+func (m *MockTaskRepository) DeleteTaskFields(ctx context.Context, username, taskId string, fields ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, username, taskId}
+	for _, a := range fields {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DeleteTaskFields", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTaskFields indicates an expected call of DeleteTaskFields.
+func (mr *MockTaskRepositoryMockRecorder) DeleteTaskFields(ctx, username, taskId interface{}, fields ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, username, taskId}, fields...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTaskFields", reflect.TypeOf((*MockTaskRepository)(nil).DeleteTaskFields), varargs...)
+}
+
+// FetchAllTask mocks base method.
+func (m *MockTaskRepository) FetchAllTask(ctx context.Context, username string) (map[string]map[string]interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchAllTask", ctx, username)
+	ret0, _ := ret[0].(map[string]map[string]interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchAllTask indicates an expected call of FetchAllTask.
+func (mr *MockTaskRepositoryMockRecorder) FetchAllTask(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchAllTask", reflect.TypeOf((*MockTaskRepository)(nil).FetchAllTask), ctx, username)
+}
+
+// GetTaskFields mocks base method.
+func (m *MockTaskRepository) GetTaskFields(ctx context.Context, username, taskId string, fields ...string) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, username, taskId}
+	for _, a := range fields {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetTaskFields", varargs...)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTaskFields indicates an expected call of GetTaskFields.
+func (mr *MockTaskRepositoryMockRecorder) GetTaskFields(ctx, username, taskId interface{}, fields ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, username, taskId}, fields...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskFields", reflect.TypeOf((*MockTaskRepository)(nil).GetTaskFields), varargs...)
+}
+
+// GetTaskState mocks base method.
+func (m *MockTaskRepository) GetTaskState(ctx context.Context, username, taskId string) (int, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaskState", ctx, username, taskId)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetTaskState indicates an expected call of GetTaskState.
+func (mr *MockTaskRepositoryMockRecorder) GetTaskState(ctx, username, taskId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskState", reflect.TypeOf((*MockTaskRepository)(nil).GetTaskState), ctx, username, taskId)
+}
+
+// IncrementTaskField mocks base method.
+func (m *MockTaskRepository) IncrementTaskField(ctx context.Context, username, taskId, field string, by int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementTaskField", ctx, username, taskId, field, by)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncrementTaskField indicates an expected call of IncrementTaskField.
+func (mr *MockTaskRepositoryMockRecorder) IncrementTaskField(ctx, username, taskId, field, by interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementTaskField", reflect.TypeOf((*MockTaskRepository)(nil).IncrementTaskField), ctx, username, taskId, field, by)
+}
+
+// SetTaskState mocks base method.
+func (m *MockTaskRepository) SetTaskState(ctx context.Context, username, taskId string, status int, filename string, ttl time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTaskState", ctx, username, taskId, status, filename, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTaskState indicates an expected call of SetTaskState.
+func (mr *MockTaskRepositoryMockRecorder) SetTaskState(ctx, username, taskId, status, filename, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTaskState", reflect.TypeOf((*MockTaskRepository)(nil).SetTaskState), ctx, username, taskId, status, filename, ttl)
+}
+
+// TransitionTaskStatus mocks base method.
+func (m *MockTaskRepository) TransitionTaskStatus(ctx context.Context, username, taskId string, from, to int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransitionTaskStatus", ctx, username, taskId, from, to)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TransitionTaskStatus indicates an expected call of TransitionTaskStatus.
+func (mr *MockTaskRepositoryMockRecorder) TransitionTaskStatus(ctx, username, taskId, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransitionTaskStatus", reflect.TypeOf((*MockTaskRepository)(nil).TransitionTaskStatus), ctx, username, taskId, from, to)
+}
+
+// UpdateTaskFields mocks base method.
+func (m *MockTaskRepository) UpdateTaskFields(ctx context.Context, username, taskId string, fields map[string]interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTaskFields", ctx, username, taskId, fields)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTaskFields indicates an expected call of UpdateTaskFields.
+func (mr *MockTaskRepositoryMockRecorder) UpdateTaskFields(ctx, username, taskId, fields interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTaskFields", reflect.TypeOf((*MockTaskRepository)(nil).UpdateTaskFields), ctx, username, taskId, fields)
+}
+
+// UpdateTaskLink mocks base method.
+func (m *MockTaskRepository) UpdateTaskLink(ctx context.Context, username, taskId, link string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTaskLink", ctx, username, taskId, link)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTaskLink indicates an expected call of UpdateTaskLink.
+func (mr *MockTaskRepositoryMockRecorder) UpdateTaskLink(ctx, username, taskId, link interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTaskLink", reflect.TypeOf((*MockTaskRepository)(nil).UpdateTaskLink), ctx, username, taskId, link)
+}
+
+// UpdateTaskStatusUnless mocks base method.
+func (m *MockTaskRepository) UpdateTaskStatusUnless(ctx context.Context, username, taskId string, status int, ttl time.Duration, unless ...int) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, username, taskId, status, ttl}
+	for _, a := range unless {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UpdateTaskStatusUnless", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTaskStatusUnless indicates an expected call of UpdateTaskStatusUnless.
+func (mr *MockTaskRepositoryMockRecorder) UpdateTaskStatusUnless(ctx, username, taskId, status, ttl interface{}, unless ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, username, taskId, status, ttl}, unless...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTaskStatusUnless", reflect.TypeOf((*MockTaskRepository)(nil).UpdateTaskStatusUnless), varargs...)
+}
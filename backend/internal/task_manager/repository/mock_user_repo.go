@@ -6,8 +6,10 @@ package repository
 
 import (
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
+	domain "github.com/oOSomnus/transflate/internal/task_manager/domain"
 )
 
 // MockUserRepository is a mock of UserRepository interface.
@@ -33,6 +35,21 @@ func (m *MockUserRepository) EXPECT() *MockUserRepositoryMockRecorder {
 	return m.recorder
 }
 
+// CommitReservation mocks base method.
+func (m *MockUserRepository) CommitReservation(username, taskId string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CommitReservation", username, taskId)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CommitReservation indicates an expected call of CommitReservation.
+func (mr *MockUserRepositoryMockRecorder) CommitReservation(username, taskId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitReservation", reflect.TypeOf((*MockUserRepository)(nil).CommitReservation), username, taskId)
+}
+
 // CreateUser mocks base method.
 func (m *MockUserRepository) CreateUser(username, password string) error {
 	m.ctrl.T.Helper()
@@ -48,17 +65,17 @@ func (mr *MockUserRepositoryMockRecorder) CreateUser(username, password interfac
 }
 
 // DecreaseBalance mocks base method.
-func (m *MockUserRepository) DecreaseBalance(username string, balance int) error {
+func (m *MockUserRepository) DecreaseBalance(username string, balance int, reason string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DecreaseBalance", username, balance)
+	ret := m.ctrl.Call(m, "DecreaseBalance", username, balance, reason)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DecreaseBalance indicates an expected call of DecreaseBalance.
-func (mr *MockUserRepositoryMockRecorder) DecreaseBalance(username, balance interface{}) *gomock.Call {
+func (mr *MockUserRepositoryMockRecorder) DecreaseBalance(username, balance, reason interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecreaseBalance", reflect.TypeOf((*MockUserRepository)(nil).DecreaseBalance), username, balance)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecreaseBalance", reflect.TypeOf((*MockUserRepository)(nil).DecreaseBalance), username, balance, reason)
 }
 
 // FindUsrWithUsername mocks base method.
@@ -105,3 +122,92 @@ func (mr *MockUserRepositoryMockRecorder) IfUserExists(username interface{}) *go
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IfUserExists", reflect.TypeOf((*MockUserRepository)(nil).IfUserExists), username)
 }
+
+// IncreaseBalance mocks base method.
+func (m *MockUserRepository) IncreaseBalance(username string, amount int, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncreaseBalance", username, amount, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncreaseBalance indicates an expected call of IncreaseBalance.
+func (mr *MockUserRepositoryMockRecorder) IncreaseBalance(username, amount, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncreaseBalance", reflect.TypeOf((*MockUserRepository)(nil).IncreaseBalance), username, amount, reason)
+}
+
+// ReleaseExpiredReservations mocks base method.
+func (m *MockUserRepository) ReleaseExpiredReservations(limit int) ([]domain.BalanceReservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseExpiredReservations", limit)
+	ret0, _ := ret[0].([]domain.BalanceReservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReleaseExpiredReservations indicates an expected call of ReleaseExpiredReservations.
+func (mr *MockUserRepositoryMockRecorder) ReleaseExpiredReservations(limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseExpiredReservations", reflect.TypeOf((*MockUserRepository)(nil).ReleaseExpiredReservations), limit)
+}
+
+// ReleaseReservation mocks base method.
+func (m *MockUserRepository) ReleaseReservation(username, taskId, reason string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseReservation", username, taskId, reason)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReleaseReservation indicates an expected call of ReleaseReservation.
+func (mr *MockUserRepositoryMockRecorder) ReleaseReservation(username, taskId, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseReservation", reflect.TypeOf((*MockUserRepository)(nil).ReleaseReservation), username, taskId, reason)
+}
+
+// RenewReservation mocks base method.
+func (m *MockUserRepository) RenewReservation(username, taskId string, ttl time.Duration) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenewReservation", username, taskId, ttl)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenewReservation indicates an expected call of RenewReservation.
+func (mr *MockUserRepositoryMockRecorder) RenewReservation(username, taskId, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenewReservation", reflect.TypeOf((*MockUserRepository)(nil).RenewReservation), username, taskId, ttl)
+}
+
+// ReserveBalance mocks base method.
+func (m *MockUserRepository) ReserveBalance(username, taskId string, pages int, ttl time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveBalance", username, taskId, pages, ttl)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveBalance indicates an expected call of ReserveBalance.
+func (mr *MockUserRepositoryMockRecorder) ReserveBalance(username, taskId, pages, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveBalance", reflect.TypeOf((*MockUserRepository)(nil).ReserveBalance), username, taskId, pages, ttl)
+}
+
+// ReverseCharge mocks base method.
+func (m *MockUserRepository) ReverseCharge(username, taskId, reason string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReverseCharge", username, taskId, reason)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReverseCharge indicates an expected call of ReverseCharge.
+func (mr *MockUserRepositoryMockRecorder) ReverseCharge(username, taskId, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReverseCharge", reflect.TypeOf((*MockUserRepository)(nil).ReverseCharge), username, taskId, reason)
+}
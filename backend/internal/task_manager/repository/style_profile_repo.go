@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/oOSomnus/transflate/internal/task_manager/domain"
+)
+
+// ErrStyleProfileNotFound indicates that the requested style profile does not exist or belongs to another user.
+var ErrStyleProfileNotFound = errors.New("style profile not found")
+
+// StyleProfileRepository defines methods for managing per-user translation style profiles.
+// CreateStyleProfile stores a new style profile for the user and returns its ID.
+// ListStyleProfiles retrieves all style profiles of the user.
+// GetStyleProfile retrieves a style profile of the user.
+// UpdateStyleProfile replaces the name and style of a style profile of the user.
+// DeleteStyleProfile removes a style profile of the user.
+type StyleProfileRepository interface {
+	CreateStyleProfile(username, name string, style domain.TranslationStyle) (int, error)
+	ListStyleProfiles(username string) ([]domain.StyleProfile, error)
+	GetStyleProfile(username string, profileId int) (*domain.StyleProfile, error)
+	UpdateStyleProfile(username string, profileId int, name string, style domain.TranslationStyle) error
+	DeleteStyleProfile(username string, profileId int) error
+}
+
+// StyleProfileRepositoryImpl stores style profiles in PostgreSQL.
+type StyleProfileRepositoryImpl struct {
+	DB *sql.DB
+}
+
+// NewStyleProfileRepository initializes a new StyleProfileRepositoryImpl with a given sql.DB connection and returns its
+// instance.
+func NewStyleProfileRepository(db *sql.DB) *StyleProfileRepositoryImpl {
+	return &StyleProfileRepositoryImpl{
+		DB: db,
+	}
+}
+
+// CreateStyleProfile inserts a new style profile owned by username and returns the generated profile ID.
+func (r *StyleProfileRepositoryImpl) CreateStyleProfile(
+	username, name string, style domain.TranslationStyle,
+) (int, error) {
+	query := `INSERT INTO style_profiles (username, name, formality, domain, instructions)
+		VALUES ($1, $2, $3, $4, $5) RETURNING profile_id`
+	var profileId int
+	err := r.DB.QueryRow(query, username, name, style.Formality, style.Domain, style.Instructions).Scan(&profileId)
+	if err != nil {
+		return 0, err
+	}
+	return profileId, nil
+}
+
+// ListStyleProfiles retrieves the style profiles owned by username ordered by creation time.
+func (r *StyleProfileRepositoryImpl) ListStyleProfiles(username string) ([]domain.StyleProfile, error) {
+	query := `SELECT profile_id, name, formality, domain, instructions, created_at FROM style_profiles
+		WHERE username = $1 ORDER BY created_at, profile_id`
+	rows, err := r.DB.Query(query, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	profiles := make([]domain.StyleProfile, 0)
+	for rows.Next() {
+		var profile domain.StyleProfile
+		if err := rows.Scan(
+			&profile.ID, &profile.Name, &profile.Formality, &profile.Domain, &profile.Instructions, &profile.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, rows.Err()
+}
+
+// GetStyleProfile retrieves a style profile owned by username.
+// Returns ErrStyleProfileNotFound if the profile does not exist or is owned by another user.
+func (r *StyleProfileRepositoryImpl) GetStyleProfile(username string, profileId int) (*domain.StyleProfile, error) {
+	query := `SELECT profile_id, name, formality, domain, instructions, created_at FROM style_profiles
+		WHERE profile_id = $1 AND username = $2`
+	var profile domain.StyleProfile
+	err := r.DB.QueryRow(query, profileId, username).Scan(
+		&profile.ID, &profile.Name, &profile.Formality, &profile.Domain, &profile.Instructions, &profile.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrStyleProfileNotFound
+		}
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// UpdateStyleProfile replaces the name and style of a style profile owned by username.
+// Returns ErrStyleProfileNotFound if the profile does not exist or is owned by another user.
+func (r *StyleProfileRepositoryImpl) UpdateStyleProfile(
+	username string, profileId int, name string, style domain.TranslationStyle,
+) error {
+	query := `UPDATE style_profiles SET name = $1, formality = $2, domain = $3, instructions = $4
+		WHERE profile_id = $5 AND username = $6`
+	result, err := r.DB.Exec(query, name, style.Formality, style.Domain, style.Instructions, profileId, username)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, ErrStyleProfileNotFound)
+}
+
+// DeleteStyleProfile removes a style profile owned by username.
+// Returns ErrStyleProfileNotFound if the profile does not exist or is owned by another user.
+func (r *StyleProfileRepositoryImpl) DeleteStyleProfile(username string, profileId int) error {
+	result, err := r.DB.Exec("DELETE FROM style_profiles WHERE profile_id = $1 AND username = $2", profileId, username)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, ErrStyleProfileNotFound)
+}
+
+// requireAffected returns notFound if result affected no rows.
+func requireAffected(result sql.Result, notFound error) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return notFound
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/oOSomnus/transflate/internal/task_manager/domain"
+	"github.com/redis/go-redis/v9"
+	"log"
+)
+
+// eventBufferSize is the number of events buffered for a subscriber that is busy sending the previous ones.
+const eventBufferSize = 32
+
+// TaskEventBus broadcasts the events of the tasks of a user to every subscriber of the user.
+// Publish sends an event to the current subscribers of the user.
+// Subscribe delivers the events of the user published from now on until ctx is done, when the channel is closed.
+type TaskEventBus interface {
+	Publish(ctx context.Context, username string, event domain.TaskEvent) error
+	Subscribe(ctx context.Context, username string) (<-chan domain.TaskEvent, error)
+}
+
+// RedisTaskEventBus is a TaskEventBus on Redis pub/sub with a channel per user, so that the events published by any
+// task worker or task manager replica reach the subscribers on every replica. Events are not stored: those published
+// while a user has no subscriber are lost.
+type RedisTaskEventBus struct {
+	client *redis.Client
+}
+
+// NewTaskEventBus initializes and returns a new RedisTaskEventBus instance with the provided Redis client.
+func NewTaskEventBus(client *redis.Client) *RedisTaskEventBus {
+	return &RedisTaskEventBus{client: client}
+}
+
+// buildEventChannelKey generates the Redis pub/sub channel carrying the task events of a user.
+func buildEventChannelKey(username string) string {
+	return fmt.Sprintf("task-events:%s", username)
+}
+
+// Publish sends the JSON encoded event on the channel of the user.
+func (b *RedisTaskEventBus) Publish(ctx context.Context, username string, event domain.TaskEvent) error {
+	encoded, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return b.client.Publish(ctx, buildEventChannelKey(username), encoded).Err()
+}
+
+// Subscribe subscribes to the channel of the user and returns once the subscription is confirmed, so that no event
+// published afterwards is missed. Events that cannot be decoded are logged and skipped.
+func (b *RedisTaskEventBus) Subscribe(ctx context.Context, username string) (<-chan domain.TaskEvent, error) {
+	pubsub := b.client.Subscribe(ctx, buildEventChannelKey(username))
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, err
+	}
+	events := make(chan domain.TaskEvent, eventBufferSize)
+	go func() {
+		defer close(events)
+		defer pubsub.Close()
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+				var event domain.TaskEvent
+				if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+					log.Printf("Dropping undecodable task event: %v", err)
+					continue
+				}
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
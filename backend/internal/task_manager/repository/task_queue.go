@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/oOSomnus/transflate/internal/task_manager/domain"
+	"github.com/redis/go-redis/v9"
+	"log"
+	"strings"
+	"time"
+)
+
+// jobField is the field of a stream entry holding the JSON encoded job.
+const jobField = "job"
+
+// TaskQueue defines a persistent queue of task jobs shared by any number of workers.
+// Enqueue adds a job to the queue.
+// Read delivers up to count new jobs to the consumer, waiting up to block for the first one.
+// Reclaim takes over up to count jobs that other consumers received but have not acknowledged for at least minIdle,
+// such as the jobs of a crashed worker.
+// Touch marks a job received by the consumer as still in progress, so that it is not reclaimed.
+// Ack acknowledges a job once it is done and removes it from the queue.
+type TaskQueue interface {
+	Enqueue(ctx context.Context, job domain.TaskJob) error
+	Read(ctx context.Context, consumer string, count int64, block time.Duration) ([]domain.QueuedJob, error)
+	Reclaim(ctx context.Context, consumer string, minIdle time.Duration, count int64) ([]domain.QueuedJob, error)
+	Touch(ctx context.Context, consumer string, id string) error
+	Ack(ctx context.Context, id string) error
+}
+
+// RedisTaskQueue is a TaskQueue stored in a Redis stream and consumed by a consumer group, which keeps track of the
+// jobs every worker received until they acknowledge them.
+type RedisTaskQueue struct {
+	client *redis.Client
+	stream string
+	group  string
+}
+
+// NewRedisTaskQueue initializes a RedisTaskQueue on the given stream, creating the stream and its consumer group if
+// they do not exist yet.
+func NewRedisTaskQueue(client *redis.Client, stream, group string) (*RedisTaskQueue, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err := client.XGroupCreateMkStream(ctx, stream, group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil, fmt.Errorf("failed to create consumer group: %w", err)
+	}
+	return &RedisTaskQueue{client: client, stream: stream, group: group}, nil
+}
+
+// Enqueue appends the JSON encoded job to the stream.
+func (q *RedisTaskQueue) Enqueue(ctx context.Context, job domain.TaskJob) error {
+	encoded, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return q.client.XAdd(ctx, &redis.XAddArgs{Stream: q.stream, Values: map[string]interface{}{jobField: encoded}}).Err()
+}
+
+// Read delivers up to count jobs no consumer of the group has received yet, waiting up to block for the first one.
+// Returns no jobs if none arrive in time.
+func (q *RedisTaskQueue) Read(
+	ctx context.Context, consumer string, count int64, block time.Duration,
+) ([]domain.QueuedJob, error) {
+	streams, err := q.client.XReadGroup(
+		ctx, &redis.XReadGroupArgs{
+			Group:    q.group,
+			Consumer: consumer,
+			Streams:  []string{q.stream, ">"},
+			Count:    count,
+			Block:    block,
+		},
+	).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var jobs []domain.QueuedJob
+	for _, stream := range streams {
+		jobs = append(jobs, q.decode(ctx, stream.Messages, nil)...)
+	}
+	return jobs, nil
+}
+
+// Reclaim transfers up to count jobs that have been pending for at least minIdle to the consumer and delivers them
+// again, with the number of times each has been delivered so far.
+func (q *RedisTaskQueue) Reclaim(
+	ctx context.Context, consumer string, minIdle time.Duration, count int64,
+) ([]domain.QueuedJob, error) {
+	pending, err := q.client.XPendingExt(
+		ctx, &redis.XPendingExtArgs{
+			Stream: q.stream,
+			Group:  q.group,
+			Idle:   minIdle,
+			Start:  "-",
+			End:    "+",
+			Count:  count,
+		},
+	).Result()
+	if err != nil || len(pending) == 0 {
+		return nil, err
+	}
+	ids := make([]string, len(pending))
+	deliveries := make(map[string]int64, len(pending))
+	for i, entry := range pending {
+		ids[i] = entry.ID
+		deliveries[entry.ID] = entry.RetryCount + 1
+	}
+	messages, err := q.client.XClaim(
+		ctx, &redis.XClaimArgs{
+			Stream:   q.stream,
+			Group:    q.group,
+			Consumer: consumer,
+			MinIdle:  minIdle,
+			Messages: ids,
+		},
+	).Result()
+	if err != nil {
+		return nil, err
+	}
+	return q.decode(ctx, messages, deliveries), nil
+}
+
+// Touch resets the idle time of a job the consumer is still working on, without counting it as a new delivery.
+func (q *RedisTaskQueue) Touch(ctx context.Context, consumer string, id string) error {
+	return q.client.XClaimJustID(
+		ctx, &redis.XClaimArgs{Stream: q.stream, Group: q.group, Consumer: consumer, Messages: []string{id}},
+	).Err()
+}
+
+// Ack acknowledges a job and deletes its entry from the stream.
+func (q *RedisTaskQueue) Ack(ctx context.Context, id string) error {
+	if err := q.client.XAck(ctx, q.stream, q.group, id).Err(); err != nil {
+		return err
+	}
+	return q.client.XDel(ctx, q.stream, id).Err()
+}
+
+// decode converts stream entries into queued jobs, using the delivery counts if given and counting a first delivery
+// otherwise. Entries that cannot be decoded would fail on every delivery, so they are logged and acknowledged.
+func (q *RedisTaskQueue) decode(
+	ctx context.Context, messages []redis.XMessage, deliveries map[string]int64,
+) []domain.QueuedJob {
+	jobs := make([]domain.QueuedJob, 0, len(messages))
+	for _, message := range messages {
+		queued := domain.QueuedJob{ID: message.ID, Deliveries: 1}
+		if count, ok := deliveries[message.ID]; ok {
+			queued.Deliveries = count
+		}
+		encoded, _ := message.Values[jobField].(string)
+		if err := json.Unmarshal([]byte(encoded), &queued.Job); err != nil {
+			log.Printf("Dropping undecodable job %s: %v", message.ID, err)
+			if err := q.Ack(ctx, message.ID); err != nil {
+				log.Printf("Error acknowledging job %s: %v", message.ID, err)
+			}
+			continue
+		}
+		jobs = append(jobs, queued)
+	}
+	return jobs
+}
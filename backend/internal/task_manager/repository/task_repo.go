@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
@@ -11,10 +12,44 @@ import (
 )
 
 // ErrTaskNotFound indicates that the requested task was not found or has expired in the data store.
+// ErrUnexpectedTaskStatus indicates that a task is not in the status a transition requires.
 var (
-	ErrTaskNotFound = errors.New("task not found or expired")
+	ErrTaskNotFound         = errors.New("task not found or expired")
+	ErrUnexpectedTaskStatus = errors.New("task is not in the expected status")
 )
 
+// transitionStatusScript sets the status of the task hash in KEYS[1] to ARGV[2] if it currently is ARGV[1].
+// It returns -1 if the task does not exist, 0 if its status differs and 1 once the status has been changed.
+var transitionStatusScript = redis.NewScript(`
+local status = redis.call('HGET', KEYS[1], 'status')
+if not status then
+	return -1
+end
+if tonumber(status) ~= tonumber(ARGV[1]) then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'status', ARGV[2])
+return 1
+`)
+
+// updateStatusScript sets the status of the task hash in KEYS[1] to ARGV[1] and its TTL to ARGV[2] seconds, unless
+// its status is one of the remaining arguments.
+// It returns -1 if the task does not exist, 0 if its status is excluded and 1 once the status has been changed.
+var updateStatusScript = redis.NewScript(`
+local status = redis.call('HGET', KEYS[1], 'status')
+if not status then
+	return -1
+end
+for i = 3, #ARGV do
+	if tonumber(status) == tonumber(ARGV[i]) then
+		return 0
+	end
+end
+redis.call('HSET', KEYS[1], 'status', ARGV[1])
+redis.call('EXPIRE', KEYS[1], ARGV[2])
+return 1
+`)
+
 // TaskRepository defines methods for managing and interacting with user tasks and their associated states and metadata.
 type TaskRepository interface {
 	// SetTaskState: If the filename does not exist, create and set it. If it already exists, the original filename will not be overwritten. Set new status and TTL every time
@@ -28,6 +63,26 @@ type TaskRepository interface {
 
 	// UpdateTaskLink: Download link for update tasks
 	UpdateTaskLink(ctx context.Context, username, taskId, link string) error
+
+	// UpdateTaskFields: Set additional fields of an existing task, such as JSON encoded glossary violations
+	UpdateTaskFields(ctx context.Context, username, taskId string, fields map[string]interface{}) error
+
+	// GetTaskFields: Get the given fields of an existing task, missing fields are left out of the result
+	GetTaskFields(ctx context.Context, username, taskId string, fields ...string) (map[string]string, error)
+
+	// DeleteTaskFields: Remove the given fields of a task, such as data only needed while it is in progress
+	DeleteTaskFields(ctx context.Context, username, taskId string, fields ...string) error
+
+	// TransitionTaskStatus: Atomically change the status of a task from one status to another
+	TransitionTaskStatus(ctx context.Context, username, taskId string, from, to int) error
+
+	// UpdateTaskStatusUnless: Atomically set the status and TTL of a task unless it is in one of the given statuses
+	UpdateTaskStatusUnless(
+		ctx context.Context, username, taskId string, status int, ttl time.Duration, unless ...int,
+	) error
+
+	// IncrementTaskField: Add to an integer field of an existing task, such as the number of pages billed
+	IncrementTaskField(ctx context.Context, username, taskId, field string, by int) error
 }
 
 // RedisTaskRepository interacts with Redis to manage task-related data for users.
@@ -142,7 +197,8 @@ func (r *RedisTaskRepository) GetTaskState(ctx context.Context, username, taskId
 }
 
 // FetchAllTask retrieves all tasks for a specified username from Redis and returns them as a nested map structure.
-// It checks for task existence, removes expired tasks, and parses task fields into appropriate types. Returns an error if any Redis operation fails.
+// The tasks are read in a single pipeline; expired tasks are removed from the set of the user, and task fields are
+// parsed into appropriate types. Returns an error if any Redis operation fails.
 func (r *RedisTaskRepository) FetchAllTask(ctx context.Context, username string) (
 	map[string]map[string]interface{}, error,
 ) {
@@ -154,30 +210,22 @@ func (r *RedisTaskRepository) FetchAllTask(ctx context.Context, username string)
 		return nil, err
 	}
 
-	for _, taskId := range taskIds {
-		key := buildTaskKey(username, taskId)
-
-		// Determine whether key still exists
-		exists, err := r.client.Exists(ctx, key).Result()
-		if err != nil {
-			return nil, err
-		}
-		if exists == 0 {
-			// May have expired or been deleted -> removed from collection
-			if remErr := r.client.SRem(ctx, buildUserSetKey(username), taskId).Err(); remErr != nil {
-				log.Printf("failed to remove expired task from set: %v", remErr)
-			}
-			continue
-		}
+	// Get the hash data of every task in a single round trip
+	pipe := r.client.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(taskIds))
+	for i, taskId := range taskIds {
+		cmds[i] = pipe.HGetAll(ctx, buildTaskKey(username, taskId))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
 
-		// Get hash data
-		vals, err := r.client.HGetAll(ctx, key).Result()
-		if err != nil {
-			return nil, err
-		}
+	var expired []interface{}
+	for i, taskId := range taskIds {
+		vals := cmds[i].Val()
 		if len(vals) == 0 {
-			// Description key does not exist or is empty -> remove it altogether
-			_ = r.client.SRem(ctx, buildUserSetKey(username), taskId).Err()
+			// May have expired or been deleted -> removed from collection
+			expired = append(expired, taskId)
 			continue
 		}
 
@@ -191,8 +239,52 @@ func (r *RedisTaskRepository) FetchAllTask(ctx context.Context, username string)
 			"link":       vals["link"],
 			"created_at": vals["created_at"],
 		}
+		if violations := vals["glossary_violations"]; violations != "" {
+			tmp["glossary_violations"] = json.RawMessage(violations)
+		}
+		if usage := vals["usage"]; usage != "" {
+			tmp["usage"] = json.RawMessage(usage)
+		}
+		if termSheet := vals["term_sheet"]; termSheet != "" {
+			tmp["term_sheet"] = json.RawMessage(termSheet)
+		}
+		if issues := vals["quality_issues"]; issues != "" {
+			retriedInt := 0
+			fmt.Sscanf(vals["retried_chunks"], "%d", &retriedInt)
+			tmp["quality_issues"] = json.RawMessage(issues)
+			tmp["retried_chunks"] = retriedInt
+		}
+		// Progress counters of the OCR and translation stages, once reported
+		for _, field := range []string{"pages_done", "pages_total", "chunks_done", "chunks_total"} {
+			if counter := vals[field]; counter != "" {
+				counterInt := 0
+				fmt.Sscanf(counter, "%d", &counterInt)
+				tmp[field] = counterInt
+			}
+		}
+		if chunks := vals["chunk_count"]; chunks != "" {
+			hitsInt, chunksInt := 0, 0
+			fmt.Sscanf(vals["memory_hits"], "%d", &hitsInt)
+			fmt.Sscanf(chunks, "%d", &chunksInt)
+			tmp["memory_hits"] = hitsInt
+			tmp["chunk_count"] = chunksInt
+			if chunksInt > 0 {
+				tmp["memory_hit_rate"] = float64(hitsInt) / float64(chunksInt)
+			}
+		}
+		// Start of the OCR and translation stages, from which the time left of their progress is estimated
+		for _, field := range []string{"ocr_started_at", "translation_started_at"} {
+			if started := vals[field]; started != "" {
+				tmp[field] = started
+			}
+		}
 		result[taskId] = tmp
 	}
+	if len(expired) > 0 {
+		if remErr := r.client.SRem(ctx, buildUserSetKey(username), expired...).Err(); remErr != nil {
+			log.Printf("failed to remove expired tasks from set: %v", remErr)
+		}
+	}
 
 	return result, nil
 }
@@ -217,3 +309,116 @@ func (r *RedisTaskRepository) UpdateTaskLink(ctx context.Context, username, task
 	}
 	return nil
 }
+
+// UpdateTaskFields sets the given hash fields of the task for the specified username and taskId in the Redis storage.
+// Returns an error if the task is not found or Redis operation fails.
+func (r *RedisTaskRepository) UpdateTaskFields(
+	ctx context.Context, username, taskId string, fields map[string]interface{},
+) error {
+	key := buildTaskKey(username, taskId)
+
+	// Determine whether key exists
+	exists, err := r.client.Exists(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	if exists == 0 {
+		return ErrTaskNotFound
+	}
+
+	if err := r.client.HSet(ctx, key, fields).Err(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetTaskFields retrieves the given hash fields of the task for the specified username and taskId from Redis.
+// Fields the task does not have are left out of the result. Returns ErrTaskNotFound if the task does not exist.
+func (r *RedisTaskRepository) GetTaskFields(
+	ctx context.Context, username, taskId string, fields ...string,
+) (map[string]string, error) {
+	key := buildTaskKey(username, taskId)
+
+	// Determine whether key exists
+	exists, err := r.client.Exists(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+	if exists == 0 {
+		return nil, ErrTaskNotFound
+	}
+
+	values, err := r.client.HMGet(ctx, key, fields...).Result()
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]string, len(fields))
+	for i, value := range values {
+		if str, ok := value.(string); ok {
+			result[fields[i]] = str
+		}
+	}
+	return result, nil
+}
+
+// DeleteTaskFields removes the given hash fields of the task for the specified username and taskId from Redis.
+func (r *RedisTaskRepository) DeleteTaskFields(ctx context.Context, username, taskId string, fields ...string) error {
+	return r.client.HDel(ctx, buildTaskKey(username, taskId), fields...).Err()
+}
+
+// TransitionTaskStatus changes the status of the task for the specified username and taskId from one status to
+// another in a single atomic step, so that concurrent requests cannot both act on the same transition.
+// Returns ErrTaskNotFound if the task does not exist and ErrUnexpectedTaskStatus if it is not in the from status.
+func (r *RedisTaskRepository) TransitionTaskStatus(ctx context.Context, username, taskId string, from, to int) error {
+	result, err := transitionStatusScript.Run(ctx, r.client, []string{buildTaskKey(username, taskId)}, from, to).Int()
+	if err != nil {
+		return err
+	}
+	switch result {
+	case -1:
+		return ErrTaskNotFound
+	case 0:
+		return ErrUnexpectedTaskStatus
+	}
+	return nil
+}
+
+// UpdateTaskStatusUnless sets the status of the task for the specified username and taskId and renews its TTL in a
+// single atomic step, unless the task is in one of the unless statuses, such as a final status it must keep.
+// Returns ErrTaskNotFound if the task does not exist and ErrUnexpectedTaskStatus if it is in an unless status.
+func (r *RedisTaskRepository) UpdateTaskStatusUnless(
+	ctx context.Context, username, taskId string, status int, ttl time.Duration, unless ...int,
+) error {
+	args := []interface{}{status, int(ttl.Seconds())}
+	for _, excluded := range unless {
+		args = append(args, excluded)
+	}
+	result, err := updateStatusScript.Run(ctx, r.client, []string{buildTaskKey(username, taskId)}, args...).Int()
+	if err != nil {
+		return err
+	}
+	switch result {
+	case -1:
+		return ErrTaskNotFound
+	case 0:
+		return ErrUnexpectedTaskStatus
+	}
+	return nil
+}
+
+// IncrementTaskField adds by to the integer hash field of the task for the specified username and taskId, starting
+// from zero if the field is missing. Returns ErrTaskNotFound if the task does not exist.
+func (r *RedisTaskRepository) IncrementTaskField(ctx context.Context, username, taskId, field string, by int) error {
+	key := buildTaskKey(username, taskId)
+
+	// Determine whether key exists
+	exists, err := r.client.Exists(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	if exists == 0 {
+		return ErrTaskNotFound
+	}
+
+	return r.client.HIncrBy(ctx, key, field, int64(by)).Err()
+}
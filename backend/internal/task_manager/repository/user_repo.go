@@ -5,7 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"log"
+	"github.com/oOSomnus/transflate/internal/task_manager/domain"
 	"time"
 )
 
@@ -13,16 +13,39 @@ import (
 // FindUsrWithUsername retrieves a user's information using their username.
 // IfUserExists checks whether a user with the given username exists.
 // CreateUser creates a new user with the specified username and password.
-// DecreaseBalance reduces the user's balance by the specified amount.
+// DecreaseBalance reduces the user's balance by the specified amount, recording the reason in the ledger.
+// IncreaseBalance adds the specified amount to the user's balance, recording the reason in the ledger.
 // GetBalance retrieves the current balance of the user.
+// ReserveBalance subtracts the pages of a task from the balance of the user and holds them until the reservation is
+// committed or released, unless the task already holds or has been charged its pages.
+// RenewReservation reserves the pages of a released reservation of a task again, such as when a failed task is retried.
+// CommitReservation charges the pages reserved for a task for good.
+// ReleaseReservation gives the pages reserved for a task back to the balance of the user for the given reason.
+// ReverseCharge gives the pages charged for a task back to the balance of the user for the given reason, such as when
+// the task could not be delivered after it was charged.
+// ReleaseExpiredReservations releases the reservations that were neither committed nor released before they expired.
 type UserRepository interface {
 	FindUsrWithUsername(username string) (string, error)
 	IfUserExists(username string) (bool, error)
 	CreateUser(username string, password string) error
-	DecreaseBalance(username string, balance int) error
+	DecreaseBalance(username string, balance int, reason string) error
+	IncreaseBalance(username string, amount int, reason string) error
 	GetBalance(username string) (int, error)
+	ReserveBalance(username, taskId string, pages int, ttl time.Duration) (bool, error)
+	RenewReservation(username, taskId string, ttl time.Duration) (int, error)
+	CommitReservation(username, taskId string) (int, error)
+	ReleaseReservation(username, taskId, reason string) (int, error)
+	ReverseCharge(username, taskId, reason string) (int, error)
+	ReleaseExpiredReservations(limit int) ([]domain.BalanceReservation, error)
 }
 
+// ErrInsufficientBalance indicates that the balance of a user does not cover the amount to subtract.
+// ErrReservationReleased indicates that the reservation of a task to commit has already been released.
+var (
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	ErrReservationReleased = errors.New("balance reservation already released")
+)
+
 // UserRepositoryImpl interacts with the database to perform user-related operations like querying and updating data.
 // It wraps around an *sql.DB instance for executing SQL queries and managing transactions.
 type UserRepositoryImpl struct {
@@ -77,9 +100,11 @@ func (r *UserRepositoryImpl) CreateUser(username string, password string) error
 	return nil
 }
 
-// DecreaseBalance decreases the balance of the specified user by the given amount.
-// Returns an error if the balance is insufficient, the user is not found, or there is a transaction/database failure.
-func (r *UserRepositoryImpl) DecreaseBalance(username string, balance int) error {
+// DecreaseBalance decreases the balance of the specified user by the given amount and records the debit with reason
+// in the ledger, in a single transaction.
+// Returns ErrInsufficientBalance if the balance is insufficient, or an error if the user is not found or there is a
+// transaction/database failure.
+func (r *UserRepositoryImpl) DecreaseBalance(username string, balance int, reason string) error {
 	if balance <= 0 {
 		return errors.New("invalid amount")
 	}
@@ -91,17 +116,7 @@ func (r *UserRepositoryImpl) DecreaseBalance(username string, balance int) error
 	if err != nil {
 		return fmt.Errorf("begin tx: %w", err)
 	}
-
-	defer func() {
-		if p := recover(); p != nil {
-			_ = tx.Rollback()
-			log.Println("recovered from panic:", p)
-		} else if err != nil {
-			_ = tx.Rollback()
-		} else {
-			_ = tx.Commit()
-		}
-	}()
+	defer func() { _ = tx.Rollback() }()
 
 	var currentBalance int
 	query := "SELECT balance FROM users WHERE username = $1 FOR UPDATE"
@@ -114,14 +129,47 @@ func (r *UserRepositoryImpl) DecreaseBalance(username string, balance int) error
 	}
 
 	if currentBalance < balance {
-		return errors.New("insufficient balance")
+		return ErrInsufficientBalance
+	}
+
+	transaction := domain.BalanceTransaction{
+		Username: username, Kind: domain.TransactionDebit, Amount: -balance, Reason: reason,
+	}
+	if err := changeBalanceInTx(ctx, tx, transaction); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+// IncreaseBalance increases the balance of the specified user by the given amount and records the credit with reason
+// in the ledger, in a single transaction.
+// Returns an error if the amount is not positive, the user is not found, or the database update fails.
+func (r *UserRepositoryImpl) IncreaseBalance(username string, amount int, reason string) error {
+	if amount <= 0 {
+		return errors.New("invalid amount")
 	}
 
-	_, err = tx.ExecContext(ctx, "UPDATE users SET balance = balance - $1 WHERE username = $2", balance, username)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to update balance: %w", err)
+		return fmt.Errorf("begin tx: %w", err)
 	}
+	defer func() { _ = tx.Rollback() }()
 
+	transaction := domain.BalanceTransaction{
+		Username: username, Kind: domain.TransactionCredit, Amount: amount, Reason: reason,
+	}
+	if err := changeBalanceInTx(ctx, tx, transaction); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
 	return nil
 }
 
@@ -140,3 +188,285 @@ func (r *UserRepositoryImpl) GetBalance(username string) (int, error) {
 	}
 	return balance, nil
 }
+
+// ReserveBalance subtracts pages from the balance of username and records them as reserved for the task until ttl from
+// now, in a single transaction. Returns false without changing the balance if the task already has a reservation that
+// has not been released, so that a task is charged once even if its OCR runs again, and reserves the pages again if
+// its reservation has been released. Returns ErrInsufficientBalance if the balance does not cover the pages.
+func (r *UserRepositoryImpl) ReserveBalance(username, taskId string, pages int, ttl time.Duration) (bool, error) {
+	if pages <= 0 {
+		return false, errors.New("invalid amount")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var status string
+	query := "SELECT status FROM balance_reservations WHERE username = $1 AND task_id = $2 FOR UPDATE"
+	err = tx.QueryRowContext(ctx, query, username, taskId).Scan(&status)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return false, fmt.Errorf("failed to get reservation: %w", err)
+	}
+	if err == nil && status != domain.ReservationReleased {
+		return false, nil
+	}
+	if err := reserveInTx(ctx, tx, username, taskId, pages, ttl, domain.ReasonPagesRecognized); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("commit tx: %w", err)
+	}
+	return true, nil
+}
+
+// RenewReservation reserves the pages of the released reservation of the task again until ttl from now, in a single
+// transaction, and returns the number of pages reserved. Returns 0 if the task has no reservation or its reservation
+// has not been released. Returns ErrInsufficientBalance if the balance of username does not cover the pages.
+func (r *UserRepositoryImpl) RenewReservation(username, taskId string, ttl time.Duration) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var pages int
+	query := `SELECT pages FROM balance_reservations WHERE username = $1 AND task_id = $2 AND status = $3
+		FOR UPDATE`
+	err = tx.QueryRowContext(ctx, query, username, taskId, domain.ReservationReleased).Scan(&pages)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get reservation: %w", err)
+	}
+	if err := reserveInTx(ctx, tx, username, taskId, pages, ttl, domain.ReasonTaskRetried); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit tx: %w", err)
+	}
+	return pages, nil
+}
+
+// reserveInTx subtracts pages from the balance of username and records them as reserved for the task until ttl from
+// now within tx, replacing any released reservation of the task, and records the reservation with reason in the ledger.
+// Returns ErrInsufficientBalance if the balance does not cover the pages.
+func reserveInTx(
+	ctx context.Context, tx *sql.Tx, username, taskId string, pages int, ttl time.Duration, reason string,
+) error {
+	var currentBalance int
+	query := "SELECT balance FROM users WHERE username = $1 FOR UPDATE"
+	if err := tx.QueryRowContext(ctx, query, username).Scan(&currentBalance); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("user not found")
+		}
+		return fmt.Errorf("failed to get current balance: %w", err)
+	}
+	if currentBalance < pages {
+		return ErrInsufficientBalance
+	}
+	transaction := domain.BalanceTransaction{
+		Username: username, Kind: domain.TransactionReservation, Amount: -pages, TaskID: taskId, Reason: reason,
+	}
+	if err := changeBalanceInTx(ctx, tx, transaction); err != nil {
+		return err
+	}
+	query = `INSERT INTO balance_reservations (username, task_id, pages, status, expires_at)
+		VALUES ($1, $2, $3, $4, NOW() + make_interval(secs => $5))
+		ON CONFLICT (username, task_id) DO UPDATE SET pages = EXCLUDED.pages, status = EXCLUDED.status,
+		created_at = CURRENT_TIMESTAMP, expires_at = EXCLUDED.expires_at, settled_at = NULL`
+	_, err := tx.ExecContext(ctx, query, username, taskId, pages, domain.ReservationReserved, ttl.Seconds())
+	if err != nil {
+		return fmt.Errorf("failed to record reservation: %w", err)
+	}
+	return nil
+}
+
+// CommitReservation marks the pages reserved for the task as charged, records the charge in the ledger in the same
+// transaction and returns their number. Returns 0 if the task has no reservation or its reservation has already been
+// committed, and ErrReservationReleased if it has been released, such as when it expired before the task was done.
+func (r *UserRepositoryImpl) CommitReservation(username, taskId string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var pages int
+	query := `UPDATE balance_reservations SET status = $1, settled_at = NOW()
+		WHERE username = $2 AND task_id = $3 AND status = $4 RETURNING pages`
+	err = tx.QueryRowContext(
+		ctx, query, domain.ReservationCommitted, username, taskId, domain.ReservationReserved,
+	).Scan(&pages)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, checkUncommitted(ctx, tx, username, taskId)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to commit reservation: %w", err)
+	}
+
+	// The pages left the balance when they were reserved, so the charge is recorded with an amount of zero.
+	transaction := domain.BalanceTransaction{
+		Username: username, Kind: domain.TransactionCommit, TaskID: taskId, Reason: domain.ReasonTaskCompleted,
+	}
+	if err := changeBalanceInTx(ctx, tx, transaction); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit tx: %w", err)
+	}
+	return pages, nil
+}
+
+// checkUncommitted returns ErrReservationReleased if the reservation of the task has been released, and nil if the
+// task has no reservation or its reservation has been committed.
+func checkUncommitted(ctx context.Context, tx *sql.Tx, username, taskId string) error {
+	var status string
+	query := "SELECT status FROM balance_reservations WHERE username = $1 AND task_id = $2"
+	err := tx.QueryRowContext(ctx, query, username, taskId).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get reservation: %w", err)
+	}
+	if status == domain.ReservationReleased {
+		return ErrReservationReleased
+	}
+	return nil
+}
+
+// ReleaseReservation adds the pages reserved for the task back to the balance of username, marks them as released and
+// records the refund with reason in the ledger in a single transaction, and returns their number. Returns 0 if the
+// task has no reservation or its reservation has already been committed or released, so that the handler cancelling a
+// task and the worker processing it can both release it without refunding any page twice.
+func (r *UserRepositoryImpl) ReleaseReservation(username, taskId, reason string) (int, error) {
+	return r.releaseReservation(username, taskId, reason, domain.ReservationReserved)
+}
+
+// ReverseCharge adds the pages charged for the task back to the balance of username, marks its reservation as released
+// and records the refund with reason in the ledger in a single transaction, and returns their number. Returns 0 if the
+// task has no reservation or its reservation has not been committed, so that a charge is never reversed twice.
+func (r *UserRepositoryImpl) ReverseCharge(username, taskId, reason string) (int, error) {
+	return r.releaseReservation(username, taskId, reason, domain.ReservationCommitted)
+}
+
+// releaseReservation releases the reservation of the task if it is in the given status, adding its pages back to the
+// balance of username and recording the refund with reason in the ledger in a single transaction, and returns their
+// number. Returns 0 if the task has no reservation in that status.
+func (r *UserRepositoryImpl) releaseReservation(username, taskId, reason, status string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var pages int
+	query := `UPDATE balance_reservations SET status = $1, settled_at = NOW()
+		WHERE username = $2 AND task_id = $3 AND status = $4 RETURNING pages`
+	err = tx.QueryRowContext(ctx, query, domain.ReservationReleased, username, taskId, status).Scan(&pages)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to release reservation: %w", err)
+	}
+	transaction := domain.BalanceTransaction{
+		Username: username, Kind: domain.TransactionRefund, Amount: pages, TaskID: taskId, Reason: reason,
+	}
+	if err := changeBalanceInTx(ctx, tx, transaction); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit tx: %w", err)
+	}
+	return pages, nil
+}
+
+// ReleaseExpiredReservations releases up to limit reservations that expired before being committed or released,
+// adding their pages back to the balance of their users and recording the refunds in the ledger in a single
+// transaction, and returns them. Reservations
+// released concurrently by another caller are skipped.
+func (r *UserRepositoryImpl) ReleaseExpiredReservations(limit int) ([]domain.BalanceReservation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `UPDATE balance_reservations SET status = $1, settled_at = NOW()
+		WHERE reservation_id IN (
+			SELECT reservation_id FROM balance_reservations
+			WHERE status = $2 AND expires_at <= NOW()
+			ORDER BY expires_at LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING reservation_id, username, task_id, pages, status, created_at, expires_at, settled_at`
+	rows, err := tx.QueryContext(ctx, query, domain.ReservationReleased, domain.ReservationReserved, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to release expired reservations: %w", err)
+	}
+	reservations := make([]domain.BalanceReservation, 0)
+	for rows.Next() {
+		var reservation domain.BalanceReservation
+		err := rows.Scan(
+			&reservation.ID, &reservation.Username, &reservation.TaskID, &reservation.Pages, &reservation.Status,
+			&reservation.CreatedAt, &reservation.ExpiresAt, &reservation.SettledAt,
+		)
+		if err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		reservations = append(reservations, reservation)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, reservation := range reservations {
+		transaction := domain.BalanceTransaction{
+			Username: reservation.Username, Kind: domain.TransactionRefund, Amount: reservation.Pages,
+			TaskID: reservation.TaskID, Reason: domain.ReasonReservationExpired,
+		}
+		if err := changeBalanceInTx(ctx, tx, transaction); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+	return reservations, nil
+}
+
+// changeBalanceInTx adds the amount of the transaction to the balance of its user within tx and records the
+// transaction in the ledger with the resulting balance, so that every change of a balance has its ledger entry.
+func changeBalanceInTx(ctx context.Context, tx *sql.Tx, transaction domain.BalanceTransaction) error {
+	query := "UPDATE users SET balance = balance + $1 WHERE username = $2 RETURNING balance"
+	err := tx.QueryRowContext(ctx, query, transaction.Amount, transaction.Username).Scan(&transaction.BalanceAfter)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("user not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update balance: %w", err)
+	}
+	return recordTransaction(ctx, tx, transaction)
+}
@@ -0,0 +1,290 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/oOSomnus/transflate/internal/task_manager/domain"
+	"strings"
+	"time"
+)
+
+// ErrWebhookNotFound indicates that the requested webhook does not exist or belongs to another user.
+// ErrWebhookSecretNotFound indicates that the user has no webhook secret yet.
+// ErrWebhookDeliveryNotFound indicates that the requested delivery does not exist or belongs to another user.
+var (
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookSecretNotFound   = errors.New("webhook secret not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+// WebhookRepository defines methods for managing the webhooks of users, their signing secrets and the delivery log.
+// CreateWebhook registers a webhook URL for the user and returns the webhook.
+// ListWebhooks retrieves all webhooks of the user.
+// DeleteWebhook removes a webhook of the user together with its deliveries.
+// EnsureWebhookSecret stores candidate as the webhook secret of the user unless the user has one, and returns the
+// secret of the user.
+// SetWebhookSecret replaces the webhook secret of the user.
+// GetWebhookSecret retrieves the webhook secret of the user.
+// CreateDeliveries queues a pending delivery of the payload to every webhook of the user and returns their number.
+// ListDeliveries retrieves the deliveries of the user selected by the filter.
+// GetDelivery retrieves a delivery of the user.
+// ReplayDelivery queues a new pending delivery of the payload of a delivery of the user and returns its ID.
+// ClaimDueDeliveries leases up to limit pending deliveries whose next attempt is due, postponing their next attempt by
+// lease so that no other dispatcher claims them while they are attempted.
+// UpdateDelivery records the outcome of an attempt of a delivery.
+type WebhookRepository interface {
+	CreateWebhook(username, url string) (*domain.Webhook, error)
+	ListWebhooks(username string) ([]domain.Webhook, error)
+	DeleteWebhook(username string, webhookId int) error
+	EnsureWebhookSecret(username, candidate string) (string, error)
+	SetWebhookSecret(username, secret string) error
+	GetWebhookSecret(username string) (string, error)
+	CreateDeliveries(username, event, taskId string, payload []byte) (int, error)
+	ListDeliveries(username string, filter domain.DeliveryFilter) ([]domain.WebhookDelivery, error)
+	GetDelivery(username string, deliveryId int) (*domain.WebhookDelivery, error)
+	ReplayDelivery(username string, deliveryId int) (int, error)
+	ClaimDueDeliveries(limit int, lease time.Duration) ([]domain.WebhookDelivery, error)
+	UpdateDelivery(delivery *domain.WebhookDelivery) error
+}
+
+// WebhookRepositoryImpl stores webhooks, webhook secrets and webhook deliveries in PostgreSQL.
+type WebhookRepositoryImpl struct {
+	DB *sql.DB
+}
+
+// NewWebhookRepository initializes a new WebhookRepositoryImpl with a given sql.DB connection and returns its instance.
+func NewWebhookRepository(db *sql.DB) *WebhookRepositoryImpl {
+	return &WebhookRepositoryImpl{
+		DB: db,
+	}
+}
+
+// deliveryColumns are the columns of a delivery joined with the URL of its webhook, in the order scanDelivery reads
+// them.
+const deliveryColumns = `d.delivery_id, d.webhook_id, w.url, d.username, d.event, d.task_id, d.payload, d.status,
+	d.attempts, d.next_attempt_at, d.last_status_code, d.last_error, d.replay_of, d.created_at, d.delivered_at`
+
+// CreateWebhook inserts a new webhook owned by username and returns it with its generated ID and creation time.
+func (r *WebhookRepositoryImpl) CreateWebhook(username, url string) (*domain.Webhook, error) {
+	query := "INSERT INTO webhooks (username, url) VALUES ($1, $2) RETURNING webhook_id, url, created_at"
+	var webhook domain.Webhook
+	if err := r.DB.QueryRow(query, username, url).Scan(&webhook.ID, &webhook.URL, &webhook.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// ListWebhooks retrieves the webhooks owned by username ordered by creation time.
+func (r *WebhookRepositoryImpl) ListWebhooks(username string) ([]domain.Webhook, error) {
+	query := "SELECT webhook_id, url, created_at FROM webhooks WHERE username = $1 ORDER BY created_at, webhook_id"
+	rows, err := r.DB.Query(query, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := make([]domain.Webhook, 0)
+	for rows.Next() {
+		var webhook domain.Webhook
+		if err := rows.Scan(&webhook.ID, &webhook.URL, &webhook.CreatedAt); err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+// DeleteWebhook removes a webhook owned by username, whose deliveries are removed along with it.
+// Returns ErrWebhookNotFound if the webhook does not exist or is owned by another user.
+func (r *WebhookRepositoryImpl) DeleteWebhook(username string, webhookId int) error {
+	result, err := r.DB.Exec("DELETE FROM webhooks WHERE webhook_id = $1 AND username = $2", webhookId, username)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, ErrWebhookNotFound)
+}
+
+// EnsureWebhookSecret inserts candidate as the webhook secret of username unless one exists, and returns the stored
+// secret, so that concurrent calls agree on a single secret.
+func (r *WebhookRepositoryImpl) EnsureWebhookSecret(username, candidate string) (string, error) {
+	query := `INSERT INTO webhook_secrets (username, secret) VALUES ($1, $2)
+		ON CONFLICT (username) DO NOTHING`
+	if _, err := r.DB.Exec(query, username, candidate); err != nil {
+		return "", err
+	}
+	return r.GetWebhookSecret(username)
+}
+
+// SetWebhookSecret stores secret as the webhook secret of username, replacing any previous secret.
+func (r *WebhookRepositoryImpl) SetWebhookSecret(username, secret string) error {
+	query := `INSERT INTO webhook_secrets (username, secret) VALUES ($1, $2)
+		ON CONFLICT (username) DO UPDATE SET secret = EXCLUDED.secret, created_at = CURRENT_TIMESTAMP`
+	_, err := r.DB.Exec(query, username, secret)
+	return err
+}
+
+// GetWebhookSecret retrieves the webhook secret of username.
+// Returns ErrWebhookSecretNotFound if the user has no secret.
+func (r *WebhookRepositoryImpl) GetWebhookSecret(username string) (string, error) {
+	var secret string
+	err := r.DB.QueryRow("SELECT secret FROM webhook_secrets WHERE username = $1", username).Scan(&secret)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrWebhookSecretNotFound
+		}
+		return "", err
+	}
+	return secret, nil
+}
+
+// CreateDeliveries inserts a pending delivery of the payload to every webhook owned by username, due immediately,
+// and returns the number of deliveries inserted.
+func (r *WebhookRepositoryImpl) CreateDeliveries(username, event, taskId string, payload []byte) (int, error) {
+	query := `INSERT INTO webhook_deliveries (webhook_id, username, event, task_id, payload)
+		SELECT webhook_id, username, $2, $3, $4 FROM webhooks WHERE username = $1`
+	result, err := r.DB.Exec(query, username, event, taskId, string(payload))
+	if err != nil {
+		return 0, err
+	}
+	created, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(created), nil
+}
+
+// ListDeliveries retrieves the deliveries owned by username selected by the filter, newest first.
+func (r *WebhookRepositoryImpl) ListDeliveries(
+	username string, filter domain.DeliveryFilter,
+) ([]domain.WebhookDelivery, error) {
+	conditions := []string{"d.username = $1"}
+	args := []interface{}{username}
+	if filter.WebhookID != 0 {
+		args = append(args, filter.WebhookID)
+		conditions = append(conditions, fmt.Sprintf("d.webhook_id = $%d", len(args)))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("d.status = $%d", len(args)))
+	}
+	args = append(args, filter.Limit)
+	query := fmt.Sprintf(
+		`SELECT %s FROM webhook_deliveries d JOIN webhooks w ON w.webhook_id = d.webhook_id
+		WHERE %s ORDER BY d.created_at DESC, d.delivery_id DESC LIMIT $%d`,
+		deliveryColumns, strings.Join(conditions, " AND "), len(args),
+	)
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanDeliveries(rows)
+}
+
+// GetDelivery retrieves a delivery owned by username.
+// Returns ErrWebhookDeliveryNotFound if the delivery does not exist or is owned by another user.
+func (r *WebhookRepositoryImpl) GetDelivery(username string, deliveryId int) (*domain.WebhookDelivery, error) {
+	query := fmt.Sprintf(
+		`SELECT %s FROM webhook_deliveries d JOIN webhooks w ON w.webhook_id = d.webhook_id
+		WHERE d.delivery_id = $1 AND d.username = $2`, deliveryColumns,
+	)
+	delivery, err := scanDelivery(r.DB.QueryRow(query, deliveryId, username))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWebhookDeliveryNotFound
+		}
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// ReplayDelivery inserts a pending copy of a delivery owned by username, due immediately, and returns its ID. The
+// copy posts the same payload to the same webhook and refers to the delivery it was copied from.
+// Returns ErrWebhookDeliveryNotFound if the delivery does not exist or is owned by another user.
+func (r *WebhookRepositoryImpl) ReplayDelivery(username string, deliveryId int) (int, error) {
+	query := `INSERT INTO webhook_deliveries (webhook_id, username, event, task_id, payload, replay_of)
+		SELECT webhook_id, username, event, task_id, payload, delivery_id FROM webhook_deliveries
+		WHERE delivery_id = $1 AND username = $2
+		RETURNING delivery_id`
+	var replayId int
+	if err := r.DB.QueryRow(query, deliveryId, username).Scan(&replayId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrWebhookDeliveryNotFound
+		}
+		return 0, err
+	}
+	return replayId, nil
+}
+
+// ClaimDueDeliveries postpones the next attempt of up to limit pending deliveries that are due by lease and returns
+// them, oldest due first. Deliveries locked by another dispatcher are skipped, and a delivery whose dispatcher crashes
+// is attempted again once its lease expires.
+func (r *WebhookRepositoryImpl) ClaimDueDeliveries(limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	query := fmt.Sprintf(
+		`UPDATE webhook_deliveries d SET next_attempt_at = NOW() + make_interval(secs => $2)
+		FROM webhooks w
+		WHERE w.webhook_id = d.webhook_id AND d.delivery_id IN (
+			SELECT delivery_id FROM webhook_deliveries
+			WHERE status = $3 AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at, delivery_id LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING %s`, deliveryColumns,
+	)
+	rows, err := r.DB.Query(query, limit, lease.Seconds(), domain.DeliveryPending)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanDeliveries(rows)
+}
+
+// UpdateDelivery stores the status, attempt count, next attempt, outcome of the last attempt and delivery time of a
+// delivery.
+func (r *WebhookRepositoryImpl) UpdateDelivery(delivery *domain.WebhookDelivery) error {
+	query := `UPDATE webhook_deliveries SET status = $1, attempts = $2, next_attempt_at = $3, last_status_code = $4,
+		last_error = $5, delivered_at = $6 WHERE delivery_id = $7`
+	result, err := r.DB.Exec(
+		query, delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.LastStatusCode, delivery.LastError,
+		delivery.DeliveredAt, delivery.ID,
+	)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, ErrWebhookDeliveryNotFound)
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanDelivery reads a delivery selected with deliveryColumns.
+func scanDelivery(row rowScanner) (*domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	var payload []byte
+	err := row.Scan(
+		&delivery.ID, &delivery.WebhookID, &delivery.URL, &delivery.Username, &delivery.Event, &delivery.TaskID,
+		&payload, &delivery.Status, &delivery.Attempts, &delivery.NextAttemptAt, &delivery.LastStatusCode,
+		&delivery.LastError, &delivery.ReplayOf, &delivery.CreatedAt, &delivery.DeliveredAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	delivery.Payload = payload
+	return &delivery, nil
+}
+
+// scanDeliveries reads all deliveries of rows selected with deliveryColumns.
+func scanDeliveries(rows *sql.Rows) ([]domain.WebhookDelivery, error) {
+	deliveries := make([]domain.WebhookDelivery, 0)
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+	return deliveries, rows.Err()
+}
@@ -5,6 +5,7 @@
 package service
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -49,16 +50,16 @@ func (mr *MockOCRClientMockRecorder) Close() *gomock.Call {
 }
 
 // ProcessOCR mocks base method.
-func (m *MockOCRClient) ProcessOCR(fileContent []byte, lang string) (*ocr.StringListResponse, error) {
+func (m *MockOCRClient) ProcessOCR(ctx context.Context, fileContent []byte, lang string, progress ProgressFunc) (*ocr.StringListResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ProcessOCR", fileContent, lang)
+	ret := m.ctrl.Call(m, "ProcessOCR", ctx, fileContent, lang, progress)
 	ret0, _ := ret[0].(*ocr.StringListResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ProcessOCR indicates an expected call of ProcessOCR.
-func (mr *MockOCRClientMockRecorder) ProcessOCR(fileContent, lang interface{}) *gomock.Call {
+func (mr *MockOCRClientMockRecorder) ProcessOCR(ctx, fileContent, lang, progress interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessOCR", reflect.TypeOf((*MockOCRClient)(nil).ProcessOCR), fileContent, lang)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessOCR", reflect.TypeOf((*MockOCRClient)(nil).ProcessOCR), ctx, fileContent, lang, progress)
 }
@@ -34,6 +34,20 @@ func (m *MockS3StorageService) EXPECT() *MockS3StorageServiceMockRecorder {
 	return m.recorder
 }
 
+// DeleteObject mocks base method.
+func (m *MockS3StorageService) DeleteObject(bucketName, objectKey string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteObject", bucketName, objectKey)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteObject indicates an expected call of DeleteObject.
+func (mr *MockS3StorageServiceMockRecorder) DeleteObject(bucketName, objectKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteObject", reflect.TypeOf((*MockS3StorageService)(nil).DeleteObject), bucketName, objectKey)
+}
+
 // GeneratePresignedURL mocks base method.
 func (m *MockS3StorageService) GeneratePresignedURL(bucketName, objectKey string, expiration time.Duration) (string, error) {
 	m.ctrl.T.Helper()
@@ -49,6 +63,35 @@ func (mr *MockS3StorageServiceMockRecorder) GeneratePresignedURL(bucketName, obj
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GeneratePresignedURL", reflect.TypeOf((*MockS3StorageService)(nil).GeneratePresignedURL), bucketName, objectKey, expiration)
 }
 
+// GetObject mocks base method.
+func (m *MockS3StorageService) GetObject(bucketName, objectKey string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetObject", bucketName, objectKey)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetObject indicates an expected call of GetObject.
+func (mr *MockS3StorageServiceMockRecorder) GetObject(bucketName, objectKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetObject", reflect.TypeOf((*MockS3StorageService)(nil).GetObject), bucketName, objectKey)
+}
+
+// PutObject mocks base method.
+func (m *MockS3StorageService) PutObject(bucketName, objectKey string, content []byte, expirationDays int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutObject", bucketName, objectKey, content, expirationDays)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutObject indicates an expected call of PutObject.
+func (mr *MockS3StorageServiceMockRecorder) PutObject(bucketName, objectKey, content, expirationDays interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutObject", reflect.TypeOf((*MockS3StorageService)(nil).PutObject), bucketName, objectKey, content, expirationDays)
+}
+
 // UploadFileToS3 mocks base method.
 func (m *MockS3StorageService) UploadFileToS3(bucketName, objectKey, filePath string, expirationDays int) error {
 	m.ctrl.T.Helper()
@@ -5,9 +5,11 @@
 package service
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	domain "github.com/oOSomnus/transflate/internal/task_manager/domain"
 )
 
 // MockTaskStatusService is a mock of TaskStatusService interface.
@@ -33,6 +35,35 @@ func (m *MockTaskStatusService) EXPECT() *MockTaskStatusServiceMockRecorder {
 	return m.recorder
 }
 
+// CancelTask mocks base method.
+func (m *MockTaskStatusService) CancelTask(username, taskId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelTask", username, taskId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelTask indicates an expected call of CancelTask.
+func (mr *MockTaskStatusServiceMockRecorder) CancelTask(username, taskId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelTask", reflect.TypeOf((*MockTaskStatusService)(nil).CancelTask), username, taskId)
+}
+
+// ConfirmTermReview mocks base method.
+func (m *MockTaskStatusService) ConfirmTermReview(username, taskId string) (*domain.TermReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTermReview", username, taskId)
+	ret0, _ := ret[0].(*domain.TermReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmTermReview indicates an expected call of ConfirmTermReview.
+func (mr *MockTaskStatusServiceMockRecorder) ConfirmTermReview(username, taskId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTermReview", reflect.TypeOf((*MockTaskStatusService)(nil).ConfirmTermReview), username, taskId)
+}
+
 // CreateNewTask mocks base method.
 func (m *MockTaskStatusService) CreateNewTask(username, filename string) (string, error) {
 	m.ctrl.T.Helper()
@@ -48,6 +79,20 @@ func (mr *MockTaskStatusServiceMockRecorder) CreateNewTask(username, filename in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNewTask", reflect.TypeOf((*MockTaskStatusService)(nil).CreateNewTask), username, filename)
 }
 
+// EndTaskStage mocks base method.
+func (m *MockTaskStatusService) EndTaskStage(taskId, stage string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EndTaskStage", taskId, stage)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EndTaskStage indicates an expected call of EndTaskStage.
+func (mr *MockTaskStatusServiceMockRecorder) EndTaskStage(taskId, stage interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EndTaskStage", reflect.TypeOf((*MockTaskStatusService)(nil).EndTaskStage), taskId, stage)
+}
+
 // GetAllTask mocks base method.
 func (m *MockTaskStatusService) GetAllTask(username string) (map[string]map[string]interface{}, error) {
 	m.ctrl.T.Helper()
@@ -63,6 +108,21 @@ func (mr *MockTaskStatusServiceMockRecorder) GetAllTask(username interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllTask", reflect.TypeOf((*MockTaskStatusService)(nil).GetAllTask), username)
 }
 
+// GetTaskDetail mocks base method.
+func (m *MockTaskStatusService) GetTaskDetail(username, taskId string) (*domain.TaskDetail, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaskDetail", username, taskId)
+	ret0, _ := ret[0].(*domain.TaskDetail)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTaskDetail indicates an expected call of GetTaskDetail.
+func (mr *MockTaskStatusServiceMockRecorder) GetTaskDetail(username, taskId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskDetail", reflect.TypeOf((*MockTaskStatusService)(nil).GetTaskDetail), username, taskId)
+}
+
 // GetTaskStatus mocks base method.
 func (m *MockTaskStatusService) GetTaskStatus(username, taskId string) (int, error) {
 	m.ctrl.T.Helper()
@@ -78,6 +138,93 @@ func (mr *MockTaskStatusServiceMockRecorder) GetTaskStatus(username, taskId inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskStatus", reflect.TypeOf((*MockTaskStatusService)(nil).GetTaskStatus), username, taskId)
 }
 
+// GetTermReview mocks base method.
+func (m *MockTaskStatusService) GetTermReview(username, taskId string) (*domain.TermReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTermReview", username, taskId)
+	ret0, _ := ret[0].(*domain.TermReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTermReview indicates an expected call of GetTermReview.
+func (mr *MockTaskStatusServiceMockRecorder) GetTermReview(username, taskId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTermReview", reflect.TypeOf((*MockTaskStatusService)(nil).GetTermReview), username, taskId)
+}
+
+// RetryTask mocks base method.
+func (m *MockTaskStatusService) RetryTask(username, taskId string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryTask", username, taskId)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RetryTask indicates an expected call of RetryTask.
+func (mr *MockTaskStatusServiceMockRecorder) RetryTask(username, taskId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryTask", reflect.TypeOf((*MockTaskStatusService)(nil).RetryTask), username, taskId)
+}
+
+// SaveTermReview mocks base method.
+func (m *MockTaskStatusService) SaveTermReview(taskId string, review *domain.TermReview) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTermReview", taskId, review)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveTermReview indicates an expected call of SaveTermReview.
+func (mr *MockTaskStatusServiceMockRecorder) SaveTermReview(taskId, review interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTermReview", reflect.TypeOf((*MockTaskStatusService)(nil).SaveTermReview), taskId, review)
+}
+
+// StartTaskStage mocks base method.
+func (m *MockTaskStatusService) StartTaskStage(taskId, stage string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartTaskStage", taskId, stage)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StartTaskStage indicates an expected call of StartTaskStage.
+func (mr *MockTaskStatusServiceMockRecorder) StartTaskStage(taskId, stage interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartTaskStage", reflect.TypeOf((*MockTaskStatusService)(nil).StartTaskStage), taskId, stage)
+}
+
+// SubscribeTaskEvents mocks base method.
+func (m *MockTaskStatusService) SubscribeTaskEvents(ctx context.Context, username string) (<-chan domain.TaskEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscribeTaskEvents", ctx, username)
+	ret0, _ := ret[0].(<-chan domain.TaskEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SubscribeTaskEvents indicates an expected call of SubscribeTaskEvents.
+func (mr *MockTaskStatusServiceMockRecorder) SubscribeTaskEvents(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeTaskEvents", reflect.TypeOf((*MockTaskStatusService)(nil).SubscribeTaskEvents), ctx, username)
+}
+
+// UpdateReviewTerms mocks base method.
+func (m *MockTaskStatusService) UpdateReviewTerms(username, taskId string, terms []domain.ReviewTerm) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateReviewTerms", username, taskId, terms)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateReviewTerms indicates an expected call of UpdateReviewTerms.
+func (mr *MockTaskStatusServiceMockRecorder) UpdateReviewTerms(username, taskId, terms interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReviewTerms", reflect.TypeOf((*MockTaskStatusService)(nil).UpdateReviewTerms), username, taskId, terms)
+}
+
 // UpdateTaskDownloadLink mocks base method.
 func (m *MockTaskStatusService) UpdateTaskDownloadLink(taskId, name string) error {
 	m.ctrl.T.Helper()
@@ -92,6 +239,76 @@ func (mr *MockTaskStatusServiceMockRecorder) UpdateTaskDownloadLink(taskId, name
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTaskDownloadLink", reflect.TypeOf((*MockTaskStatusService)(nil).UpdateTaskDownloadLink), taskId, name)
 }
 
+// UpdateTaskError mocks base method.
+func (m *MockTaskStatusService) UpdateTaskError(taskId, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTaskError", taskId, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTaskError indicates an expected call of UpdateTaskError.
+func (mr *MockTaskStatusServiceMockRecorder) UpdateTaskError(taskId, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTaskError", reflect.TypeOf((*MockTaskStatusService)(nil).UpdateTaskError), taskId, reason)
+}
+
+// UpdateTaskGlossaryViolations mocks base method.
+func (m *MockTaskStatusService) UpdateTaskGlossaryViolations(taskId string, violations []domain.GlossaryViolation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTaskGlossaryViolations", taskId, violations)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTaskGlossaryViolations indicates an expected call of UpdateTaskGlossaryViolations.
+func (mr *MockTaskStatusServiceMockRecorder) UpdateTaskGlossaryViolations(taskId, violations interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTaskGlossaryViolations", reflect.TypeOf((*MockTaskStatusService)(nil).UpdateTaskGlossaryViolations), taskId, violations)
+}
+
+// UpdateTaskMemoryHits mocks base method.
+func (m *MockTaskStatusService) UpdateTaskMemoryHits(taskId string, hits, chunks int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTaskMemoryHits", taskId, hits, chunks)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTaskMemoryHits indicates an expected call of UpdateTaskMemoryHits.
+func (mr *MockTaskStatusServiceMockRecorder) UpdateTaskMemoryHits(taskId, hits, chunks interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTaskMemoryHits", reflect.TypeOf((*MockTaskStatusService)(nil).UpdateTaskMemoryHits), taskId, hits, chunks)
+}
+
+// UpdateTaskProgress mocks base method.
+func (m *MockTaskStatusService) UpdateTaskProgress(taskId, unit string, done, total int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTaskProgress", taskId, unit, done, total)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTaskProgress indicates an expected call of UpdateTaskProgress.
+func (mr *MockTaskStatusServiceMockRecorder) UpdateTaskProgress(taskId, unit, done, total interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTaskProgress", reflect.TypeOf((*MockTaskStatusService)(nil).UpdateTaskProgress), taskId, unit, done, total)
+}
+
+// UpdateTaskQuality mocks base method.
+func (m *MockTaskStatusService) UpdateTaskQuality(taskId string, issues []domain.QualityIssue, retriedChunks int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTaskQuality", taskId, issues, retriedChunks)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTaskQuality indicates an expected call of UpdateTaskQuality.
+func (mr *MockTaskStatusServiceMockRecorder) UpdateTaskQuality(taskId, issues, retriedChunks interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTaskQuality", reflect.TypeOf((*MockTaskStatusService)(nil).UpdateTaskQuality), taskId, issues, retriedChunks)
+}
+
 // UpdateTaskStatus mocks base method.
 func (m *MockTaskStatusService) UpdateTaskStatus(username, taskId string, status int) error {
 	m.ctrl.T.Helper()
@@ -105,3 +322,31 @@ func (mr *MockTaskStatusServiceMockRecorder) UpdateTaskStatus(username, taskId,
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTaskStatus", reflect.TypeOf((*MockTaskStatusService)(nil).UpdateTaskStatus), username, taskId, status)
 }
+
+// UpdateTaskTermSheet mocks base method.
+func (m *MockTaskStatusService) UpdateTaskTermSheet(taskId string, terms []domain.GlossaryTerm) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTaskTermSheet", taskId, terms)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTaskTermSheet indicates an expected call of UpdateTaskTermSheet.
+func (mr *MockTaskStatusServiceMockRecorder) UpdateTaskTermSheet(taskId, terms interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTaskTermSheet", reflect.TypeOf((*MockTaskStatusService)(nil).UpdateTaskTermSheet), taskId, terms)
+}
+
+// UpdateTaskUsage mocks base method.
+func (m *MockTaskStatusService) UpdateTaskUsage(taskId string, usage domain.TranslationUsage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTaskUsage", taskId, usage)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTaskUsage indicates an expected call of UpdateTaskUsage.
+func (mr *MockTaskStatusServiceMockRecorder) UpdateTaskUsage(taskId, usage interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTaskUsage", reflect.TypeOf((*MockTaskStatusService)(nil).UpdateTaskUsage), taskId, usage)
+}
@@ -5,6 +5,7 @@
 package service
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -48,17 +49,32 @@ func (mr *MockTranslateServiceMockRecorder) CloseTransGrpcConn() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseTransGrpcConn", reflect.TypeOf((*MockTranslateService)(nil).CloseTransGrpcConn))
 }
 
+// ProposeTerms mocks base method.
+func (m *MockTranslateService) ProposeTerms(ctx context.Context, req *translate.TermRequest) (*translate.TermResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProposeTerms", ctx, req)
+	ret0, _ := ret[0].(*translate.TermResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProposeTerms indicates an expected call of ProposeTerms.
+func (mr *MockTranslateServiceMockRecorder) ProposeTerms(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProposeTerms", reflect.TypeOf((*MockTranslateService)(nil).ProposeTerms), ctx, req)
+}
+
 // TranslateText mocks base method.
-func (m *MockTranslateService) TranslateText(text string) (*translate.TranslateResult, error) {
+func (m *MockTranslateService) TranslateText(ctx context.Context, req *translate.TranslateRequest, progress ProgressFunc) (*translate.TranslateResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "TranslateText", text)
+	ret := m.ctrl.Call(m, "TranslateText", ctx, req, progress)
 	ret0, _ := ret[0].(*translate.TranslateResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // TranslateText indicates an expected call of TranslateText.
-func (mr *MockTranslateServiceMockRecorder) TranslateText(text interface{}) *gomock.Call {
+func (mr *MockTranslateServiceMockRecorder) TranslateText(ctx, req, progress interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TranslateText", reflect.TypeOf((*MockTranslateService)(nil).TranslateText), text)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TranslateText", reflect.TypeOf((*MockTranslateService)(nil).TranslateText), ctx, req, progress)
 }
@@ -7,15 +7,21 @@ import (
 	"github.com/spf13/viper"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"io"
 	"log"
 	"time"
 )
 
+// ProgressFunc receives the units of work of a long running call done so far out of the total units of work, such as
+// the pages recognized by OCR or the chunks translated.
+type ProgressFunc func(done, total int)
+
 // OCRClient is an interface for Optical Character Recognition operations and resource cleanup.
-// ProcessOCR processes the OCR request on given file content with a specified language.
+// ProcessOCR processes the OCR request on given file content with a specified language, until ctx is done, reporting
+// the pages recognized to progress, which may be nil.
 // Close releases any resources used by the OCRClient.
 type OCRClient interface {
-	ProcessOCR(fileContent []byte, lang string) (*pb.StringListResponse, error)
+	ProcessOCR(ctx context.Context, fileContent []byte, lang string, progress ProgressFunc) (*pb.StringListResponse, error)
 	Close() error
 }
 
@@ -56,16 +62,38 @@ func (s *OCRService) getGRPCConn() error {
 }
 
 // ProcessOCR processes the given PDF file content using OCR and specified language, returning a structured response.
-func (s *OCRService) ProcessOCR(fileContent []byte, lang string) (*pb.StringListResponse, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+// The progress streamed by the OCR service is passed to progress as it arrives, unless progress is nil.
+// The call is aborted once ctx is done, such as when the task is cancelled.
+func (s *OCRService) ProcessOCR(
+	ctx context.Context, fileContent []byte, lang string, progress ProgressFunc,
+) (*pb.StringListResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
 	defer cancel()
 	client := s.grpcClient
-	return client.ProcessPDF(
+	stream, err := client.TrackPDF(
 		ctx, &pb.PDFRequest{
 			PdfData:  fileContent,
 			Language: lang,
 		},
 	)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		update, err := stream.Recv()
+		if err == io.EOF {
+			return nil, fmt.Errorf("OCR stream ended without a result")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if progress != nil {
+			progress(int(update.PagesDone), int(update.PagesTotal))
+		}
+		if update.Result != nil {
+			return update.Result, nil
+		}
+	}
 }
 
 // Close releases the underlying gRPC connection if it is active and returns any error encountered during closure.
@@ -1,19 +1,28 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/spf13/viper"
+	"io"
+	"mime"
 	"os"
+	"path/filepath"
 	"time"
 )
 
+// S3StorageService defines methods for storing files in S3 and sharing them.
+// PutObject, GetObject and DeleteObject manage objects held in memory, such as the inputs of queued tasks.
 type S3StorageService interface {
 	UploadFileToS3(bucketName, objectKey, filePath string, expirationDays int) error
 	GeneratePresignedURL(bucketName, objectKey string, expiration time.Duration) (string, error)
+	PutObject(bucketName, objectKey string, content []byte, expirationDays int) error
+	GetObject(bucketName, objectKey string) ([]byte, error)
+	DeleteObject(bucketName, objectKey string) error
 }
 
 type S3StorageServiceImpl struct {
@@ -36,6 +45,7 @@ func NewS3StorageService() (*S3StorageServiceImpl, error) {
 }
 
 // UploadFileToS3 uploads a local file to an S3 bucket at the specified key and sets an expiration metadata value.
+// The content type is derived from the file extension so that browsers display HTML and markdown files correctly.
 func (s *S3StorageServiceImpl) UploadFileToS3(bucketName, objectKey, filePath string, expirationDays int) error {
 	//format expiration
 	expiration := time.Now().AddDate(0, 0, expirationDays).Format(time.RFC1123)
@@ -47,17 +57,20 @@ func (s *S3StorageServiceImpl) UploadFileToS3(bucketName, objectKey, filePath st
 	}
 	defer file.Close()
 
-	// upload
-	_, err = s.client.PutObject(
-		context.Background(), &s3.PutObjectInput{
-			Bucket: &bucketName,
-			Key:    &objectKey,
-			Body:   file,
-			Metadata: map[string]string{
-				"Expires": expiration,
-			},
+	input := &s3.PutObjectInput{
+		Bucket: &bucketName,
+		Key:    &objectKey,
+		Body:   file,
+		Metadata: map[string]string{
+			"Expires": expiration,
 		},
-	)
+	}
+	if contentType := contentTypeByExtension(filepath.Ext(filePath)); contentType != "" {
+		input.ContentType = &contentType
+	}
+
+	// upload
+	_, err = s.client.PutObject(context.Background(), input)
 	if err != nil {
 		return fmt.Errorf("failed to uplaod file %w", err)
 	}
@@ -66,6 +79,14 @@ func (s *S3StorageServiceImpl) UploadFileToS3(bucketName, objectKey, filePath st
 	return nil
 }
 
+// contentTypeByExtension returns the MIME type of a file extension, or an empty string if it is unknown.
+func contentTypeByExtension(ext string) string {
+	if ext == ".md" {
+		return "text/markdown; charset=utf-8"
+	}
+	return mime.TypeByExtension(ext)
+}
+
 // GeneratePresignedURL generates a presigned URL for accessing an object in an S3 bucket with a specified expiration time.
 // It requires the bucket name, object key, and expiration duration as inputs and returns the presigned URL or an error.
 func (s *S3StorageServiceImpl) GeneratePresignedURL(bucketName, objectKey string, expiration time.Duration) (
@@ -87,3 +108,43 @@ func (s *S3StorageServiceImpl) GeneratePresignedURL(bucketName, objectKey string
 
 	return presignedURL.URL, nil
 }
+
+// PutObject uploads content to an S3 bucket at the specified key and sets an expiration metadata value.
+func (s *S3StorageServiceImpl) PutObject(bucketName, objectKey string, content []byte, expirationDays int) error {
+	expiration := time.Now().AddDate(0, 0, expirationDays).Format(time.RFC1123)
+	_, err := s.client.PutObject(
+		context.Background(), &s3.PutObjectInput{
+			Bucket:   &bucketName,
+			Key:      &objectKey,
+			Body:     bytes.NewReader(content),
+			Metadata: map[string]string{"Expires": expiration},
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to upload object %w", err)
+	}
+	return nil
+}
+
+// GetObject downloads the content of the object at the specified key of an S3 bucket.
+func (s *S3StorageServiceImpl) GetObject(bucketName, objectKey string) ([]byte, error) {
+	output, err := s.client.GetObject(context.Background(), &s3.GetObjectInput{Bucket: &bucketName, Key: &objectKey})
+	if err != nil {
+		return nil, fmt.Errorf("failed to download object %w", err)
+	}
+	defer output.Body.Close()
+	content, err := io.ReadAll(output.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object %w", err)
+	}
+	return content, nil
+}
+
+// DeleteObject removes the object at the specified key from an S3 bucket.
+func (s *S3StorageServiceImpl) DeleteObject(bucketName, objectKey string) error {
+	_, err := s.client.DeleteObject(context.Background(), &s3.DeleteObjectInput{Bucket: &bucketName, Key: &objectKey})
+	if err != nil {
+		return fmt.Errorf("failed to delete object %w", err)
+	}
+	return nil
+}
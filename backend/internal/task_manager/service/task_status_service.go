@@ -2,10 +2,13 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"github.com/google/uuid"
+	"github.com/oOSomnus/transflate/internal/task_manager/domain"
 	"github.com/oOSomnus/transflate/internal/task_manager/repository"
 	"log"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -17,17 +20,37 @@ type TaskStatusService interface {
 	CreateNewTask(username string, filename string) (string, error)
 	GetAllTask(username string) (map[string]map[string]interface{}, error)
 	UpdateTaskDownloadLink(taskId string, name string) error
+	UpdateTaskGlossaryViolations(taskId string, violations []domain.GlossaryViolation) error
+	UpdateTaskMemoryHits(taskId string, hits int, chunks int) error
+	UpdateTaskUsage(taskId string, usage domain.TranslationUsage) error
+	UpdateTaskTermSheet(taskId string, terms []domain.GlossaryTerm) error
+	UpdateTaskQuality(taskId string, issues []domain.QualityIssue, retriedChunks int) error
+	SaveTermReview(taskId string, review *domain.TermReview) error
+	GetTermReview(username string, taskId string) (*domain.TermReview, error)
+	UpdateReviewTerms(username string, taskId string, terms []domain.ReviewTerm) error
+	ConfirmTermReview(username string, taskId string) (*domain.TermReview, error)
+	CancelTask(username string, taskId string) error
+	RetryTask(username string, taskId string) (string, error)
+	StartTaskStage(taskId string, stage string) error
+	EndTaskStage(taskId string, stage string) error
+	UpdateTaskError(taskId string, reason string) error
+	UpdateTaskProgress(taskId string, unit string, done int, total int) error
+	GetTaskDetail(username string, taskId string) (*domain.TaskDetail, error)
+	SubscribeTaskEvents(ctx context.Context, username string) (<-chan domain.TaskEvent, error)
 }
 
 // TaskStatusServiceImpl provides methods to manage task states via a TaskRepository.
 // It includes functionalities for creating, updating, retrieving, and fetching tasks.
+// Every change of the lifecycle of a task is published on the TaskEventBus, so that it is pushed to its user.
 type TaskStatusServiceImpl struct {
-	tr repository.TaskRepository
+	tr     repository.TaskRepository
+	events repository.TaskEventBus
 }
 
-// NewTaskStatusService initializes and returns a new instance of TaskStatusServiceImpl with the provided TaskRepository.
-func NewTaskStatusService(tr repository.TaskRepository) *TaskStatusServiceImpl {
-	return &TaskStatusServiceImpl{tr: tr}
+// NewTaskStatusService initializes and returns a new instance of TaskStatusServiceImpl with the provided TaskRepository
+// and TaskEventBus.
+func NewTaskStatusService(tr repository.TaskRepository, events repository.TaskEventBus) *TaskStatusServiceImpl {
+	return &TaskStatusServiceImpl{tr: tr, events: events}
 }
 
 // InvalidTaskId indicates that the provided Task ID is invalid.
@@ -40,19 +63,49 @@ const (
 )
 
 // TaskReceived represents the state where a task has been received and not yet processed.
-// ProcessingImages indicates that the task is currently processing images.
-// ProcesingText signifies that the task is processing textual data.
+// Translating indicates that the text of the document is being translated.
+// Uploading indicates that the translated document is being rendered and uploaded.
 // Done denotes that the task has been completed successfully.
+// WaitingForReview indicates that the task waits for the user to review the terms proposed for the document.
+// Cancelled denotes that the user cancelled the task before it was done.
+// RecognizingText indicates that the text of the document is being recognized by OCR.
 // Error represents the state where an error occurred in task processing.
 const (
-	TaskReceived = 0
-	Translating  = 1
-	Uploading    = 2
-	Done         = 3
-	Error        = 9
+	TaskReceived     = 0
+	Translating      = 1
+	Uploading        = 2
+	Done             = 3
+	WaitingForReview = 4
+	Cancelled        = 5
+	RecognizingText  = 6
+	Error            = 9
+)
+
+// progressUnits maps the statuses of the stages whose progress is counted to the unit it is counted in.
+var progressUnits = map[int]string{RecognizingText: domain.ProgressPages, Translating: domain.ProgressChunks}
+
+// ErrNotWaitingForReview indicates that the terms of a task can only be reviewed while it waits for the review.
+// ErrTaskCancelled indicates that the status of a cancelled task can no longer change.
+// ErrTaskFinished indicates that the status of a task that is done or failed can no longer change, and that a task
+// cannot be cancelled once it is delivered, done, failed or cancelled.
+// ErrTaskNotFailed indicates that only failed tasks can be retried.
+var (
+	ErrNotWaitingForReview = errors.New("task is not waiting for term review")
+	ErrTaskCancelled       = errors.New("task has been cancelled")
+	ErrTaskFinished        = errors.New("task has already finished")
+	ErrTaskNotFailed       = errors.New("task has not failed")
+)
+
+// sourceTextField, optionsField and reviewTermsField are the task fields holding the state of a term review.
+const (
+	sourceTextField  = "source_text"
+	optionsField     = "options"
+	reviewTermsField = "review_terms"
 )
 
 // UpdateTaskStatus updates the status of the specified task if the username matches and returns an error if any issue occurs.
+// A task that is done, failed or cancelled keeps its status, for which ErrTaskFinished, or ErrTaskCancelled if it has
+// been cancelled, is returned. Failed tasks are only moved on by RetryTask.
 func (tss *TaskStatusServiceImpl) UpdateTaskStatus(username string, taskID string, status int) error {
 	// taskId format: username-UUID
 	idUsername, taskUUID, err := parseTaskID(taskID)
@@ -66,11 +119,18 @@ func (tss *TaskStatusServiceImpl) UpdateTaskStatus(username string, taskID strin
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	err = tss.tr.SetTaskState(ctx, idUsername, taskUUID, status, "", 12*time.Hour)
+	err = tss.tr.UpdateTaskStatusUnless(ctx, idUsername, taskUUID, status, 12*time.Hour, Done, Error, Cancelled)
+	if errors.Is(err, repository.ErrUnexpectedTaskStatus) {
+		if current, _, err := tss.tr.GetTaskState(ctx, idUsername, taskUUID); err == nil && current == Cancelled {
+			return ErrTaskCancelled
+		}
+		return ErrTaskFinished
+	}
 	if err != nil {
 		log.Printf("Error handling update: %v", err)
 		return errors.New(ErrorAccessingData)
 	}
+	tss.publishStatus(idUsername, taskUUID, status)
 	return nil
 }
 
@@ -107,6 +167,13 @@ func (tss *TaskStatusServiceImpl) CreateNewTask(username string, filename string
 		log.Printf("Error handling update: %v", err)
 		return "", errors.New(ErrorAccessingData)
 	}
+	status := TaskReceived
+	tss.publish(
+		username, domain.TaskEvent{
+			Type: domain.TaskEventCreated, TaskID: newId.String(), Filename: filename, Status: &status,
+			Time: time.Now(),
+		},
+	)
 	return taskId, nil
 }
 
@@ -120,6 +187,8 @@ func parseTaskID(taskID string) (string, string, error) {
 }
 
 // GetAllTask fetches all tasks along with their status for the specified username and returns them as a map.
+// Tasks recognizing or translating their text also report the estimated seconds left of the running stage as
+// eta_seconds, once it can be estimated from their progress and the start of the stage listed along with them.
 func (tss *TaskStatusServiceImpl) GetAllTask(username string) (map[string]map[string]interface{}, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -128,6 +197,24 @@ func (tss *TaskStatusServiceImpl) GetAllTask(username string) (map[string]map[st
 		log.Printf("Error fetching all tasks: %v", err)
 		return nil, errors.New(ErrorAccessingData)
 	}
+	for _, task := range allTasks {
+		status, _ := task["status"].(int)
+		unit, ok := progressUnits[status]
+		if !ok {
+			continue
+		}
+		total, ok := task[progressTotalField(unit)].(int)
+		if !ok {
+			continue
+		}
+		done, _ := task[progressDoneField(unit)].(int)
+		progress := &domain.TaskProgress{Unit: unit, Done: done, Total: total}
+		started, _ := task[stageStartedField(domain.ProgressStages[unit])].(string)
+		estimateProgress(progress, started)
+		if progress.ETASeconds != nil {
+			task["eta_seconds"] = *progress.ETASeconds
+		}
+	}
 	return allTasks, nil
 }
 
@@ -144,5 +231,589 @@ func (tss *TaskStatusServiceImpl) UpdateTaskDownloadLink(taskID string, link str
 		log.Printf("Error updating task link: %v", err)
 		return errors.New(ErrorAccessingData)
 	}
+	tss.publish(
+		idUsername, domain.TaskEvent{Type: domain.TaskEventLink, TaskID: taskUUID, Link: link, Time: time.Now()},
+	)
+	return nil
+}
+
+// UpdateTaskGlossaryViolations stores the glossary violations found while translating the specified task so that they
+// are reported along with the task result. Returns an error if failed.
+func (tss *TaskStatusServiceImpl) UpdateTaskGlossaryViolations(
+	taskID string, violations []domain.GlossaryViolation,
+) error {
+	idUsername, taskUUID, err := parseTaskID(taskID)
+	if err != nil {
+		log.Printf("error parsing task id: %v", err)
+		return err
+	}
+	encoded, err := json.Marshal(violations)
+	if err != nil {
+		log.Printf("Error encoding glossary violations: %v", err)
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	fields := map[string]interface{}{"glossary_violations": string(encoded)}
+	if err := tss.tr.UpdateTaskFields(ctx, idUsername, taskUUID, fields); err != nil {
+		log.Printf("Error updating task glossary violations: %v", err)
+		return errors.New(ErrorAccessingData)
+	}
+	return nil
+}
+
+// UpdateTaskMemoryHits stores how many of the chunks of the specified task were reused from the translation memory, so
+// that the hit rate is reported along with the task result. Returns an error if failed.
+func (tss *TaskStatusServiceImpl) UpdateTaskMemoryHits(taskID string, hits int, chunks int) error {
+	idUsername, taskUUID, err := parseTaskID(taskID)
+	if err != nil {
+		log.Printf("error parsing task id: %v", err)
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	fields := map[string]interface{}{"memory_hits": hits, "chunk_count": chunks}
+	if err := tss.tr.UpdateTaskFields(ctx, idUsername, taskUUID, fields); err != nil {
+		log.Printf("Error updating task memory hits: %v", err)
+		return errors.New(ErrorAccessingData)
+	}
+	return nil
+}
+
+// UpdateTaskUsage stores the model, elapsed time, token usage and backends of the translation of the specified task, so
+// that its cost and the backend that translated every chunk are reported along with the task result. Returns an error
+// if failed.
+func (tss *TaskStatusServiceImpl) UpdateTaskUsage(taskID string, usage domain.TranslationUsage) error {
+	idUsername, taskUUID, err := parseTaskID(taskID)
+	if err != nil {
+		log.Printf("error parsing task id: %v", err)
+		return err
+	}
+	encoded, err := json.Marshal(usage)
+	if err != nil {
+		log.Printf("Error encoding task usage: %v", err)
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	fields := map[string]interface{}{domain.TaskFieldUsage: string(encoded)}
+	if err := tss.tr.UpdateTaskFields(ctx, idUsername, taskUUID, fields); err != nil {
+		log.Printf("Error updating task usage: %v", err)
+		return errors.New(ErrorAccessingData)
+	}
+	return nil
+}
+
+// UpdateTaskTermSheet stores the term sheet extracted in consistency mode for the specified task, so that the
+// translations chosen for its key terms are reported along with the task result. Returns an error if failed.
+func (tss *TaskStatusServiceImpl) UpdateTaskTermSheet(taskID string, terms []domain.GlossaryTerm) error {
+	idUsername, taskUUID, err := parseTaskID(taskID)
+	if err != nil {
+		log.Printf("error parsing task id: %v", err)
+		return err
+	}
+	encoded, err := json.Marshal(terms)
+	if err != nil {
+		log.Printf("Error encoding task term sheet: %v", err)
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	fields := map[string]interface{}{"term_sheet": string(encoded)}
+	if err := tss.tr.UpdateTaskFields(ctx, idUsername, taskUUID, fields); err != nil {
+		log.Printf("Error updating task term sheet: %v", err)
+		return errors.New(ErrorAccessingData)
+	}
+	return nil
+}
+
+// UpdateTaskQuality stores the quality issues left in the translation of the specified task and the number of chunks
+// that were retranslated, so that doubtful passages are reported along with the task result. Returns an error if failed.
+func (tss *TaskStatusServiceImpl) UpdateTaskQuality(
+	taskID string, issues []domain.QualityIssue, retriedChunks int,
+) error {
+	idUsername, taskUUID, err := parseTaskID(taskID)
+	if err != nil {
+		log.Printf("error parsing task id: %v", err)
+		return err
+	}
+	if issues == nil {
+		// Chunks may have been retried successfully, which is reported as an empty list rather than null
+		issues = []domain.QualityIssue{}
+	}
+	encoded, err := json.Marshal(issues)
+	if err != nil {
+		log.Printf("Error encoding task quality issues: %v", err)
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	fields := map[string]interface{}{"quality_issues": string(encoded), "retried_chunks": retriedChunks}
+	if err := tss.tr.UpdateTaskFields(ctx, idUsername, taskUUID, fields); err != nil {
+		log.Printf("Error updating task quality issues: %v", err)
+		return errors.New(ErrorAccessingData)
+	}
+	return nil
+}
+
+// SaveTermReview stores the OCR text, options and proposed terms of the specified task, so that the task can be
+// resumed once the user has reviewed the terms. Returns an error if failed.
+func (tss *TaskStatusServiceImpl) SaveTermReview(taskID string, review *domain.TermReview) error {
+	idUsername, taskUUID, err := parseTaskID(taskID)
+	if err != nil {
+		log.Printf("error parsing task id: %v", err)
+		return err
+	}
+	options, err := json.Marshal(review.Options)
+	if err != nil {
+		log.Printf("Error encoding task options: %v", err)
+		return err
+	}
+	terms, err := json.Marshal(review.Terms)
+	if err != nil {
+		log.Printf("Error encoding review terms: %v", err)
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	fields := map[string]interface{}{
+		sourceTextField:  review.Text,
+		optionsField:     string(options),
+		reviewTermsField: string(terms),
+	}
+	if err := tss.tr.UpdateTaskFields(ctx, idUsername, taskUUID, fields); err != nil {
+		log.Printf("Error saving term review: %v", err)
+		return errors.New(ErrorAccessingData)
+	}
+	return nil
+}
+
+// GetTermReview retrieves the term review of the specified task if the username matches.
+// Returns repository.ErrTaskNotFound if the task does not exist and ErrNotWaitingForReview if it is not waiting for
+// the review of its terms.
+func (tss *TaskStatusServiceImpl) GetTermReview(username string, taskID string) (*domain.TermReview, error) {
+	idUsername, taskUUID, err := tss.authorizeTask(username, taskID)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	status, _, err := tss.tr.GetTaskState(ctx, idUsername, taskUUID)
+	if err != nil {
+		return nil, taskAccessError(err)
+	}
+	if status != WaitingForReview {
+		return nil, ErrNotWaitingForReview
+	}
+	return tss.loadTermReview(ctx, idUsername, taskUUID)
+}
+
+// UpdateReviewTerms replaces the terms under review of the specified task if the username matches and the task is
+// waiting for the review. Returns the same errors as GetTermReview.
+func (tss *TaskStatusServiceImpl) UpdateReviewTerms(username string, taskID string, terms []domain.ReviewTerm) error {
+	idUsername, taskUUID, err := tss.authorizeTask(username, taskID)
+	if err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(terms)
+	if err != nil {
+		log.Printf("Error encoding review terms: %v", err)
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	status, _, err := tss.tr.GetTaskState(ctx, idUsername, taskUUID)
+	if err != nil {
+		return taskAccessError(err)
+	}
+	if status != WaitingForReview {
+		return ErrNotWaitingForReview
+	}
+	fields := map[string]interface{}{reviewTermsField: string(encoded)}
+	if err := tss.tr.UpdateTaskFields(ctx, idUsername, taskUUID, fields); err != nil {
+		return taskAccessError(err)
+	}
+	return nil
+}
+
+// ConfirmTermReview ends the term review of the specified task if the username matches, moving the task back to the
+// Translating status, and returns the reviewed state to resume the task with. The status changes atomically, so a
+// review can only be confirmed once. Returns the same errors as GetTermReview.
+func (tss *TaskStatusServiceImpl) ConfirmTermReview(username string, taskID string) (*domain.TermReview, error) {
+	idUsername, taskUUID, err := tss.authorizeTask(username, taskID)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := tss.tr.TransitionTaskStatus(ctx, idUsername, taskUUID, WaitingForReview, Translating); err != nil {
+		if errors.Is(err, repository.ErrUnexpectedTaskStatus) {
+			return nil, ErrNotWaitingForReview
+		}
+		return nil, taskAccessError(err)
+	}
+	tss.publishStatus(idUsername, taskUUID, Translating)
+	now := time.Now()
+	reviewEnded := map[string]interface{}{stageEndedField(domain.StageReview): now.Format(time.RFC3339Nano)}
+	if err := tss.tr.UpdateTaskFields(ctx, idUsername, taskUUID, reviewEnded); err != nil {
+		log.Printf("Error updating task stage: %v", err)
+	} else {
+		tss.publishStage(idUsername, taskUUID, domain.TaskEventStageEnded, domain.StageReview, now)
+	}
+	review, err := tss.loadTermReview(ctx, idUsername, taskUUID)
+	if err != nil {
+		return nil, err
+	}
+	// The OCR text is no longer needed once the review is confirmed
+	if err := tss.tr.DeleteTaskFields(ctx, idUsername, taskUUID, sourceTextField); err != nil {
+		log.Printf("Error removing task source text: %v", err)
+	}
+	return review, nil
+}
+
+// CancelTask moves the specified task to the Cancelled status if the username matches and it is still in progress,
+// queued or waiting for its term review. The status changes atomically, so the workers processing the task never
+// complete it once it is cancelled. A task being delivered in the Uploading status can no longer be cancelled, since
+// it is charged before it is done. Returns repository.ErrTaskNotFound if the task does not exist and ErrTaskFinished
+// if it is already being delivered, done, failed or cancelled.
+func (tss *TaskStatusServiceImpl) CancelTask(username string, taskID string) error {
+	idUsername, taskUUID, err := tss.authorizeTask(username, taskID)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err = tss.tr.UpdateTaskStatusUnless(
+		ctx, idUsername, taskUUID, Cancelled, 12*time.Hour, Uploading, Done, Error, Cancelled,
+	)
+	if errors.Is(err, repository.ErrUnexpectedTaskStatus) {
+		return ErrTaskFinished
+	}
+	if err != nil {
+		return taskAccessError(err)
+	}
+	tss.publishStatus(idUsername, taskUUID, Cancelled)
+	return nil
+}
+
+// RetryTask moves the specified failed task back to the TaskReceived status if the username matches, so that it can be
+// queued again, and clears the reason it failed, which is returned so that it can be restored if the task cannot be
+// queued. The status changes atomically, so a failed task is only retried once.
+// Returns repository.ErrTaskNotFound if the task does not exist and ErrTaskNotFailed if it is not in the Error status.
+func (tss *TaskStatusServiceImpl) RetryTask(username string, taskID string) (string, error) {
+	idUsername, taskUUID, err := tss.authorizeTask(username, taskID)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := tss.tr.TransitionTaskStatus(ctx, idUsername, taskUUID, Error, TaskReceived); err != nil {
+		if errors.Is(err, repository.ErrUnexpectedTaskStatus) {
+			return "", ErrTaskNotFailed
+		}
+		return "", taskAccessError(err)
+	}
+	fields, err := tss.tr.GetTaskFields(ctx, idUsername, taskUUID, domain.TaskFieldError)
+	if err != nil {
+		log.Printf("Error reading task error: %v", err)
+	}
+	if err := tss.tr.DeleteTaskFields(ctx, idUsername, taskUUID, domain.TaskFieldError); err != nil {
+		log.Printf("Error removing task error: %v", err)
+	}
+	tss.publishStatus(idUsername, taskUUID, TaskReceived)
+	return fields[domain.TaskFieldError], nil
+}
+
+// StartTaskStage records that a stage of the specified task starts now. The end and progress of a previous run of the
+// stage, such as a run that failed before the task was retried, are cleared. Returns an error if failed.
+func (tss *TaskStatusServiceImpl) StartTaskStage(taskID string, stage string) error {
+	idUsername, taskUUID, err := parseTaskID(taskID)
+	if err != nil {
+		log.Printf("error parsing task id: %v", err)
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	now := time.Now()
+	fields := map[string]interface{}{
+		stageStartedField(stage): now.Format(time.RFC3339Nano),
+		stageEndedField(stage):   "",
+	}
+	if err := tss.tr.UpdateTaskFields(ctx, idUsername, taskUUID, fields); err != nil {
+		log.Printf("Error updating task stage: %v", err)
+		return errors.New(ErrorAccessingData)
+	}
+	for unit, progressStage := range domain.ProgressStages {
+		if progressStage != stage {
+			continue
+		}
+		// The progress of a previous run would be taken for the progress of this run
+		err := tss.tr.DeleteTaskFields(ctx, idUsername, taskUUID, progressDoneField(unit), progressTotalField(unit))
+		if err != nil {
+			log.Printf("Error removing task progress: %v", err)
+		}
+	}
+	tss.publishStage(idUsername, taskUUID, domain.TaskEventStageStarted, stage, now)
 	return nil
 }
+
+// EndTaskStage records that a stage of the specified task ends now. Returns an error if failed.
+func (tss *TaskStatusServiceImpl) EndTaskStage(taskID string, stage string) error {
+	idUsername, taskUUID, err := parseTaskID(taskID)
+	if err != nil {
+		log.Printf("error parsing task id: %v", err)
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	now := time.Now()
+	fields := map[string]interface{}{stageEndedField(stage): now.Format(time.RFC3339Nano)}
+	if err := tss.tr.UpdateTaskFields(ctx, idUsername, taskUUID, fields); err != nil {
+		log.Printf("Error updating task stage: %v", err)
+		return errors.New(ErrorAccessingData)
+	}
+	tss.publishStage(idUsername, taskUUID, domain.TaskEventStageEnded, stage, now)
+	return nil
+}
+
+// UpdateTaskError stores the human-readable reason the specified task failed, so that it is reported along with the
+// task. Returns an error if failed.
+func (tss *TaskStatusServiceImpl) UpdateTaskError(taskID string, reason string) error {
+	idUsername, taskUUID, err := parseTaskID(taskID)
+	if err != nil {
+		log.Printf("error parsing task id: %v", err)
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	fields := map[string]interface{}{domain.TaskFieldError: reason}
+	if err := tss.tr.UpdateTaskFields(ctx, idUsername, taskUUID, fields); err != nil {
+		log.Printf("Error updating task error: %v", err)
+		return errors.New(ErrorAccessingData)
+	}
+	tss.publish(
+		idUsername, domain.TaskEvent{Type: domain.TaskEventFailed, TaskID: taskUUID, Error: reason, Time: time.Now()},
+	)
+	return nil
+}
+
+// UpdateTaskProgress records that done out of total units of the specified task are complete, such as the pages of
+// its document recognized by OCR or the chunks of its text translated, and publishes the progress along with the
+// estimated seconds left of the stage. Returns an error if the unit is unknown or the update failed.
+func (tss *TaskStatusServiceImpl) UpdateTaskProgress(taskID string, unit string, done int, total int) error {
+	idUsername, taskUUID, err := parseTaskID(taskID)
+	if err != nil {
+		log.Printf("error parsing task id: %v", err)
+		return err
+	}
+	stage, ok := domain.ProgressStages[unit]
+	if !ok {
+		log.Printf("Unknown progress unit: %s", unit)
+		return errors.New(ErrorAccessingData)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	fields := map[string]interface{}{progressDoneField(unit): done, progressTotalField(unit): total}
+	if err := tss.tr.UpdateTaskFields(ctx, idUsername, taskUUID, fields); err != nil {
+		log.Printf("Error updating task progress: %v", err)
+		return errors.New(ErrorAccessingData)
+	}
+	progress := &domain.TaskProgress{Unit: unit, Done: done, Total: total}
+	started, err := tss.tr.GetTaskFields(ctx, idUsername, taskUUID, stageStartedField(stage))
+	if err != nil {
+		log.Printf("Error reading stage start of task %s: %v", taskID, err)
+	} else {
+		estimateProgress(progress, started[stageStartedField(stage)])
+	}
+	tss.publish(
+		idUsername, domain.TaskEvent{
+			Type: domain.TaskEventProgress, TaskID: taskUUID, Stage: stage, Progress: progress, Time: time.Now(),
+		},
+	)
+	return nil
+}
+
+// GetTaskDetail retrieves the full state of the specified task if the username matches: its status, file, download
+// link, OCR language, page counts, the start and end of the stages run so far, the progress of the running stage and
+// the reason it failed, if it did.
+// Returns repository.ErrTaskNotFound if the task does not exist.
+func (tss *TaskStatusServiceImpl) GetTaskDetail(username string, taskID string) (*domain.TaskDetail, error) {
+	idUsername, taskUUID, err := tss.authorizeTask(username, taskID)
+	if err != nil {
+		return nil, err
+	}
+	fields := []string{
+		"status", "filename", "link", "created_at", domain.TaskFieldJob, domain.TaskFieldPageCount,
+		domain.TaskFieldPagesBilled, domain.TaskFieldPagesRefunded, domain.TaskFieldError, domain.TaskFieldUsage,
+	}
+	for _, stage := range domain.TaskStages {
+		fields = append(fields, stageStartedField(stage), stageEndedField(stage))
+	}
+	for unit := range domain.ProgressStages {
+		fields = append(fields, progressDoneField(unit), progressTotalField(unit))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	values, err := tss.tr.GetTaskFields(ctx, idUsername, taskUUID, fields...)
+	if err != nil {
+		return nil, taskAccessError(err)
+	}
+
+	detail := &domain.TaskDetail{
+		ID:        taskUUID,
+		Filename:  values["filename"],
+		Link:      values["link"],
+		CreatedAt: values["created_at"],
+		Stages:    []domain.TaskStage{},
+		Error:     values[domain.TaskFieldError],
+	}
+	// Missing counters have not been written yet and count as zero
+	detail.Status, _ = strconv.Atoi(values["status"])
+	detail.Pages, _ = strconv.Atoi(values[domain.TaskFieldPageCount])
+	detail.PagesBilled, _ = strconv.Atoi(values[domain.TaskFieldPagesBilled])
+	detail.PagesRefunded, _ = strconv.Atoi(values[domain.TaskFieldPagesRefunded])
+	if encoded, ok := values[domain.TaskFieldJob]; ok {
+		var job domain.TaskJob
+		if err := json.Unmarshal([]byte(encoded), &job); err != nil {
+			log.Printf("Error decoding job of task %s: %v", taskID, err)
+		} else {
+			detail.Lang = job.Options.Lang
+		}
+	}
+	if encoded, ok := values[domain.TaskFieldUsage]; ok {
+		var usage domain.TranslationUsage
+		if err := json.Unmarshal([]byte(encoded), &usage); err != nil {
+			log.Printf("Error decoding usage of task %s: %v", taskID, err)
+		} else {
+			detail.Usage = &usage
+		}
+	}
+	for _, stage := range domain.TaskStages {
+		startedAt, err := time.Parse(time.RFC3339Nano, values[stageStartedField(stage)])
+		if err != nil {
+			// The stage has not started
+			continue
+		}
+		taskStage := domain.TaskStage{Name: stage, StartedAt: startedAt}
+		if endedAt, err := time.Parse(time.RFC3339Nano, values[stageEndedField(stage)]); err == nil {
+			taskStage.EndedAt = &endedAt
+		}
+		detail.Stages = append(detail.Stages, taskStage)
+	}
+	if unit, ok := progressUnits[detail.Status]; ok {
+		if total, err := strconv.Atoi(values[progressTotalField(unit)]); err == nil {
+			detail.Progress = &domain.TaskProgress{Unit: unit, Total: total}
+			detail.Progress.Done, _ = strconv.Atoi(values[progressDoneField(unit)])
+			estimateProgress(detail.Progress, values[stageStartedField(domain.ProgressStages[unit])])
+		}
+	}
+	return detail, nil
+}
+
+// SubscribeTaskEvents delivers the events of the tasks of the user from now on, until ctx is done.
+func (tss *TaskStatusServiceImpl) SubscribeTaskEvents(
+	ctx context.Context, username string,
+) (<-chan domain.TaskEvent, error) {
+	events, err := tss.events.Subscribe(ctx, username)
+	if err != nil {
+		log.Printf("Error subscribing to task events: %v", err)
+		return nil, errors.New(ErrorAccessingData)
+	}
+	return events, nil
+}
+
+// publish pushes an event of a task of the user to its subscribers. Events only inform the subscribers, so a failure
+// to publish one is logged without failing the change it reports.
+func (tss *TaskStatusServiceImpl) publish(username string, event domain.TaskEvent) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := tss.events.Publish(ctx, username, event); err != nil {
+		log.Printf("Error publishing %s event of task %s: %v", event.Type, event.TaskID, err)
+	}
+}
+
+// publishStatus publishes the new status of a task.
+func (tss *TaskStatusServiceImpl) publishStatus(username string, taskUUID string, status int) {
+	tss.publish(
+		username, domain.TaskEvent{Type: domain.TaskEventStatus, TaskID: taskUUID, Status: &status, Time: time.Now()},
+	)
+}
+
+// publishStage publishes the start or end of a stage of a task at the given time.
+func (tss *TaskStatusServiceImpl) publishStage(
+	username string, taskUUID string, eventType string, stage string, at time.Time,
+) {
+	tss.publish(username, domain.TaskEvent{Type: eventType, TaskID: taskUUID, Stage: stage, Time: at})
+}
+
+// stageStartedField returns the task field holding the start time of a stage.
+func stageStartedField(stage string) string {
+	return stage + "_started_at"
+}
+
+// stageEndedField returns the task field holding the end time of a stage.
+func stageEndedField(stage string) string {
+	return stage + "_ended_at"
+}
+
+// progressDoneField returns the task field holding the units of progress done.
+func progressDoneField(unit string) string {
+	return unit + "_done"
+}
+
+// progressTotalField returns the task field holding the total units of progress.
+func progressTotalField(unit string) string {
+	return unit + "_total"
+}
+
+// estimateProgress estimates the seconds left of the progress from the recorded start of its stage, and leaves the
+// estimate out if the stage has not recorded its start.
+func estimateProgress(progress *domain.TaskProgress, startedAt string) {
+	if started, err := time.Parse(time.RFC3339Nano, startedAt); err == nil {
+		progress.EstimateSeconds(started, time.Now())
+	}
+}
+
+// loadTermReview reads and decodes the stored term review of a task.
+func (tss *TaskStatusServiceImpl) loadTermReview(
+	ctx context.Context, username, taskUUID string,
+) (*domain.TermReview, error) {
+	fields, err := tss.tr.GetTaskFields(ctx, username, taskUUID, sourceTextField, optionsField, reviewTermsField)
+	if err != nil {
+		return nil, taskAccessError(err)
+	}
+	review := &domain.TermReview{Text: fields[sourceTextField]}
+	if err := json.Unmarshal([]byte(fields[optionsField]), &review.Options); err != nil {
+		log.Printf("Error decoding task options: %v", err)
+		return nil, errors.New(ErrorAccessingData)
+	}
+	if err := json.Unmarshal([]byte(fields[reviewTermsField]), &review.Terms); err != nil {
+		log.Printf("Error decoding review terms: %v", err)
+		return nil, errors.New(ErrorAccessingData)
+	}
+	return review, nil
+}
+
+// authorizeTask splits the task ID into its username and UUID, returning an error if the task belongs to another user.
+func (tss *TaskStatusServiceImpl) authorizeTask(username string, taskID string) (string, string, error) {
+	idUsername, taskUUID, err := parseTaskID(taskID)
+	if err != nil {
+		log.Printf("error parsing task id: %v", err)
+		return "", "", err
+	}
+	if idUsername != username {
+		log.Println("Not Authorized")
+		return "", "", errors.New(NotAuthorized)
+	}
+	return idUsername, taskUUID, nil
+}
+
+// taskAccessError passes repository.ErrTaskNotFound through and replaces any other repository error with
+// ErrorAccessingData after logging it.
+func taskAccessError(err error) error {
+	if errors.Is(err, repository.ErrTaskNotFound) {
+		return err
+	}
+	log.Printf("Error accessing task: %v", err)
+	return errors.New(ErrorAccessingData)
+}
@@ -2,16 +2,19 @@ package service
 
 import (
 	"context"
+	"errors"
 	pbt "github.com/oOSomnus/transflate/api/generated/translate"
 	"github.com/spf13/viper"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"io"
 	"log"
 	"time"
 )
 
 type TranslateService interface {
-	TranslateText(text string) (*pbt.TranslateResult, error)
+	TranslateText(ctx context.Context, req *pbt.TranslateRequest, progress ProgressFunc) (*pbt.TranslateResult, error)
+	ProposeTerms(ctx context.Context, req *pbt.TermRequest) (*pbt.TermResult, error)
 	CloseTransGrpcConn() error
 }
 
@@ -66,15 +69,48 @@ func (t *TranslateServiceImpl) CloseTransGrpcConn() error {
 	return nil
 }
 
-// TranslateText translates the given text by sending it to the translation service via gRPC and returns the result.
-func (t *TranslateServiceImpl) TranslateText(text string) (*pbt.TranslateResult, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+// TranslateText translates the text of the given request, along with its glossary, by sending it to the translation
+// service via gRPC and returns the result. The chunks translated so far are passed to progress as the translation
+// service reports them, unless progress is nil. The call is aborted once ctx is done.
+func (t *TranslateServiceImpl) TranslateText(
+	ctx context.Context, req *pbt.TranslateRequest, progress ProgressFunc,
+) (*pbt.TranslateResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
 	defer cancel()
-	response, err := t.translateClient.ProcessTranslation(ctx, &pbt.TranslateRequest{Text: text})
+	stream, err := t.translateClient.TrackTranslation(ctx, req)
 	if err != nil {
 		log.Printf("Error translating text: %v", err)
 		return nil, err
 	}
 
+	for {
+		update, err := stream.Recv()
+		if err == io.EOF {
+			log.Println("Translation stream ended without a result")
+			return nil, errors.New("translation stream ended without a result")
+		}
+		if err != nil {
+			log.Printf("Error translating text: %v", err)
+			return nil, err
+		}
+		if progress != nil {
+			progress(int(update.ChunksDone), int(update.ChunksTotal))
+		}
+		if update.Result != nil {
+			return update.Result, nil
+		}
+	}
+}
+
+// ProposeTerms asks the translation service for the candidate terms of the requested text and the translations it
+// proposes for them, leaving out terms of the request's glossary. The call is aborted once ctx is done.
+func (t *TranslateServiceImpl) ProposeTerms(ctx context.Context, req *pbt.TermRequest) (*pbt.TermResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+	response, err := t.translateClient.ProposeTerms(ctx, req)
+	if err != nil {
+		log.Printf("Error proposing terms: %v", err)
+		return nil, err
+	}
 	return response, nil
 }
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/spf13/viper"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// defaultWebhookTimeout is how long a webhook receiver may take to respond unless webhook.timeout is configured.
+// maxWebhookResponse is the number of bytes of a webhook response read before the connection is reused.
+const (
+	defaultWebhookTimeout = 10 * time.Second
+	maxWebhookResponse    = 64 << 10
+)
+
+// ErrWebhookAddressBlocked indicates that a webhook would connect to an address outside the public internet.
+var ErrWebhookAddressBlocked = errors.New("webhook address is not a public internet address")
+
+// blockedPrefixes are the IPv4 ranges reserved for special use that netip.Addr does not classify: "this network",
+// carrier-grade NAT, IETF protocol assignments, benchmarking and the reserved range including broadcast.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+}
+
+// IsPublicAddress reports whether webhooks may connect to addr. Loopback, private, link-local, unspecified and
+// multicast addresses, and the special-use ranges of blockedPrefixes, are internal to the network of the workers, so
+// payloads are never posted to them.
+func IsPublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() || addr.IsUnspecified() {
+		return false
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// WebhookSender posts webhook payloads.
+// Send posts the body with the given headers to the URL and returns the status code of the response. An error is only
+// returned if no response was received.
+type WebhookSender interface {
+	Send(ctx context.Context, url string, headers map[string]string, body []byte) (int, error)
+}
+
+// HTTPWebhookSender is a WebhookSender posting payloads with an HTTP client that does not follow redirects, so that a
+// payload is only ever posted to the registered URL, and only connects to public addresses, see IsPublicAddress. The
+// address is checked when connecting rather than when resolving the host, so that a host resolving to a public
+// address at registration cannot point the workers to an internal one later.
+type HTTPWebhookSender struct {
+	client *http.Client
+}
+
+// NewWebhookSender initializes and returns a new HTTPWebhookSender waiting up to webhook.timeout for every response.
+func NewWebhookSender() *HTTPWebhookSender {
+	timeout := viper.GetDuration("webhook.timeout")
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+	dialer := &net.Dialer{Timeout: timeout, Control: controlWebhookAddress}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would be dialled instead of the receiver, so no proxy is used
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &HTTPWebhookSender{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   timeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Send posts the body to the URL and returns the status code of the response, whose body is discarded.
+func (s *HTTPWebhookSender) Send(ctx context.Context, url string, headers map[string]string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxWebhookResponse))
+	return resp.StatusCode, nil
+}
+
+// controlWebhookAddress refuses connections to addresses that are not public, see IsPublicAddress. It runs for every
+// address the dialer tries, after the host has been resolved.
+func controlWebhookAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !IsPublicAddress(addr) {
+		return ErrWebhookAddressBlocked
+	}
+	return nil
+}
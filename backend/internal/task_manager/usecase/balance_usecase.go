@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"github.com/oOSomnus/transflate/internal/task_manager/domain"
+	"github.com/oOSomnus/transflate/internal/task_manager/repository"
+)
+
+// BalanceUsecase defines the operations related to the ledger of the balances of users.
+// ListTransactions retrieves a page of the transactions of a user selected by the filter and the number of
+// transactions in its date range.
+// FindBalanceDrift retrieves the users whose stored balance no longer matches their ledger.
+type BalanceUsecase interface {
+	ListTransactions(username string, filter domain.TransactionFilter) ([]domain.BalanceTransaction, int, error)
+	FindBalanceDrift() ([]domain.BalanceDrift, error)
+}
+
+// BalanceUsecaseImpl is a struct implementing business use cases for the ledger of balances using a
+// BalanceRepository.
+type BalanceUsecaseImpl struct {
+	Repo repository.BalanceRepository
+}
+
+// NewBalanceUsecase initializes and returns a new instance of BalanceUsecaseImpl with the provided BalanceRepository.
+func NewBalanceUsecase(r repository.BalanceRepository) *BalanceUsecaseImpl {
+	return &BalanceUsecaseImpl{Repo: r}
+}
+
+// ErrInvalidTransactionRange represents an error message for transaction filters ending before they start.
+const ErrInvalidTransactionRange = "transaction date range must not end before it starts"
+
+// defaultTransactionLimit and maxTransactionLimit are the default and maximum number of transactions listed at once.
+const (
+	defaultTransactionLimit = 50
+	maxTransactionLimit     = 200
+)
+
+// ListTransactions validates the filter and retrieves the transactions of the user it selects, newest first, along
+// with the number of transactions in its date range. It lists defaultTransactionLimit transactions unless the filter
+// has a limit, and at most maxTransactionLimit.
+func (b *BalanceUsecaseImpl) ListTransactions(
+	username string, filter domain.TransactionFilter,
+) ([]domain.BalanceTransaction, int, error) {
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return nil, 0, errors.New(ErrInvalidTransactionRange)
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultTransactionLimit
+	}
+	if filter.Limit > maxTransactionLimit {
+		filter.Limit = maxTransactionLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	transactions, total, err := b.Repo.ListTransactions(username, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list balance transactions: %w", err)
+	}
+	return transactions, total, nil
+}
+
+// FindBalanceDrift retrieves the users whose balance differs from the sum of their ledger, which means the balance
+// was changed without a transaction, such as by hand in the database.
+func (b *BalanceUsecaseImpl) FindBalanceDrift() ([]domain.BalanceDrift, error) {
+	drifts, err := b.Repo.FindBalanceDrift()
+	if err != nil {
+		return nil, fmt.Errorf("failed to reconcile balances: %w", err)
+	}
+	return drifts, nil
+}
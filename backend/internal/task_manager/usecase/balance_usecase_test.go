@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/oOSomnus/transflate/internal/task_manager/domain"
+	"github.com/oOSomnus/transflate/internal/task_manager/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListTransactions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := repository.NewMockBalanceRepository(ctrl)
+	usecase := NewBalanceUsecase(mockRepo)
+
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	transactions := []domain.BalanceTransaction{
+		{ID: 2, Kind: domain.TransactionCommit, BalanceAfter: 7, TaskID: "1", Reason: domain.ReasonTaskCompleted},
+		{ID: 1, Kind: domain.TransactionReservation, Amount: -3, BalanceAfter: 7, TaskID: "1"},
+	}
+
+	tests := []struct {
+		name      string
+		filter    domain.TransactionFilter
+		mockSetup func()
+		want      []domain.BalanceTransaction
+		wantTotal int
+		wantErr   string
+	}{
+		{
+			name:   "applies default limit",
+			filter: domain.TransactionFilter{From: from, To: to},
+			mockSetup: func() {
+				mockRepo.EXPECT().ListTransactions(
+					"testuser", domain.TransactionFilter{From: from, To: to, Limit: defaultTransactionLimit},
+				).Return(transactions, 2, nil)
+			},
+			want:      transactions,
+			wantTotal: 2,
+		},
+		{
+			name:   "caps limit and clamps offset",
+			filter: domain.TransactionFilter{Limit: 1000, Offset: -5},
+			mockSetup: func() {
+				mockRepo.EXPECT().ListTransactions(
+					"testuser", domain.TransactionFilter{Limit: maxTransactionLimit},
+				).Return([]domain.BalanceTransaction{}, 0, nil)
+			},
+			want: []domain.BalanceTransaction{},
+		},
+		{
+			name:      "range ending before it starts",
+			filter:    domain.TransactionFilter{From: to, To: from},
+			mockSetup: func() {},
+			wantErr:   ErrInvalidTransactionRange,
+		},
+		{
+			name:   "repository error",
+			filter: domain.TransactionFilter{Limit: 10, Offset: 20},
+			mockSetup: func() {
+				mockRepo.EXPECT().ListTransactions(
+					"testuser", domain.TransactionFilter{Limit: 10, Offset: 20},
+				).Return(nil, 0, errors.New("connection refused"))
+			},
+			wantErr: "failed to list balance transactions: connection refused",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.mockSetup()
+				got, total, err := usecase.ListTransactions("testuser", tt.filter)
+				if tt.wantErr != "" {
+					assert.EqualError(t, err, tt.wantErr)
+					return
+				}
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+				assert.Equal(t, tt.wantTotal, total)
+			},
+		)
+	}
+}
+
+func TestFindBalanceDrift(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := repository.NewMockBalanceRepository(ctrl)
+	usecase := NewBalanceUsecase(mockRepo)
+
+	drifts := []domain.BalanceDrift{{Username: "testuser", Balance: 12, LedgerBalance: 10}}
+	mockRepo.EXPECT().FindBalanceDrift().Return(drifts, nil)
+	got, err := usecase.FindBalanceDrift()
+	assert.NoError(t, err)
+	assert.Equal(t, drifts, got)
+
+	mockRepo.EXPECT().FindBalanceDrift().Return(nil, errors.New("connection refused"))
+	_, err = usecase.FindBalanceDrift()
+	assert.EqualError(t, err, "failed to reconcile balances: connection refused")
+}
@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/oOSomnus/transflate/internal/task_manager/domain"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"strings"
+)
+
+// ErrNoSegments indicates that a bilingual layout was requested for a translation without aligned segments.
+var ErrNoSegments = errors.New("translation has no aligned source segments")
+
+// markdownRenderer converts markdown to HTML. Raw HTML in the markdown is omitted, since neither OCR output nor model
+// responses are trusted.
+var markdownRenderer = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// htmlTemplate wraps rendered HTML into a standalone page; the placeholder receives the body.
+const htmlTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Translation</title>
+<style>
+body { font-family: sans-serif; line-height: 1.6; margin: 2em auto; max-width: 72em; padding: 0 1em; }
+.segment { border-bottom: 1px solid #ddd; padding: 1em 0; }
+.source { color: #555; }
+table.bilingual { border-collapse: collapse; width: 100%%; }
+table.bilingual th, table.bilingual td { border: 1px solid #ddd; padding: 0.5em 1em; vertical-align: top; width: 50%%; }
+</style>
+</head>
+<body>
+%s</body>
+</html>
+`
+
+// RenderDocument renders a translation result with the given output options and returns the document content and the
+// file extension it should be stored with.
+// Bilingual layouts keep every source segment aligned with its translation and return ErrNoSegments if the result has
+// no segments.
+func RenderDocument(result *domain.TranslationResult, output domain.OutputOptions) (string, string, error) {
+	if output.IsBilingual() && len(result.Segments) == 0 {
+		return "", "", ErrNoSegments
+	}
+
+	if output.Format == domain.FormatHTML {
+		body, err := renderHTMLBody(result, output.Layout)
+		if err != nil {
+			return "", "", err
+		}
+		return fmt.Sprintf(htmlTemplate, body), ".html", nil
+	}
+
+	switch output.Layout {
+	case domain.LayoutAlternating:
+		return renderAlternatingMarkdown(result.Segments), ".md", nil
+	case domain.LayoutTable:
+		return renderTableMarkdown(result.Segments), ".md", nil
+	default:
+		return result.Text, ".md", nil
+	}
+}
+
+// renderAlternatingMarkdown renders every source segment as a blockquote followed by its translation, separating
+// segments with a thematic break.
+func renderAlternatingMarkdown(segments []domain.Segment) string {
+	var builder strings.Builder
+	for i, segment := range segments {
+		if i > 0 {
+			builder.WriteString("\n---\n\n")
+		}
+		for _, line := range strings.Split(strings.TrimSpace(segment.Source), "\n") {
+			builder.WriteString(strings.TrimRight("> "+line, " ") + "\n")
+		}
+		builder.WriteString("\n" + strings.TrimSpace(segment.Translation) + "\n")
+	}
+	return builder.String()
+}
+
+// renderTableMarkdown renders the segments as a two-column markdown table with the source on the left.
+func renderTableMarkdown(segments []domain.Segment) string {
+	var builder strings.Builder
+	builder.WriteString("| Source | Translation |\n| --- | --- |\n")
+	for _, segment := range segments {
+		builder.WriteString("| " + tableCell(segment.Source) + " | " + tableCell(segment.Translation) + " |\n")
+	}
+	return builder.String()
+}
+
+// tableCell fits text into a single markdown table cell by escaping pipes and turning line breaks into <br> tags.
+func tableCell(text string) string {
+	text = strings.TrimSpace(text)
+	text = strings.ReplaceAll(text, "|", "\\|")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(text, "\n", "<br>")
+}
+
+// renderHTMLBody renders the body of the HTML page for the given layout.
+func renderHTMLBody(result *domain.TranslationResult, layout string) (string, error) {
+	var builder strings.Builder
+	switch layout {
+	case domain.LayoutAlternating:
+		for _, segment := range result.Segments {
+			source, err := markdownToHTML(segment.Source)
+			if err != nil {
+				return "", err
+			}
+			translation, err := markdownToHTML(segment.Translation)
+			if err != nil {
+				return "", err
+			}
+			builder.WriteString(`<section class="segment">` + "\n")
+			builder.WriteString(`<div class="source">` + "\n" + source + "</div>\n")
+			builder.WriteString(`<div class="translation">` + "\n" + translation + "</div>\n")
+			builder.WriteString("</section>\n")
+		}
+	case domain.LayoutTable:
+		builder.WriteString(`<table class="bilingual">` + "\n")
+		builder.WriteString("<thead><tr><th>Source</th><th>Translation</th></tr></thead>\n")
+		builder.WriteString("<tbody>\n")
+		for _, segment := range result.Segments {
+			source, err := markdownToHTML(segment.Source)
+			if err != nil {
+				return "", err
+			}
+			translation, err := markdownToHTML(segment.Translation)
+			if err != nil {
+				return "", err
+			}
+			builder.WriteString("<tr>\n<td>\n" + source + "</td>\n<td>\n" + translation + "</td>\n</tr>\n")
+		}
+		builder.WriteString("</tbody>\n</table>\n")
+	default:
+		translation, err := markdownToHTML(result.Text)
+		if err != nil {
+			return "", err
+		}
+		builder.WriteString(translation)
+	}
+	return builder.String(), nil
+}
+
+// markdownToHTML converts markdown to an HTML fragment.
+func markdownToHTML(markdown string) (string, error) {
+	var buf bytes.Buffer
+	if err := markdownRenderer.Convert([]byte(markdown), &buf); err != nil {
+		return "", fmt.Errorf("failed to render markdown: %w", err)
+	}
+	return buf.String(), nil
+}
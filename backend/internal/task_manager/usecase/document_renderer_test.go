@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/oOSomnus/transflate/internal/task_manager/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderDocument(t *testing.T) {
+	result := &domain.TranslationResult{
+		Text: "你好，世界。\n再见。",
+		Segments: []domain.Segment{
+			{Source: "Hello, world.\nSecond line.", Translation: "你好，世界。"},
+			{Source: "a | b", Translation: "**再见**。"},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		output    domain.OutputOptions
+		extension string
+		contains  []string
+	}{
+		{
+			name:      "translation markdown",
+			output:    domain.OutputOptions{Layout: domain.LayoutTranslation, Format: domain.FormatMarkdown},
+			extension: ".md",
+			contains:  []string{result.Text},
+		},
+		{
+			name:      "alternating markdown",
+			output:    domain.OutputOptions{Layout: domain.LayoutAlternating, Format: domain.FormatMarkdown},
+			extension: ".md",
+			contains: []string{
+				"> Hello, world.\n> Second line.\n\n你好，世界。\n\n---\n\n> a | b\n\n**再见**。\n",
+			},
+		},
+		{
+			name:      "table markdown",
+			output:    domain.OutputOptions{Layout: domain.LayoutTable, Format: domain.FormatMarkdown},
+			extension: ".md",
+			contains: []string{
+				"| Source | Translation |\n| --- | --- |\n",
+				"| Hello, world.<br>Second line. | 你好，世界。 |\n",
+				"| a \\| b | **再见**。 |\n",
+			},
+		},
+		{
+			name:      "table html",
+			output:    domain.OutputOptions{Layout: domain.LayoutTable, Format: domain.FormatHTML},
+			extension: ".html",
+			contains: []string{
+				`<meta charset="utf-8">`,
+				"<td>\n<p>Hello, world.\nSecond line.</p>\n</td>\n<td>\n<p>你好，世界。</p>\n</td>",
+				"<p><strong>再见</strong>。</p>",
+			},
+		},
+		{
+			name:      "alternating html",
+			output:    domain.OutputOptions{Layout: domain.LayoutAlternating, Format: domain.FormatHTML},
+			extension: ".html",
+			contains:  []string{`<div class="source">` + "\n<p>a | b</p>\n</div>"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				content, extension, err := RenderDocument(result, tt.output)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.extension, extension)
+				for _, expected := range tt.contains {
+					assert.Contains(t, content, expected)
+				}
+			},
+		)
+	}
+}
+
+func TestRenderDocumentEscapesRawHTML(t *testing.T) {
+	result := &domain.TranslationResult{Text: "<script>alert(1)</script>\n\ntext"}
+	content, _, err := RenderDocument(
+		result, domain.OutputOptions{Layout: domain.LayoutTranslation, Format: domain.FormatHTML},
+	)
+	assert.NoError(t, err)
+	assert.False(t, strings.Contains(content, "<script>"))
+}
@@ -0,0 +1,279 @@
+package usecase
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/oOSomnus/transflate/internal/task_manager/domain"
+	"github.com/oOSomnus/transflate/internal/task_manager/repository"
+	"io"
+	"log"
+	"strings"
+)
+
+// GlossaryUsecase defines the operations related to per-user glossary management.
+// CreateGlossary creates a glossary with the given name and terms and returns it.
+// ListGlossaries retrieves all glossaries of a user.
+// GetGlossary retrieves a glossary of a user including its terms.
+// UpdateGlossary renames a glossary and replaces all of its terms.
+// DeleteGlossary removes a glossary together with its terms.
+// AddTerms adds terms to a glossary, overwriting the target of terms whose source already exists.
+// DeleteTerm removes a single term from a glossary.
+// ImportTerms parses a CSV or TBX file and adds its terms to a glossary, returning the number of imported terms.
+type GlossaryUsecase interface {
+	CreateGlossary(username string, req domain.GlossaryRequest) (*domain.Glossary, error)
+	ListGlossaries(username string) ([]domain.Glossary, error)
+	GetGlossary(username string, glossaryId int) (*domain.Glossary, error)
+	UpdateGlossary(username string, glossaryId int, req domain.GlossaryRequest) error
+	DeleteGlossary(username string, glossaryId int) error
+	AddTerms(username string, glossaryId int, terms []domain.GlossaryTerm) error
+	DeleteTerm(username string, glossaryId, termId int) error
+	ImportTerms(username string, glossaryId int, format string, r io.Reader) (int, error)
+}
+
+// GlossaryUsecaseImpl is a struct implementing business use cases for glossaries using a GlossaryRepository.
+type GlossaryUsecaseImpl struct {
+	Repo repository.GlossaryRepository
+}
+
+// NewGlossaryUsecase initializes and returns a new instance of GlossaryUsecaseImpl using the provided GlossaryRepository.
+func NewGlossaryUsecase(r repository.GlossaryRepository) *GlossaryUsecaseImpl {
+	return &GlossaryUsecaseImpl{
+		Repo: r,
+	}
+}
+
+// ErrInvalidGlossary represents an error message for glossaries with an empty name or incomplete terms.
+// ErrUnsupportedFormat represents an error message for glossary imports in an unknown file format.
+// ErrEmptyImport represents an error message for glossary imports that contain no terms.
+const (
+	ErrInvalidGlossary   = "glossary name and terms cannot be empty"
+	ErrUnsupportedFormat = "unsupported glossary format, expected csv or tbx"
+	ErrEmptyImport       = "no glossary terms found in file"
+)
+
+// maxGlossaryNameLength is the maximum length of a glossary name allowed by the glossaries table.
+// maxTermLength is the maximum length of a source or target term allowed by the glossary_terms table.
+const (
+	maxGlossaryNameLength = 100
+	maxTermLength         = 255
+)
+
+// CreateGlossary validates the request, creates the glossary for the user and stores its initial terms.
+func (g *GlossaryUsecaseImpl) CreateGlossary(username string, req domain.GlossaryRequest) (*domain.Glossary, error) {
+	name, terms, err := normalizeGlossaryRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	glossaryId, err := g.Repo.CreateGlossary(username, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create glossary: %w", err)
+	}
+	if len(terms) > 0 {
+		if err := g.Repo.UpsertTerms(username, glossaryId, terms); err != nil {
+			return nil, fmt.Errorf("failed to save glossary terms: %w", err)
+		}
+	}
+	return g.Repo.GetGlossary(username, glossaryId)
+}
+
+// ListGlossaries retrieves all glossaries owned by the user without their terms.
+func (g *GlossaryUsecaseImpl) ListGlossaries(username string) ([]domain.Glossary, error) {
+	glossaries, err := g.Repo.ListGlossaries(username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list glossaries: %w", err)
+	}
+	return glossaries, nil
+}
+
+// GetGlossary retrieves a glossary owned by the user including all of its terms.
+func (g *GlossaryUsecaseImpl) GetGlossary(username string, glossaryId int) (*domain.Glossary, error) {
+	return g.Repo.GetGlossary(username, glossaryId)
+}
+
+// UpdateGlossary validates the request, then renames the glossary and replaces all of its terms.
+func (g *GlossaryUsecaseImpl) UpdateGlossary(username string, glossaryId int, req domain.GlossaryRequest) error {
+	name, terms, err := normalizeGlossaryRequest(req)
+	if err != nil {
+		return err
+	}
+	return g.Repo.UpdateGlossary(username, glossaryId, name, terms)
+}
+
+// DeleteGlossary removes a glossary owned by the user together with its terms.
+func (g *GlossaryUsecaseImpl) DeleteGlossary(username string, glossaryId int) error {
+	return g.Repo.DeleteGlossary(username, glossaryId)
+}
+
+// AddTerms validates the terms and adds them to a glossary owned by the user.
+func (g *GlossaryUsecaseImpl) AddTerms(username string, glossaryId int, terms []domain.GlossaryTerm) error {
+	normalized, err := normalizeTerms(terms)
+	if err != nil {
+		return err
+	}
+	if len(normalized) == 0 {
+		return errors.New(ErrInvalidGlossary)
+	}
+	return g.Repo.UpsertTerms(username, glossaryId, normalized)
+}
+
+// DeleteTerm removes a term from a glossary owned by the user.
+func (g *GlossaryUsecaseImpl) DeleteTerm(username string, glossaryId, termId int) error {
+	return g.Repo.DeleteTerm(username, glossaryId, termId)
+}
+
+// ImportTerms parses r as a CSV or TBX file according to format and adds the contained terms to a glossary owned by the
+// user. It returns the number of distinct terms imported.
+func (g *GlossaryUsecaseImpl) ImportTerms(username string, glossaryId int, format string, r io.Reader) (int, error) {
+	var terms []domain.GlossaryTerm
+	var err error
+	switch strings.ToLower(format) {
+	case "csv":
+		terms, err = ParseCSVGlossary(r)
+	case "tbx":
+		terms, err = ParseTBXGlossary(r)
+	default:
+		return 0, errors.New(ErrUnsupportedFormat)
+	}
+	if err != nil {
+		log.Printf("Error parsing %s glossary: %v", format, err)
+		return 0, err
+	}
+
+	terms, err = normalizeTerms(terms)
+	if err != nil {
+		return 0, err
+	}
+	if len(terms) == 0 {
+		return 0, errors.New(ErrEmptyImport)
+	}
+	if err := g.Repo.UpsertTerms(username, glossaryId, terms); err != nil {
+		return 0, err
+	}
+	return len(terms), nil
+}
+
+// normalizeGlossaryRequest trims and validates the name and terms of a glossary request.
+func normalizeGlossaryRequest(req domain.GlossaryRequest) (string, []domain.GlossaryTerm, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > maxGlossaryNameLength {
+		return "", nil, errors.New(ErrInvalidGlossary)
+	}
+	terms, err := normalizeTerms(req.Terms)
+	if err != nil {
+		return "", nil, err
+	}
+	return name, terms, nil
+}
+
+// normalizeTerms trims the source and target of every term and removes duplicate sources, keeping the last target.
+// Returns an error if a term has an empty or overlong source or target.
+func normalizeTerms(terms []domain.GlossaryTerm) ([]domain.GlossaryTerm, error) {
+	positions := make(map[string]int, len(terms))
+	normalized := make([]domain.GlossaryTerm, 0, len(terms))
+	for _, term := range terms {
+		source := strings.TrimSpace(term.Source)
+		target := strings.TrimSpace(term.Target)
+		if source == "" || target == "" || len(source) > maxTermLength || len(target) > maxTermLength {
+			return nil, errors.New(ErrInvalidGlossary)
+		}
+		if i, ok := positions[source]; ok {
+			normalized[i].Target = target
+			continue
+		}
+		positions[source] = len(normalized)
+		normalized = append(normalized, domain.GlossaryTerm{Source: source, Target: target})
+	}
+	return normalized, nil
+}
+
+// ParseCSVGlossary reads glossary terms from CSV data with the source term in the first column and the target term in
+// the second. A leading header row naming the columns "source" and "target" is skipped, as are blank rows.
+func ParseCSVGlossary(r io.Reader) ([]domain.GlossaryTerm, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var terms []domain.GlossaryTerm
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv glossary: %w", err)
+		}
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		if len(record) < 2 {
+			return nil, fmt.Errorf("invalid csv glossary: line %d needs a source and a target column", line)
+		}
+		source := strings.TrimPrefix(record[0], "\ufeff")
+		if line == 1 && strings.EqualFold(strings.TrimSpace(source), "source") &&
+			strings.EqualFold(strings.TrimSpace(record[1]), "target") {
+			continue
+		}
+		terms = append(terms, domain.GlossaryTerm{Source: source, Target: record[1]})
+	}
+	return terms, nil
+}
+
+// tbxDocument is the subset of a TBX (TermBase eXchange) file needed to read glossary terms. It covers both the TBX 2
+// martif layout with termEntry/langSet/tig and the TBX 3 layout with conceptEntry/langSec/termSec.
+type tbxDocument struct {
+	Entries []tbxEntry `xml:"text>body>termEntry"`
+	Concept []tbxEntry `xml:"text>body>conceptEntry"`
+}
+
+// tbxEntry is a single concept holding its terms in one language section per language.
+type tbxEntry struct {
+	LangSets []tbxLangSet `xml:"langSet"`
+	LangSecs []tbxLangSet `xml:"langSec"`
+}
+
+// tbxLangSet holds the terms of a concept in a single language, either directly, in tig elements or in termSec elements.
+type tbxLangSet struct {
+	Lang  string   `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	Terms []string `xml:"term"`
+	Tigs  []string `xml:"tig>term"`
+	Secs  []string `xml:"termSec>term"`
+}
+
+// firstTerm returns the first non-empty term of the language section, or an empty string if there is none.
+func (l tbxLangSet) firstTerm() string {
+	for _, group := range [][]string{l.Tigs, l.Secs, l.Terms} {
+		for _, term := range group {
+			if term = strings.TrimSpace(term); term != "" {
+				return term
+			}
+		}
+	}
+	return ""
+}
+
+// ParseTBXGlossary reads glossary terms from a TBX file. For each concept entry the first term of its first language
+// section becomes the source term and the first term of its last language section becomes the target term. Entries with
+// fewer than two language sections are skipped.
+func ParseTBXGlossary(r io.Reader) ([]domain.GlossaryTerm, error) {
+	var doc tbxDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid tbx glossary: %w", err)
+	}
+
+	var terms []domain.GlossaryTerm
+	for _, entry := range append(doc.Entries, doc.Concept...) {
+		langSets := append(entry.LangSets, entry.LangSecs...)
+		if len(langSets) < 2 {
+			continue
+		}
+		source := langSets[0].firstTerm()
+		target := langSets[len(langSets)-1].firstTerm()
+		if source == "" || target == "" {
+			continue
+		}
+		terms = append(terms, domain.GlossaryTerm{Source: source, Target: target})
+	}
+	return terms, nil
+}
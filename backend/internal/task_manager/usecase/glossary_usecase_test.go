@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/oOSomnus/transflate/internal/task_manager/domain"
+	"github.com/oOSomnus/transflate/internal/task_manager/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCSVGlossary(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []domain.GlossaryTerm
+		wantErr  bool
+	}{
+		{
+			"with header", "source,target\nplaintiff,原告\n\"breach, material\",重大违约\n",
+			[]domain.GlossaryTerm{{Source: "plaintiff", Target: "原告"}, {Source: "breach, material", Target: "重大违约"}},
+			false,
+		},
+		{"without header", "\ufeffcontract,合同\n\n", []domain.GlossaryTerm{{Source: "contract", Target: "合同"}}, false},
+		{"missing target column", "contract\n", nil, true},
+		{"empty input", "", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got, err := ParseCSVGlossary(strings.NewReader(tt.input))
+				if tt.wantErr {
+					assert.Error(t, err)
+					return
+				}
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, got)
+			},
+		)
+	}
+}
+
+func TestParseTBXGlossary(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []domain.GlossaryTerm
+		wantErr  bool
+	}{
+		{
+			"tbx 2 martif",
+			`<?xml version="1.0"?>
+<martif type="TBX" xml:lang="en"><text><body>
+<termEntry id="1">
+  <langSet xml:lang="en"><tig><term>myocardial infarction</term></tig></langSet>
+  <langSet xml:lang="zh"><tig><term>心肌梗死</term></tig></langSet>
+</termEntry>
+<termEntry id="2"><langSet xml:lang="en"><tig><term>orphan</term></tig></langSet></termEntry>
+</body></text></martif>`,
+			[]domain.GlossaryTerm{{Source: "myocardial infarction", Target: "心肌梗死"}},
+			false,
+		},
+		{
+			"tbx 3 concept entries",
+			`<tbx type="TBX-Core" xml:lang="en"><text><body>
+<conceptEntry id="c1">
+  <langSec xml:lang="en"><termSec><term>tort</term></termSec></langSec>
+  <langSec xml:lang="de"><termSec><term>unerlaubte Handlung</term></termSec></langSec>
+</conceptEntry>
+</body></text></tbx>`,
+			[]domain.GlossaryTerm{{Source: "tort", Target: "unerlaubte Handlung"}},
+			false,
+		},
+		{"malformed xml", "<martif><text>", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got, err := ParseTBXGlossary(strings.NewReader(tt.input))
+				if tt.wantErr {
+					assert.Error(t, err)
+					return
+				}
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, got)
+			},
+		)
+	}
+}
+
+func TestImportTerms(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := repository.NewMockGlossaryRepository(ctrl)
+	usecase := NewGlossaryUsecase(mockRepo)
+
+	tests := []struct {
+		name      string
+		format    string
+		input     string
+		mockSetup func()
+		want      int
+		wantErr   string
+	}{
+		{
+			name:   "duplicate sources keep last target",
+			format: "CSV",
+			input:  " plaintiff , 原告\nplaintiff,起诉人\ndefendant,被告\n",
+			mockSetup: func() {
+				mockRepo.EXPECT().UpsertTerms(
+					"testuser", 7, []domain.GlossaryTerm{
+						{Source: "plaintiff", Target: "起诉人"},
+						{Source: "defendant", Target: "被告"},
+					},
+				).Return(nil)
+			},
+			want: 2,
+		},
+		{name: "unsupported format", format: "xlsx", input: "", mockSetup: func() {}, wantErr: ErrUnsupportedFormat},
+		{name: "no terms", format: "csv", input: "source,target\n", mockSetup: func() {}, wantErr: ErrEmptyImport},
+		{name: "empty target", format: "csv", input: "plaintiff,\n", mockSetup: func() {}, wantErr: ErrInvalidGlossary},
+		{
+			name:   "glossary of another user",
+			format: "csv",
+			input:  "plaintiff,原告\n",
+			mockSetup: func() {
+				mockRepo.EXPECT().UpsertTerms("testuser", 7, gomock.Any()).Return(repository.ErrGlossaryNotFound)
+			},
+			wantErr: repository.ErrGlossaryNotFound.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.mockSetup()
+				got, err := usecase.ImportTerms("testuser", 7, tt.format, strings.NewReader(tt.input))
+				if tt.wantErr != "" {
+					assert.EqualError(t, err, tt.wantErr)
+				} else {
+					assert.NoError(t, err)
+				}
+				assert.Equal(t, tt.want, got)
+			},
+		)
+	}
+}
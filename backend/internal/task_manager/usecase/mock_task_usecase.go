@@ -5,9 +5,12 @@
 package usecase
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	domain "github.com/oOSomnus/transflate/internal/task_manager/domain"
+	service "github.com/oOSomnus/transflate/internal/task_manager/service"
 )
 
 // MockTaskUsecase is a mock of TaskUsecase interface.
@@ -33,32 +36,448 @@ func (m *MockTaskUsecase) EXPECT() *MockTaskUsecaseMockRecorder {
 	return m.recorder
 }
 
+// CommitTaskCharge mocks base method.
+func (m *MockTaskUsecase) CommitTaskCharge(username, taskId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CommitTaskCharge", username, taskId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CommitTaskCharge indicates an expected call of CommitTaskCharge.
+func (mr *MockTaskUsecaseMockRecorder) CommitTaskCharge(username, taskId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitTaskCharge", reflect.TypeOf((*MockTaskUsecase)(nil).CommitTaskCharge), username, taskId)
+}
+
 // CreateDownloadLinkWithMdString mocks base method.
-func (m *MockTaskUsecase) CreateDownloadLinkWithMdString(mdString string) (string, error) {
+func (m *MockTaskUsecase) CreateDownloadLinkWithMdString(result *domain.TranslationResult, output domain.OutputOptions) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateDownloadLinkWithMdString", mdString)
+	ret := m.ctrl.Call(m, "CreateDownloadLinkWithMdString", result, output)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateDownloadLinkWithMdString indicates an expected call of CreateDownloadLinkWithMdString.
-func (mr *MockTaskUsecaseMockRecorder) CreateDownloadLinkWithMdString(mdString interface{}) *gomock.Call {
+func (mr *MockTaskUsecaseMockRecorder) CreateDownloadLinkWithMdString(result, output interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDownloadLinkWithMdString", reflect.TypeOf((*MockTaskUsecase)(nil).CreateDownloadLinkWithMdString), result, output)
+}
+
+// DeleteTaskInput mocks base method.
+func (m *MockTaskUsecase) DeleteTaskInput(job domain.TaskJob) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTaskInput", job)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTaskInput indicates an expected call of DeleteTaskInput.
+func (mr *MockTaskUsecaseMockRecorder) DeleteTaskInput(job interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDownloadLinkWithMdString", reflect.TypeOf((*MockTaskUsecase)(nil).CreateDownloadLinkWithMdString), mdString)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTaskInput", reflect.TypeOf((*MockTaskUsecase)(nil).DeleteTaskInput), job)
 }
 
-// ProcessOCRAndTranslate mocks base method.
-func (m *MockTaskUsecase) ProcessOCRAndTranslate(username string, fileContent []byte, lang string) (string, error) {
+// EnqueueTask mocks base method.
+func (m *MockTaskUsecase) EnqueueTask(job domain.TaskJob, input []byte, extension string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ProcessOCRAndTranslate", username, fileContent, lang)
+	ret := m.ctrl.Call(m, "EnqueueTask", job, input, extension)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnqueueTask indicates an expected call of EnqueueTask.
+func (mr *MockTaskUsecaseMockRecorder) EnqueueTask(job, input, extension interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueTask", reflect.TypeOf((*MockTaskUsecase)(nil).EnqueueTask), job, input, extension)
+}
+
+// LoadExtractedText mocks base method.
+func (m *MockTaskUsecase) LoadExtractedText(job domain.TaskJob) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadExtractedText", job)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ProcessOCRAndTranslate indicates an expected call of ProcessOCRAndTranslate.
-func (mr *MockTaskUsecaseMockRecorder) ProcessOCRAndTranslate(username, fileContent, lang interface{}) *gomock.Call {
+// LoadExtractedText indicates an expected call of LoadExtractedText.
+func (mr *MockTaskUsecaseMockRecorder) LoadExtractedText(job interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadExtractedText", reflect.TypeOf((*MockTaskUsecase)(nil).LoadExtractedText), job)
+}
+
+// LoadTaskInput mocks base method.
+func (m *MockTaskUsecase) LoadTaskInput(job domain.TaskJob) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadTaskInput", job)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadTaskInput indicates an expected call of LoadTaskInput.
+func (mr *MockTaskUsecaseMockRecorder) LoadTaskInput(job interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadTaskInput", reflect.TypeOf((*MockTaskUsecase)(nil).LoadTaskInput), job)
+}
+
+// LoadTranslationResult mocks base method.
+func (m *MockTaskUsecase) LoadTranslationResult(job domain.TaskJob) (*domain.TranslationResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadTranslationResult", job)
+	ret0, _ := ret[0].(*domain.TranslationResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadTranslationResult indicates an expected call of LoadTranslationResult.
+func (mr *MockTaskUsecaseMockRecorder) LoadTranslationResult(job interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadTranslationResult", reflect.TypeOf((*MockTaskUsecase)(nil).LoadTranslationResult), job)
+}
+
+// RefundTask mocks base method.
+func (m *MockTaskUsecase) RefundTask(username, taskId, reason string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefundTask", username, taskId, reason)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefundTask indicates an expected call of RefundTask.
+func (mr *MockTaskUsecaseMockRecorder) RefundTask(username, taskId, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefundTask", reflect.TypeOf((*MockTaskUsecase)(nil).RefundTask), username, taskId, reason)
+}
+
+// ReleaseExpiredReservations mocks base method.
+func (m *MockTaskUsecase) ReleaseExpiredReservations() (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseExpiredReservations")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReleaseExpiredReservations indicates an expected call of ReleaseExpiredReservations.
+func (mr *MockTaskUsecaseMockRecorder) ReleaseExpiredReservations() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseExpiredReservations", reflect.TypeOf((*MockTaskUsecase)(nil).ReleaseExpiredReservations))
+}
+
+// RetryTask mocks base method.
+func (m *MockTaskUsecase) RetryTask(username, taskId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryTask", username, taskId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RetryTask indicates an expected call of RetryTask.
+func (mr *MockTaskUsecaseMockRecorder) RetryTask(username, taskId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryTask", reflect.TypeOf((*MockTaskUsecase)(nil).RetryTask), username, taskId)
+}
+
+// ReverseTaskCharge mocks base method.
+func (m *MockTaskUsecase) ReverseTaskCharge(username, taskId string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReverseTaskCharge", username, taskId)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReverseTaskCharge indicates an expected call of ReverseTaskCharge.
+func (mr *MockTaskUsecaseMockRecorder) ReverseTaskCharge(username, taskId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReverseTaskCharge", reflect.TypeOf((*MockTaskUsecase)(nil).ReverseTaskCharge), username, taskId)
+}
+
+// SaveExtractedText mocks base method.
+func (m *MockTaskUsecase) SaveExtractedText(job domain.TaskJob, text string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveExtractedText", job, text)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveExtractedText indicates an expected call of SaveExtractedText.
+func (mr *MockTaskUsecaseMockRecorder) SaveExtractedText(job, text interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveExtractedText", reflect.TypeOf((*MockTaskUsecase)(nil).SaveExtractedText), job, text)
+}
+
+// SaveTranslationResult mocks base method.
+func (m *MockTaskUsecase) SaveTranslationResult(job domain.TaskJob, result *domain.TranslationResult) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTranslationResult", job, result)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveTranslationResult indicates an expected call of SaveTranslationResult.
+func (mr *MockTaskUsecaseMockRecorder) SaveTranslationResult(job, result interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTranslationResult", reflect.TypeOf((*MockTaskUsecase)(nil).SaveTranslationResult), job, result)
+}
+
+// MockTaskProcessingUsecase is a mock of TaskProcessingUsecase interface.
+type MockTaskProcessingUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockTaskProcessingUsecaseMockRecorder
+}
+
+// MockTaskProcessingUsecaseMockRecorder is the mock recorder for MockTaskProcessingUsecase.
+type MockTaskProcessingUsecaseMockRecorder struct {
+	mock *MockTaskProcessingUsecase
+}
+
+// NewMockTaskProcessingUsecase creates a new mock instance.
+func NewMockTaskProcessingUsecase(ctrl *gomock.Controller) *MockTaskProcessingUsecase {
+	mock := &MockTaskProcessingUsecase{ctrl: ctrl}
+	mock.recorder = &MockTaskProcessingUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTaskProcessingUsecase) EXPECT() *MockTaskProcessingUsecaseMockRecorder {
+	return m.recorder
+}
+
+// CommitTaskCharge mocks base method.
+func (m *MockTaskProcessingUsecase) CommitTaskCharge(username, taskId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CommitTaskCharge", username, taskId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CommitTaskCharge indicates an expected call of CommitTaskCharge.
+func (mr *MockTaskProcessingUsecaseMockRecorder) CommitTaskCharge(username, taskId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitTaskCharge", reflect.TypeOf((*MockTaskProcessingUsecase)(nil).CommitTaskCharge), username, taskId)
+}
+
+// CreateDownloadLinkWithMdString mocks base method.
+func (m *MockTaskProcessingUsecase) CreateDownloadLinkWithMdString(result *domain.TranslationResult, output domain.OutputOptions) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDownloadLinkWithMdString", result, output)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateDownloadLinkWithMdString indicates an expected call of CreateDownloadLinkWithMdString.
+func (mr *MockTaskProcessingUsecaseMockRecorder) CreateDownloadLinkWithMdString(result, output interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDownloadLinkWithMdString", reflect.TypeOf((*MockTaskProcessingUsecase)(nil).CreateDownloadLinkWithMdString), result, output)
+}
+
+// DeleteTaskInput mocks base method.
+func (m *MockTaskProcessingUsecase) DeleteTaskInput(job domain.TaskJob) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTaskInput", job)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTaskInput indicates an expected call of DeleteTaskInput.
+func (mr *MockTaskProcessingUsecaseMockRecorder) DeleteTaskInput(job interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTaskInput", reflect.TypeOf((*MockTaskProcessingUsecase)(nil).DeleteTaskInput), job)
+}
+
+// EnqueueTask mocks base method.
+func (m *MockTaskProcessingUsecase) EnqueueTask(job domain.TaskJob, input []byte, extension string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueTask", job, input, extension)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnqueueTask indicates an expected call of EnqueueTask.
+func (mr *MockTaskProcessingUsecaseMockRecorder) EnqueueTask(job, input, extension interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueTask", reflect.TypeOf((*MockTaskProcessingUsecase)(nil).EnqueueTask), job, input, extension)
+}
+
+// ExtractText mocks base method.
+func (m *MockTaskProcessingUsecase) ExtractText(ctx context.Context, username, taskId string, fileContent []byte, lang string, progress service.ProgressFunc) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExtractText", ctx, username, taskId, fileContent, lang, progress)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExtractText indicates an expected call of ExtractText.
+func (mr *MockTaskProcessingUsecaseMockRecorder) ExtractText(ctx, username, taskId, fileContent, lang, progress interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExtractText", reflect.TypeOf((*MockTaskProcessingUsecase)(nil).ExtractText), ctx, username, taskId, fileContent, lang, progress)
+}
+
+// LoadExtractedText mocks base method.
+func (m *MockTaskProcessingUsecase) LoadExtractedText(job domain.TaskJob) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadExtractedText", job)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadExtractedText indicates an expected call of LoadExtractedText.
+func (mr *MockTaskProcessingUsecaseMockRecorder) LoadExtractedText(job interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadExtractedText", reflect.TypeOf((*MockTaskProcessingUsecase)(nil).LoadExtractedText), job)
+}
+
+// LoadTaskInput mocks base method.
+func (m *MockTaskProcessingUsecase) LoadTaskInput(job domain.TaskJob) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadTaskInput", job)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadTaskInput indicates an expected call of LoadTaskInput.
+func (mr *MockTaskProcessingUsecaseMockRecorder) LoadTaskInput(job interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadTaskInput", reflect.TypeOf((*MockTaskProcessingUsecase)(nil).LoadTaskInput), job)
+}
+
+// LoadTranslationResult mocks base method.
+func (m *MockTaskProcessingUsecase) LoadTranslationResult(job domain.TaskJob) (*domain.TranslationResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadTranslationResult", job)
+	ret0, _ := ret[0].(*domain.TranslationResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadTranslationResult indicates an expected call of LoadTranslationResult.
+func (mr *MockTaskProcessingUsecaseMockRecorder) LoadTranslationResult(job interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadTranslationResult", reflect.TypeOf((*MockTaskProcessingUsecase)(nil).LoadTranslationResult), job)
+}
+
+// ProposeTerms mocks base method.
+func (m *MockTaskProcessingUsecase) ProposeTerms(ctx context.Context, text string, options domain.TaskOptions) ([]domain.ReviewTerm, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProposeTerms", ctx, text, options)
+	ret0, _ := ret[0].([]domain.ReviewTerm)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProposeTerms indicates an expected call of ProposeTerms.
+func (mr *MockTaskProcessingUsecaseMockRecorder) ProposeTerms(ctx, text, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProposeTerms", reflect.TypeOf((*MockTaskProcessingUsecase)(nil).ProposeTerms), ctx, text, options)
+}
+
+// RefundTask mocks base method.
+func (m *MockTaskProcessingUsecase) RefundTask(username, taskId, reason string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefundTask", username, taskId, reason)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefundTask indicates an expected call of RefundTask.
+func (mr *MockTaskProcessingUsecaseMockRecorder) RefundTask(username, taskId, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefundTask", reflect.TypeOf((*MockTaskProcessingUsecase)(nil).RefundTask), username, taskId, reason)
+}
+
+// ReleaseExpiredReservations mocks base method.
+func (m *MockTaskProcessingUsecase) ReleaseExpiredReservations() (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseExpiredReservations")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReleaseExpiredReservations indicates an expected call of ReleaseExpiredReservations.
+func (mr *MockTaskProcessingUsecaseMockRecorder) ReleaseExpiredReservations() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseExpiredReservations", reflect.TypeOf((*MockTaskProcessingUsecase)(nil).ReleaseExpiredReservations))
+}
+
+// RetryTask mocks base method.
+func (m *MockTaskProcessingUsecase) RetryTask(username, taskId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryTask", username, taskId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RetryTask indicates an expected call of RetryTask.
+func (mr *MockTaskProcessingUsecaseMockRecorder) RetryTask(username, taskId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryTask", reflect.TypeOf((*MockTaskProcessingUsecase)(nil).RetryTask), username, taskId)
+}
+
+// ReverseTaskCharge mocks base method.
+func (m *MockTaskProcessingUsecase) ReverseTaskCharge(username, taskId string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReverseTaskCharge", username, taskId)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReverseTaskCharge indicates an expected call of ReverseTaskCharge.
+func (mr *MockTaskProcessingUsecaseMockRecorder) ReverseTaskCharge(username, taskId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReverseTaskCharge", reflect.TypeOf((*MockTaskProcessingUsecase)(nil).ReverseTaskCharge), username, taskId)
+}
+
+// SaveExtractedText mocks base method.
+func (m *MockTaskProcessingUsecase) SaveExtractedText(job domain.TaskJob, text string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveExtractedText", job, text)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveExtractedText indicates an expected call of SaveExtractedText.
+func (mr *MockTaskProcessingUsecaseMockRecorder) SaveExtractedText(job, text interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveExtractedText", reflect.TypeOf((*MockTaskProcessingUsecase)(nil).SaveExtractedText), job, text)
+}
+
+// SaveTranslationResult mocks base method.
+func (m *MockTaskProcessingUsecase) SaveTranslationResult(job domain.TaskJob, result *domain.TranslationResult) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTranslationResult", job, result)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveTranslationResult indicates an expected call of SaveTranslationResult.
+func (mr *MockTaskProcessingUsecaseMockRecorder) SaveTranslationResult(job, result interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTranslationResult", reflect.TypeOf((*MockTaskProcessingUsecase)(nil).SaveTranslationResult), job, result)
+}
+
+// TranslateDocument mocks base method.
+func (m *MockTaskProcessingUsecase) TranslateDocument(ctx context.Context, text string, options domain.TaskOptions, progress service.ProgressFunc) (*domain.TranslationResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TranslateDocument", ctx, text, options, progress)
+	ret0, _ := ret[0].(*domain.TranslationResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TranslateDocument indicates an expected call of TranslateDocument.
+func (mr *MockTaskProcessingUsecaseMockRecorder) TranslateDocument(ctx, text, options, progress interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessOCRAndTranslate", reflect.TypeOf((*MockTaskUsecase)(nil).ProcessOCRAndTranslate), username, fileContent, lang)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TranslateDocument", reflect.TypeOf((*MockTaskProcessingUsecase)(nil).TranslateDocument), ctx, text, options, progress)
 }
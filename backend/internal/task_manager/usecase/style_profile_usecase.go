@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"github.com/oOSomnus/transflate/internal/task_manager/domain"
+	"github.com/oOSomnus/transflate/internal/task_manager/repository"
+	"strings"
+)
+
+// StyleProfileUsecase defines the operations related to per-user translation style profiles.
+// CreateStyleProfile validates and stores a style profile and returns it.
+// ListStyleProfiles retrieves all style profiles of a user.
+// GetStyleProfile retrieves a style profile of a user.
+// UpdateStyleProfile replaces the name and style of a style profile.
+// DeleteStyleProfile removes a style profile.
+type StyleProfileUsecase interface {
+	CreateStyleProfile(username string, req domain.StyleProfileRequest) (*domain.StyleProfile, error)
+	ListStyleProfiles(username string) ([]domain.StyleProfile, error)
+	GetStyleProfile(username string, profileId int) (*domain.StyleProfile, error)
+	UpdateStyleProfile(username string, profileId int, req domain.StyleProfileRequest) error
+	DeleteStyleProfile(username string, profileId int) error
+}
+
+// StyleProfileUsecaseImpl is a struct implementing business use cases for style profiles using a
+// StyleProfileRepository.
+type StyleProfileUsecaseImpl struct {
+	Repo repository.StyleProfileRepository
+}
+
+// NewStyleProfileUsecase initializes and returns a new instance of StyleProfileUsecaseImpl using the provided
+// StyleProfileRepository.
+func NewStyleProfileUsecase(r repository.StyleProfileRepository) *StyleProfileUsecaseImpl {
+	return &StyleProfileUsecaseImpl{
+		Repo: r,
+	}
+}
+
+// ErrInvalidStyleProfile represents an error message for style profiles with an empty or overlong name.
+const ErrInvalidStyleProfile = "style profile name cannot be empty or longer than 100 characters"
+
+// maxStyleProfileNameLength is the maximum length of a style profile name allowed by the style_profiles table.
+const maxStyleProfileNameLength = 100
+
+// CreateStyleProfile validates the request and creates the style profile for the user.
+func (s *StyleProfileUsecaseImpl) CreateStyleProfile(
+	username string, req domain.StyleProfileRequest,
+) (*domain.StyleProfile, error) {
+	name, style, err := normalizeStyleProfileRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	profileId, err := s.Repo.CreateStyleProfile(username, name, style)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create style profile: %w", err)
+	}
+	return s.Repo.GetStyleProfile(username, profileId)
+}
+
+// ListStyleProfiles retrieves all style profiles owned by the user.
+func (s *StyleProfileUsecaseImpl) ListStyleProfiles(username string) ([]domain.StyleProfile, error) {
+	profiles, err := s.Repo.ListStyleProfiles(username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list style profiles: %w", err)
+	}
+	return profiles, nil
+}
+
+// GetStyleProfile retrieves a style profile owned by the user.
+func (s *StyleProfileUsecaseImpl) GetStyleProfile(username string, profileId int) (*domain.StyleProfile, error) {
+	return s.Repo.GetStyleProfile(username, profileId)
+}
+
+// UpdateStyleProfile validates the request, then replaces the name and style of the style profile.
+func (s *StyleProfileUsecaseImpl) UpdateStyleProfile(
+	username string, profileId int, req domain.StyleProfileRequest,
+) error {
+	name, style, err := normalizeStyleProfileRequest(req)
+	if err != nil {
+		return err
+	}
+	return s.Repo.UpdateStyleProfile(username, profileId, name, style)
+}
+
+// DeleteStyleProfile removes a style profile owned by the user.
+func (s *StyleProfileUsecaseImpl) DeleteStyleProfile(username string, profileId int) error {
+	return s.Repo.DeleteStyleProfile(username, profileId)
+}
+
+// normalizeStyleProfileRequest trims and validates the name and style of a style profile request.
+// Returns domain.ErrInvalidStyle if the style is not supported.
+func normalizeStyleProfileRequest(req domain.StyleProfileRequest) (string, domain.TranslationStyle, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > maxStyleProfileNameLength {
+		return "", domain.TranslationStyle{}, errors.New(ErrInvalidStyleProfile)
+	}
+	style, err := domain.ParseTranslationStyle(req.Formality, req.Domain, req.Instructions)
+	if err != nil {
+		return "", domain.TranslationStyle{}, err
+	}
+	return name, style, nil
+}
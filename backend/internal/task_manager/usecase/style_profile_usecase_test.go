@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/oOSomnus/transflate/internal/task_manager/domain"
+	"github.com/oOSomnus/transflate/internal/task_manager/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateStyleProfile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := repository.NewMockStyleProfileRepository(ctrl)
+	usecase := NewStyleProfileUsecase(mockRepo)
+
+	tests := []struct {
+		name      string
+		req       domain.StyleProfileRequest
+		mockSetup func()
+		want      *domain.StyleProfile
+		wantErr   string
+	}{
+		{
+			name: "normalized style",
+			req: domain.StyleProfileRequest{
+				Name: " Contracts ", Formality: "Formal", Domain: " legal", Instructions: " Keep clause numbers. ",
+			},
+			mockSetup: func() {
+				style := domain.TranslationStyle{
+					Formality: domain.FormalityFormal, Domain: domain.DomainLegal, Instructions: "Keep clause numbers.",
+				}
+				mockRepo.EXPECT().CreateStyleProfile("testuser", "Contracts", style).Return(3, nil)
+				mockRepo.EXPECT().GetStyleProfile("testuser", 3).Return(
+					&domain.StyleProfile{ID: 3, Name: "Contracts", TranslationStyle: style}, nil,
+				)
+			},
+			want: &domain.StyleProfile{
+				ID:   3,
+				Name: "Contracts",
+				TranslationStyle: domain.TranslationStyle{
+					Formality: domain.FormalityFormal, Domain: domain.DomainLegal, Instructions: "Keep clause numbers.",
+				},
+			},
+		},
+		{
+			name:      "empty name",
+			req:       domain.StyleProfileRequest{Name: "  ", Formality: "formal"},
+			mockSetup: func() {},
+			wantErr:   ErrInvalidStyleProfile,
+		},
+		{
+			name:      "unknown domain",
+			req:       domain.StyleProfileRequest{Name: "Recipes", Domain: "culinary"},
+			mockSetup: func() {},
+			wantErr:   domain.ErrInvalidStyle.Error(),
+		},
+		{
+			name:      "overlong instructions",
+			req:       domain.StyleProfileRequest{Name: "Verbose", Instructions: strings.Repeat("a", 2001)},
+			mockSetup: func() {},
+			wantErr:   domain.ErrInvalidStyle.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.mockSetup()
+				got, err := usecase.CreateStyleProfile("testuser", tt.req)
+				if tt.wantErr != "" {
+					assert.EqualError(t, err, tt.wantErr)
+				} else {
+					assert.NoError(t, err)
+				}
+				assert.Equal(t, tt.want, got)
+			},
+		)
+	}
+}
@@ -1,6 +1,10 @@
 package usecase
 
 import (
+	"context"
+	"encoding/json"
+	pbt "github.com/oOSomnus/transflate/api/generated/translate"
+	"github.com/oOSomnus/transflate/internal/task_manager/domain"
 	"github.com/oOSomnus/transflate/internal/task_manager/repository"
 	"github.com/oOSomnus/transflate/internal/task_manager/service"
 	"github.com/oOSomnus/transflate/pkg/utils"
@@ -13,33 +17,97 @@ import (
 	"time"
 )
 
-// TaskUsecase defines methods for processing OCR and translations, as well as generating downloadable links from Markdown.
+// TaskUsecase defines the operations on tasks that need neither the OCR nor the translation service, which is all that
+// the API server needs.
+// EnqueueTask stores the input of a task and queues its job for a worker, which loads the input with LoadTaskInput
+// and removes it with DeleteTaskInput once the task is done. CreateDownloadLinkWithMdString uploads the translated
+// document of a task.
+// The pages of a document are reserved from the balance of the user by its OCR, charged by CommitTaskCharge once the
+// task is done, and given back by RefundTask if it fails or is cancelled, or by ReleaseExpiredReservations if it does
+// not finish in time. A charged task that cannot be delivered gets its pages back from ReverseTaskCharge.
+// The OCR text and translation result of a task are kept as artifacts until the task is done, so that RetryTask can
+// queue a failed task again and resume it from its last completed stage.
 type TaskUsecase interface {
-	ProcessOCRAndTranslate(username string, fileContent []byte, lang string) (string, error)
-	CreateDownloadLinkWithMdString(mdString string) (string, error)
+	CreateDownloadLinkWithMdString(result *domain.TranslationResult, output domain.OutputOptions) (string, error)
+	EnqueueTask(job domain.TaskJob, input []byte, extension string) error
+	LoadTaskInput(job domain.TaskJob) ([]byte, error)
+	DeleteTaskInput(job domain.TaskJob) error
+	RefundTask(username string, taskId string, reason string) (int, error)
+	CommitTaskCharge(username string, taskId string) error
+	ReverseTaskCharge(username string, taskId string) (int, error)
+	ReleaseExpiredReservations() (int, error)
+	RetryTask(username string, taskId string) error
+	SaveExtractedText(job domain.TaskJob, text string) error
+	LoadExtractedText(job domain.TaskJob) (string, error)
+	SaveTranslationResult(job domain.TaskJob, result *domain.TranslationResult) error
+	LoadTranslationResult(job domain.TaskJob) (*domain.TranslationResult, error)
 }
 
+// TaskProcessingUsecase extends TaskUsecase with the stages of a task run by the workers.
+// ExtractText and TranslateDocument run the OCR and translation stages of a task separately, so that the terms
+// proposed by ProposeTerms can be reviewed in between, and report the pages recognized and the chunks translated to a
+// progress callback as they go. The stages stop once their context is done, such as when the task is cancelled.
+type TaskProcessingUsecase interface {
+	TaskUsecase
+	ExtractText(
+		ctx context.Context, username string, taskId string, fileContent []byte, lang string,
+		progress service.ProgressFunc,
+	) (string, error)
+	TranslateDocument(
+		ctx context.Context, text string, options domain.TaskOptions, progress service.ProgressFunc,
+	) (*domain.TranslationResult, error)
+	ProposeTerms(ctx context.Context, text string, options domain.TaskOptions) ([]domain.ReviewTerm, error)
+}
+
+// ErrTaskNotRetryable indicates that a failed task cannot be retried, since the job it ran is not known.
+var ErrTaskNotRetryable = errors.New("task cannot be retried")
+
 // TaskUsecaseImpl is the implementation of task-related operations using repository and service dependencies.
-// It manages user tasks, integrates OCR, text translation, and S3 storage services.
+// It manages user tasks and their balance, stores their inputs and artifacts in S3, and queues task jobs.
 type TaskUsecaseImpl struct {
-	ur   repository.UserRepository
-	tr   repository.TaskRepository
-	ocrc service.OCRClient
-	s3s  service.S3StorageService
-	ts   service.TranslateService
+	ur  repository.UserRepository
+	tr  repository.TaskRepository
+	s3s service.S3StorageService
+	tq  repository.TaskQueue
 }
 
 // NewTaskUsecase initializes and returns a new TaskUsecaseImpl instance with required repositories and services.
 func NewTaskUsecase(
-	ur repository.UserRepository, tr repository.TaskRepository, ocrc service.OCRClient, s3s service.S3StorageService,
-	ts service.TranslateService,
+	ur repository.UserRepository, tr repository.TaskRepository, s3s service.S3StorageService, tq repository.TaskQueue,
 ) *TaskUsecaseImpl {
-	return &TaskUsecaseImpl{ur: ur, tr: tr, ocrc: ocrc, s3s: s3s, ts: ts}
+	return &TaskUsecaseImpl{ur: ur, tr: tr, s3s: s3s, tq: tq}
+}
+
+// TaskProcessingUsecaseImpl is the implementation of the task stages run by the workers, integrating the OCR and
+// translation services on top of TaskUsecaseImpl.
+type TaskProcessingUsecaseImpl struct {
+	*TaskUsecaseImpl
+	ocrc service.OCRClient
+	ts   service.TranslateService
 }
 
-// ProcessOCRAndTranslate performs OCR on the input file, subtracts user balance based on pages, and translates the text.
-func (t *TaskUsecaseImpl) ProcessOCRAndTranslate(username string, fileContent []byte, lang string) (string, error) {
-	ocrResponse, err := t.ocrc.ProcessOCR(fileContent, lang)
+// NewTaskProcessingUsecase initializes and returns a new TaskProcessingUsecaseImpl instance with required repositories
+// and services.
+func NewTaskProcessingUsecase(
+	ur repository.UserRepository, tr repository.TaskRepository, ocrc service.OCRClient, s3s service.S3StorageService,
+	ts service.TranslateService, tq repository.TaskQueue,
+) *TaskProcessingUsecaseImpl {
+	return &TaskProcessingUsecaseImpl{TaskUsecaseImpl: NewTaskUsecase(ur, tr, s3s, tq), ocrc: ocrc, ts: ts}
+}
+
+// ExtractText performs OCR on the input file in the given language, reserves user balance based on pages, and returns
+// the cleaned text of the document. The page count of the document is recorded on the task, and the reserved pages are
+// recorded as billed. A task is only billed once even if its OCR runs again when it is resumed, and a task cancelled
+// before its OCR completes is not billed, and ctx.Err() is returned.
+// The pages recognized so far are reported to progress, which may be nil.
+func (t *TaskProcessingUsecaseImpl) ExtractText(
+	ctx context.Context, username string, taskId string, fileContent []byte, lang string,
+	progress service.ProgressFunc,
+) (string, error) {
+	ocrResponse, err := t.ocrc.ProcessOCR(ctx, fileContent, lang, progress)
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
 	if err != nil || ocrResponse == nil {
 		log.Println("Error during OCR processing:", err)
 		return "", errors.New("failed to process OCR")
@@ -48,21 +116,292 @@ func (t *TaskUsecaseImpl) ProcessOCRAndTranslate(username string, fileContent []
 	// Merge and clean OCR response lines
 	cleanedText := mergeAndCleanStrings(ocrResponse.Lines)
 
-	// Decrease user balance based on the number of pages
 	numPages := int(ocrResponse.PageNum)
-	if err = t.ur.DecreaseBalance(username, numPages); err != nil {
-		log.Printf("Error decreasing balance for user %s: %v", username, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	pageCount := map[string]interface{}{domain.TaskFieldPageCount: numPages}
+	if err := t.tr.UpdateTaskFields(ctx, username, taskUUID(username, taskId), pageCount); err != nil {
+		log.Printf("Error recording page count of task %s: %v", taskId, err)
+	}
+
+	// Reserve user balance based on the number of pages
+	reserved, err := t.ur.ReserveBalance(username, taskUUID(username, taskId), numPages, reservationTTL())
+	if err != nil {
+		log.Printf("Error reserving balance for user %s: %v", username, err)
 		return "", err
 	}
+	if reserved {
+		t.recordPages(ctx, username, taskId, domain.TaskFieldPagesBilled, numPages)
+	}
+	return cleanedText, nil
+}
+
+// defaultReservationTTL is how long the pages of a task stay reserved unless billing.reservation-ttl is configured,
+// after which they are released even if the task has not finished. It matches the expiration of task inputs, so that a
+// task cannot be resumed once its reservation has expired.
+// expiredReservationBatch is the number of expired reservations released at once by ReleaseExpiredReservations.
+const (
+	defaultReservationTTL   = 24 * time.Hour
+	expiredReservationBatch = 100
+)
+
+// reservationTTL returns how long the pages of a task stay reserved.
+func reservationTTL() time.Duration {
+	if ttl := viper.GetDuration("billing.reservation-ttl"); ttl > 0 {
+		return ttl
+	}
+	return defaultReservationTTL
+}
+
+// RefundTask releases the pages reserved for a task back to the balance of the user, recording reason in the ledger,
+// and returns the number of pages refunded. The reservation is released atomically, so that the handler cancelling a
+// task and the worker processing it can both refund it without refunding any page twice.
+func (t *TaskUsecaseImpl) RefundTask(username string, taskId string, reason string) (int, error) {
+	pages, err := t.ur.ReleaseReservation(username, taskUUID(username, taskId), reason)
+	if err != nil {
+		log.Printf("Error refunding pages of task %s to user %s: %v", taskId, username, err)
+		return 0, errors.Wrap(err, "failed to refund pages")
+	}
+	if pages > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		t.recordPages(ctx, username, taskId, domain.TaskFieldPagesRefunded, pages)
+	}
+	return pages, nil
+}
+
+// CommitTaskCharge charges the pages reserved for a translated task for good, so that they are no longer released.
+// If the reservation has already been released, such as when it expired during a long term review, the pages are
+// reserved again and charged, so that no translation is delivered for free. Returns
+// repository.ErrInsufficientBalance if the balance no longer covers the pages, in which case the task has not been
+// charged.
+func (t *TaskUsecaseImpl) CommitTaskCharge(username string, taskId string) error {
+	pages, err := t.ur.CommitReservation(username, taskUUID(username, taskId))
+	if errors.Is(err, repository.ErrReservationReleased) {
+		renewed, renewErr := t.ur.RenewReservation(username, taskUUID(username, taskId), reservationTTL())
+		if renewErr != nil {
+			return errors.Wrap(renewErr, "failed to reserve balance")
+		}
+		if renewed > 0 {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			t.recordPages(ctx, username, taskId, domain.TaskFieldPagesBilled, renewed)
+		}
+		pages, err = t.ur.CommitReservation(username, taskUUID(username, taskId))
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to commit charge")
+	}
+	if pages > 0 {
+		log.Printf("Charged %d pages for task %s", pages, taskId)
+	}
+	return nil
+}
+
+// ReverseTaskCharge gives the pages charged for a task back to the balance of the user, such as when the task could not
+// be completed after it was charged, and returns the number of pages refunded. A charge is only reversed once.
+func (t *TaskUsecaseImpl) ReverseTaskCharge(username string, taskId string) (int, error) {
+	pages, err := t.ur.ReverseCharge(username, taskUUID(username, taskId), domain.ReasonTaskNotDelivered)
+	if err != nil {
+		log.Printf("Error reversing charge of task %s to user %s: %v", taskId, username, err)
+		return 0, errors.Wrap(err, "failed to reverse charge")
+	}
+	if pages > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		t.recordPages(ctx, username, taskId, domain.TaskFieldPagesRefunded, pages)
+	}
+	return pages, nil
+}
 
-	// Translate the cleaned text
-	translatedResponse, err := t.ts.TranslateText(cleanedText)
+// ReleaseExpiredReservations releases the reservations of tasks that did not finish before their reservation expired,
+// records their pages as refunded on their tasks, and returns the number of reservations released.
+func (t *TaskUsecaseImpl) ReleaseExpiredReservations() (int, error) {
+	reservations, err := t.ur.ReleaseExpiredReservations(expiredReservationBatch)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to release expired reservations")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for _, reservation := range reservations {
+		log.Printf("Released %d pages of expired reservation of task %s", reservation.Pages, reservation.TaskID)
+		err := t.tr.IncrementTaskField(
+			ctx, reservation.Username, reservation.TaskID, domain.TaskFieldPagesRefunded, reservation.Pages,
+		)
+		// The task may have expired from Redis before its reservation
+		if err != nil && !errors.Is(err, repository.ErrTaskNotFound) {
+			log.Printf("Error recording pages refunded for task %s: %v", reservation.TaskID, err)
+		}
+	}
+	return len(reservations), nil
+}
+
+// recordPages adds pages to the billed or refunded pages of a task, which are shown to the user. The balance has
+// already changed, so any failure is logged without failing the caller.
+func (t *TaskUsecaseImpl) recordPages(ctx context.Context, username string, taskId string, field string, pages int) {
+	if err := t.tr.IncrementTaskField(ctx, username, taskUUID(username, taskId), field, pages); err != nil {
+		log.Printf("Error recording %d %s for task %s: %v", pages, field, taskId, err)
+	}
+}
+
+// taskUUID returns the UUID part of a task ID, which has the form username-UUID.
+func taskUUID(username string, taskId string) string {
+	return strings.TrimPrefix(taskId, username+"-")
+}
+
+// TranslateDocument translates the text of a document with the glossary, source language and consistency mode of the
+// options, reporting glossary terms that were not translated as required in the result. The chunks translated so far
+// are reported to progress, which may be nil.
+func (t *TaskProcessingUsecaseImpl) TranslateDocument(
+	ctx context.Context, text string, options domain.TaskOptions, progress service.ProgressFunc,
+) (*domain.TranslationResult, error) {
+	translatedResponse, err := t.ts.TranslateText(ctx, newTranslateRequest(text, options), progress)
 	if err != nil {
 		log.Println("Error during text translation:", err)
-		return "", err
+		return nil, err
+	}
+
+	return &domain.TranslationResult{
+		Text:               translatedResponse.Lines,
+		GlossaryViolations: fromPbViolations(translatedResponse.GlossaryViolations),
+		MemoryHits:         int(translatedResponse.MemoryHits),
+		ChunkCount:         int(translatedResponse.ChunkCount),
+		Usage:              fromPbUsage(translatedResponse),
+		Segments:           fromPbSegments(translatedResponse.Segments),
+		TermSheet:          fromPbTerms(translatedResponse.TermSheet),
+		QualityIssues:      fromPbQualityIssues(translatedResponse.QualityIssues),
+		RetriedChunks:      int(translatedResponse.RetriedChunks),
+	}, nil
+}
+
+// ProposeTerms asks the translation service for the candidate terms of the text and the translations it proposes for
+// them, so that the user can review them before the document is translated. Terms of the options' glossary are left
+// out, since their translation is already fixed. All proposed terms start unlocked.
+func (t *TaskProcessingUsecaseImpl) ProposeTerms(
+	ctx context.Context, text string, options domain.TaskOptions,
+) ([]domain.ReviewTerm, error) {
+	req := &pbt.TermRequest{Text: text}
+	for _, term := range options.Glossary {
+		req.Glossary = append(req.Glossary, &pbt.GlossaryTerm{Source: term.Source, Target: term.Target})
+	}
+	response, err := t.ts.ProposeTerms(ctx, req)
+	if err != nil {
+		log.Println("Error proposing terms:", err)
+		return nil, err
+	}
+	terms := make([]domain.ReviewTerm, 0, len(response.Terms))
+	for _, term := range response.Terms {
+		terms = append(terms, domain.ReviewTerm{Source: term.Source, Target: term.Target})
+	}
+	return terms, nil
+}
+
+// fromPbSegments converts the aligned source and translation segments reported by the translation service.
+func fromPbSegments(pbSegments []*pbt.Segment) []domain.Segment {
+	if len(pbSegments) == 0 {
+		return nil
+	}
+	segments := make([]domain.Segment, 0, len(pbSegments))
+	for _, segment := range pbSegments {
+		segments = append(
+			segments,
+			domain.Segment{Source: segment.Source, Translation: segment.Translation, Backend: segment.Backend},
+		)
+	}
+	return segments
+}
+
+// fromPbUsage extracts the model, elapsed time, token usage and backends reported by the translation service. The
+// backend of every chunk is taken from its aligned segment.
+func fromPbUsage(result *pbt.TranslateResult) domain.TranslationUsage {
+	usage := domain.TranslationUsage{
+		Model:          result.Model,
+		ElapsedMs:      int64(result.ElapsedMs),
+		Total:          fromPbTokenUsage(result.Usage),
+		Chunks:         make([]domain.TokenUsage, 0, len(result.ChunkUsage)),
+		FallbackChunks: int(result.FallbackChunks),
+	}
+	for _, chunkUsage := range result.ChunkUsage {
+		usage.Chunks = append(usage.Chunks, fromPbTokenUsage(chunkUsage))
+	}
+	for _, segment := range result.Segments {
+		usage.Backends = append(usage.Backends, segment.Backend)
+	}
+	return usage
+}
+
+// fromPbTokenUsage converts a protobuf token usage into a domain token usage, treating a missing usage as zero.
+func fromPbTokenUsage(usage *pbt.TokenUsage) domain.TokenUsage {
+	return domain.TokenUsage{
+		PromptTokens:     int(usage.GetPromptTokens()),
+		CompletionTokens: int(usage.GetCompletionTokens()),
+		TotalTokens:      int(usage.GetTotalTokens()),
 	}
+}
+
+// fromPbTerms converts the terms of a term sheet reported by the translation service into domain glossary terms.
+func fromPbTerms(pbTerms []*pbt.GlossaryTerm) []domain.GlossaryTerm {
+	if len(pbTerms) == 0 {
+		return nil
+	}
+	terms := make([]domain.GlossaryTerm, 0, len(pbTerms))
+	for _, term := range pbTerms {
+		terms = append(terms, domain.GlossaryTerm{Source: term.Source, Target: term.Target})
+	}
+	return terms
+}
 
-	return translatedResponse.Lines, nil
+// newTranslateRequest builds the translation request for text with the glossary, source language, consistency mode and
+// style of the options.
+func newTranslateRequest(text string, options domain.TaskOptions) *pbt.TranslateRequest {
+	req := &pbt.TranslateRequest{Text: text, SourceLang: options.Lang, Consistency: options.Consistency}
+	if options.Style != (domain.TranslationStyle{}) {
+		req.Style = &pbt.TranslationStyle{
+			Formality:    options.Style.Formality,
+			Domain:       options.Style.Domain,
+			Instructions: options.Style.Instructions,
+		}
+	}
+	for _, term := range options.Glossary {
+		req.Glossary = append(req.Glossary, &pbt.GlossaryTerm{Source: term.Source, Target: term.Target})
+	}
+	return req
+}
+
+// fromPbViolations converts glossary violations reported by the translation service into domain violations.
+func fromPbViolations(pbViolations []*pbt.GlossaryViolation) []domain.GlossaryViolation {
+	if len(pbViolations) == 0 {
+		return nil
+	}
+	violations := make([]domain.GlossaryViolation, 0, len(pbViolations))
+	for _, violation := range pbViolations {
+		violations = append(
+			violations, domain.GlossaryViolation{
+				ChunkIndex: int(violation.ChunkIndex),
+				Source:     violation.Source,
+				Target:     violation.Target,
+			},
+		)
+	}
+	return violations
+}
+
+// fromPbQualityIssues converts the quality issues reported by the translation service.
+func fromPbQualityIssues(pbIssues []*pbt.QualityIssue) []domain.QualityIssue {
+	if len(pbIssues) == 0 {
+		return nil
+	}
+	issues := make([]domain.QualityIssue, 0, len(pbIssues))
+	for _, issue := range pbIssues {
+		issues = append(
+			issues, domain.QualityIssue{
+				ChunkIndex: int(issue.ChunkIndex),
+				Check:      issue.Check,
+				Detail:     issue.Detail,
+			},
+		)
+	}
+	return issues
 }
 
 // mergeAndCleanStrings takes a slice of strings, merges them, and cleans the resulting string using text cleaning utils.
@@ -75,20 +414,35 @@ func mergeAndCleanStrings(lines []string) string {
 }
 
 // s3KeyPrefix specifies the prefix path for storing objects in the S3 bucket.
-// tempFilePattern defines the naming pattern for temporary files used in the application.
+// s3InputPrefix specifies the prefix path for storing the inputs of queued tasks in the S3 bucket.
+// s3ArtifactPrefix specifies the prefix path for storing the OCR text and translation result of tasks in the S3 bucket.
+// inputExpirationDays is the expiration set on task inputs and artifacts, which outlive the task only if it fails.
+// tempFilePrefix defines the naming pattern for temporary files used in the application, completed by the extension.
 // presignedURLExpiry sets the expiration duration for presigned URLs to 1 hour.
 const (
-	s3KeyPrefix        = "mds/"
-	tempFilePattern    = "respMd-*.md"
-	presignedURLExpiry = time.Hour
+	s3KeyPrefix         = "mds/"
+	s3InputPrefix       = "inputs/"
+	s3ArtifactPrefix    = "artifacts/"
+	inputExpirationDays = 1
+	tempFilePrefix      = "respMd-*"
+	presignedURLExpiry  = time.Hour
 )
 
-// CreateDownloadLinkWithMdString generates a presigned download link for a file created from the provided markdown string.
-// It temporarily creates a file with the given markdown content, uploads it to S3, and generates a presigned URL for access.
-func (t *TaskUsecaseImpl) CreateDownloadLinkWithMdString(mdString string) (string, error) {
+// CreateDownloadLinkWithMdString generates a presigned download link for a file rendered from the translation result.
+// The output options select the layout, which can place every source segment next to its translation, and whether
+// the file is markdown or HTML. It temporarily creates a file with the rendered content, uploads it to S3, and
+// generates a presigned URL for access.
+func (t *TaskUsecaseImpl) CreateDownloadLinkWithMdString(
+	result *domain.TranslationResult, output domain.OutputOptions,
+) (string, error) {
 	bucketName := viper.GetString("s3.bucket.name")
 
-	mdTmpFile, err := createTempFileWithContent(mdString, tempFilePattern)
+	content, extension, err := RenderDocument(result, output)
+	if err != nil {
+		return "", errors.Wrap(err, "error rendering document")
+	}
+
+	mdTmpFile, err := createTempFileWithContent(content, tempFilePrefix+extension)
 	if err != nil {
 		return "", errors.Wrap(err, "error creating temp file with content")
 	}
@@ -112,6 +466,158 @@ func (t *TaskUsecaseImpl) CreateDownloadLinkWithMdString(mdString string) (strin
 	return downLink, nil
 }
 
+// extractedTextArtifact and translationArtifact name the artifacts holding the OCR text and translation result.
+const (
+	extractedTextArtifact = "text.txt"
+	translationArtifact   = "translation.json"
+)
+
+// EnqueueTask uploads the input of a task to S3 under a key derived from the task ID and the extension of the input,
+// and queues the job referring to it. The input is stored before the job is queued, so a worker never receives a job
+// whose input is missing. The job is also recorded on the task, so that the task can be retried if it fails.
+func (t *TaskUsecaseImpl) EnqueueTask(job domain.TaskJob, input []byte, extension string) error {
+	job.InputKey = s3InputPrefix + job.TaskID + extension
+	if err := t.s3s.PutObject(viper.GetString("s3.bucket.name"), job.InputKey, input, inputExpirationDays); err != nil {
+		return errors.Wrap(err, "failed to store task input")
+	}
+	encoded, err := json.Marshal(job)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode task job")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	fields := map[string]interface{}{domain.TaskFieldJob: string(encoded)}
+	if err := t.tr.UpdateTaskFields(ctx, job.Username, taskUUID(job.Username, job.TaskID), fields); err != nil {
+		return errors.Wrap(err, "failed to record task job")
+	}
+	if err := t.tq.Enqueue(ctx, job); err != nil {
+		return errors.Wrap(err, "failed to enqueue task")
+	}
+	return nil
+}
+
+// RetryTask queues the job last recorded for a failed task again, marked to resume from the artifacts of its completed
+// stages. The input of the job is kept in S3 while the task is not done, so it is not uploaded again. The pages
+// released when the task failed are reserved again, and released again if the job cannot be queued.
+// Returns ErrTaskNotRetryable if no job is recorded for the task, and repository.ErrInsufficientBalance if the balance
+// of the user no longer covers the pages of the task.
+func (t *TaskUsecaseImpl) RetryTask(username string, taskId string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	fields, err := t.tr.GetTaskFields(ctx, username, taskUUID(username, taskId), domain.TaskFieldJob)
+	if err != nil {
+		return errors.Wrap(err, "failed to load task job")
+	}
+	encoded, ok := fields[domain.TaskFieldJob]
+	if !ok {
+		return ErrTaskNotRetryable
+	}
+	var job domain.TaskJob
+	if err := json.Unmarshal([]byte(encoded), &job); err != nil {
+		log.Printf("Error decoding job of task %s: %v", taskId, err)
+		return ErrTaskNotRetryable
+	}
+	// The pages released when the task failed are reserved again, since a resumed task may skip its OCR
+	renewed, err := t.ur.RenewReservation(username, taskUUID(username, taskId), reservationTTL())
+	if err != nil {
+		return errors.Wrap(err, "failed to reserve balance")
+	}
+	if renewed > 0 {
+		t.recordPages(ctx, username, taskId, domain.TaskFieldPagesBilled, renewed)
+	}
+	job.Resume = true
+	if err := t.tq.Enqueue(ctx, job); err != nil {
+		if _, refundErr := t.RefundTask(username, taskId, domain.ReasonRetryNotQueued); refundErr != nil {
+			log.Printf("Error releasing balance of task %s that failed to be queued: %v", taskId, refundErr)
+		}
+		return errors.Wrap(err, "failed to enqueue task")
+	}
+	return nil
+}
+
+// SaveExtractedText stores the OCR text of a task as an artifact, so that a retry does not run OCR again.
+func (t *TaskUsecaseImpl) SaveExtractedText(job domain.TaskJob, text string) error {
+	return t.saveArtifact(job, extractedTextArtifact, []byte(text))
+}
+
+// LoadExtractedText loads the OCR text stored for a task by SaveExtractedText.
+func (t *TaskUsecaseImpl) LoadExtractedText(job domain.TaskJob) (string, error) {
+	content, err := t.loadArtifact(job, extractedTextArtifact)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// SaveTranslationResult stores the translation result of a task as an artifact, so that a retry does not translate
+// the document again.
+func (t *TaskUsecaseImpl) SaveTranslationResult(job domain.TaskJob, result *domain.TranslationResult) error {
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode translation result")
+	}
+	return t.saveArtifact(job, translationArtifact, encoded)
+}
+
+// LoadTranslationResult loads the translation result stored for a task by SaveTranslationResult.
+func (t *TaskUsecaseImpl) LoadTranslationResult(job domain.TaskJob) (*domain.TranslationResult, error) {
+	content, err := t.loadArtifact(job, translationArtifact)
+	if err != nil {
+		return nil, err
+	}
+	var result domain.TranslationResult
+	if err := json.Unmarshal(content, &result); err != nil {
+		return nil, errors.Wrap(err, "failed to decode translation result")
+	}
+	return &result, nil
+}
+
+// artifactKey returns the S3 key of the named artifact of a task.
+func artifactKey(job domain.TaskJob, name string) string {
+	return s3ArtifactPrefix + job.TaskID + "/" + name
+}
+
+// saveArtifact uploads the named artifact of a task to S3, expiring along with the input of the task.
+func (t *TaskUsecaseImpl) saveArtifact(job domain.TaskJob, name string, content []byte) error {
+	key := artifactKey(job, name)
+	if err := t.s3s.PutObject(viper.GetString("s3.bucket.name"), key, content, inputExpirationDays); err != nil {
+		return errors.Wrap(err, "failed to store task artifact")
+	}
+	return nil
+}
+
+// loadArtifact downloads the named artifact of a task from S3.
+func (t *TaskUsecaseImpl) loadArtifact(job domain.TaskJob, name string) ([]byte, error) {
+	content, err := t.s3s.GetObject(viper.GetString("s3.bucket.name"), artifactKey(job, name))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load task artifact")
+	}
+	return content, nil
+}
+
+// LoadTaskInput downloads the input of a queued task from S3.
+func (t *TaskUsecaseImpl) LoadTaskInput(job domain.TaskJob) ([]byte, error) {
+	input, err := t.s3s.GetObject(viper.GetString("s3.bucket.name"), job.InputKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load task input")
+	}
+	return input, nil
+}
+
+// DeleteTaskInput removes the input and the artifacts of a finished task from S3. Every object is removed even if
+// removing another one fails, and the first error is returned.
+func (t *TaskUsecaseImpl) DeleteTaskInput(job domain.TaskJob) error {
+	bucketName := viper.GetString("s3.bucket.name")
+	var firstErr error
+	keys := []string{job.InputKey, artifactKey(job, extractedTextArtifact), artifactKey(job, translationArtifact)}
+	for _, key := range keys {
+		if err := t.s3s.DeleteObject(bucketName, key); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
 // createTempFileWithContent creates a temporary file with the specified content and name pattern, then returns the file.
 // Ensures the file is closed after writing and includes proper error handling for file operations.
 func createTempFileWithContent(content, pattern string) (*os.File, error) {
@@ -1,21 +1,26 @@
 package usecase
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"github.com/golang/mock/gomock"
 	pb "github.com/oOSomnus/transflate/api/generated/ocr"
 	pbt "github.com/oOSomnus/transflate/api/generated/translate"
+	"github.com/oOSomnus/transflate/internal/task_manager/domain"
 	"github.com/oOSomnus/transflate/internal/task_manager/repository"
 	"github.com/oOSomnus/transflate/internal/task_manager/service"
+	"reflect"
 	"testing"
 	"time"
 )
 
-func TestTaskUsecaseImpl_ProcessOCRAndTranslate(t *testing.T) {
+func TestTaskUsecaseImpl_ExtractTextAndTranslateDocument(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockUserRepo := repository.NewMockUserRepository(ctrl)
+	mockTaskRepo := repository.NewMockTaskRepository(ctrl)
 	mockOCRClient := service.NewMockOCRClient(ctrl)
 	mockTranslateService := service.NewMockTranslateService(ctrl)
 
@@ -23,97 +28,307 @@ func TestTaskUsecaseImpl_ProcessOCRAndTranslate(t *testing.T) {
 		name        string
 		username    string
 		fileContent []byte
-		lang        string
+		options     domain.TaskOptions
 		mockSetup   func()
-		expected    string
+		expected    *domain.TranslationResult
 		expectError bool
 	}{
 		{
 			name:        "success",
 			username:    "testuser",
 			fileContent: []byte("filecontent"),
-			lang:        "en",
+			options:     domain.TaskOptions{Lang: "en"},
 			mockSetup: func() {
-				mockOCRClient.EXPECT().ProcessOCR(gomock.Any(), "en").Return(
+				mockOCRClient.EXPECT().ProcessOCR(gomock.Any(), gomock.Any(), "en", gomock.Nil()).Return(
 					&pb.StringListResponse{
 						Lines:   []string{"Hello", "World"},
 						PageNum: uint32(1),
 					}, nil,
 				)
-				mockUserRepo.EXPECT().DecreaseBalance("testuser", 1).Return(nil)
-				mockTranslateService.EXPECT().TranslateText("HelloWorld").Return(
-					&pbt.TranslateResult{Lines: "Translated Text"}, nil,
-				)
+				mockTaskRepo.EXPECT().UpdateTaskFields(
+					gomock.Any(), "testuser", "1", map[string]interface{}{"page_count": 1},
+				).Return(nil)
+				mockUserRepo.EXPECT().ReserveBalance("testuser", "1", 1, defaultReservationTTL).Return(true, nil)
+				mockTaskRepo.EXPECT().IncrementTaskField(gomock.Any(), "testuser", "1", "pages_billed", 1).Return(nil)
+				mockTranslateService.EXPECT().TranslateText(
+					gomock.Any(), &pbt.TranslateRequest{Text: "HelloWorld", SourceLang: "en"}, gomock.Nil(),
+				).Return(&pbt.TranslateResult{Lines: "Translated Text"}, nil)
+			},
+			expected: &domain.TranslationResult{
+				Text:  "Translated Text",
+				Usage: domain.TranslationUsage{Chunks: []domain.TokenUsage{}},
 			},
-			expected:    "Translated Text",
 			expectError: false,
 		},
 		{
 			name:        "ocr error",
 			username:    "testuser",
 			fileContent: []byte("filecontent"),
-			lang:        "en",
+			options:     domain.TaskOptions{Lang: "en"},
 			mockSetup: func() {
-				mockOCRClient.EXPECT().ProcessOCR(gomock.Any(), "en").Return(nil, errors.New("ocr error"))
+				mockOCRClient.EXPECT().ProcessOCR(gomock.Any(), gomock.Any(), "en", gomock.Nil()).Return(
+					nil, errors.New("ocr error"),
+				)
 			},
-			expected:    "",
+			expected:    nil,
 			expectError: true,
 		},
 		{
-			name:        "decrease balance error",
+			name:        "insufficient balance",
 			username:    "testuser",
 			fileContent: []byte("filecontent"),
-			lang:        "en",
+			options:     domain.TaskOptions{Lang: "en"},
 			mockSetup: func() {
-				mockOCRClient.EXPECT().ProcessOCR(gomock.Any(), "en").Return(
+				mockOCRClient.EXPECT().ProcessOCR(gomock.Any(), gomock.Any(), "en", gomock.Nil()).Return(
 					&pb.StringListResponse{
 						Lines:   []string{"Hello", "World"},
 						PageNum: uint32(1),
 					}, nil,
 				)
-				mockUserRepo.EXPECT().DecreaseBalance("testuser", 1).Return(errors.New("decrease balance error"))
+				mockTaskRepo.EXPECT().UpdateTaskFields(
+					gomock.Any(), "testuser", "1", map[string]interface{}{"page_count": 1},
+				).Return(nil)
+				mockUserRepo.EXPECT().ReserveBalance("testuser", "1", 1, defaultReservationTTL).Return(
+					false, repository.ErrInsufficientBalance,
+				)
 			},
-			expected:    "",
+			expected:    nil,
 			expectError: true,
 		},
 		{
 			name:        "translation error",
 			username:    "testuser",
 			fileContent: []byte("filecontent"),
-			lang:        "en",
+			options:     domain.TaskOptions{Lang: "en"},
 			mockSetup: func() {
-				mockOCRClient.EXPECT().ProcessOCR(gomock.Any(), "en").Return(
+				mockOCRClient.EXPECT().ProcessOCR(gomock.Any(), gomock.Any(), "en", gomock.Nil()).Return(
 					&pb.StringListResponse{
 						Lines:   []string{"Hello", "World"},
 						PageNum: uint32(1),
 					}, nil,
 				)
-				mockUserRepo.EXPECT().DecreaseBalance("testuser", 1).Return(nil)
-				mockTranslateService.EXPECT().TranslateText("HelloWorld").Return(nil, errors.New("translation error"))
+				mockTaskRepo.EXPECT().UpdateTaskFields(
+					gomock.Any(), "testuser", "1", map[string]interface{}{"page_count": 1},
+				).Return(nil)
+				mockUserRepo.EXPECT().ReserveBalance("testuser", "1", 1, defaultReservationTTL).Return(true, nil)
+				mockTaskRepo.EXPECT().IncrementTaskField(gomock.Any(), "testuser", "1", "pages_billed", 1).Return(nil)
+				mockTranslateService.EXPECT().TranslateText(
+					gomock.Any(), &pbt.TranslateRequest{Text: "HelloWorld", SourceLang: "en"}, gomock.Nil(),
+				).Return(nil, errors.New("translation error"))
 			},
-			expected:    "",
+			expected:    nil,
 			expectError: true,
 		},
+		{
+			name:        "glossary violations",
+			username:    "testuser",
+			fileContent: []byte("filecontent"),
+			options: domain.TaskOptions{
+				Lang:     "en",
+				Glossary: []domain.GlossaryTerm{{Source: "World", Target: "Welt"}},
+			},
+			mockSetup: func() {
+				mockOCRClient.EXPECT().ProcessOCR(gomock.Any(), gomock.Any(), "en", gomock.Nil()).Return(
+					&pb.StringListResponse{
+						Lines:   []string{"Hello", "World"},
+						PageNum: uint32(1),
+					}, nil,
+				)
+				mockTaskRepo.EXPECT().UpdateTaskFields(
+					gomock.Any(), "testuser", "1", map[string]interface{}{"page_count": 1},
+				).Return(nil)
+				mockUserRepo.EXPECT().ReserveBalance("testuser", "1", 1, defaultReservationTTL).Return(true, nil)
+				mockTaskRepo.EXPECT().IncrementTaskField(gomock.Any(), "testuser", "1", "pages_billed", 1).Return(nil)
+				mockTranslateService.EXPECT().TranslateText(
+					gomock.Any(),
+					&pbt.TranslateRequest{
+						Text:       "HelloWorld",
+						Glossary:   []*pbt.GlossaryTerm{{Source: "World", Target: "Welt"}},
+						SourceLang: "en",
+					},
+					gomock.Nil(),
+				).Return(
+					&pbt.TranslateResult{
+						Lines: "Hallo Erde",
+						GlossaryViolations: []*pbt.GlossaryViolation{
+							{ChunkIndex: 0, Source: "World", Target: "Welt"},
+						},
+						MemoryHits: 1,
+						ChunkCount: 2,
+						Usage:      &pbt.TokenUsage{PromptTokens: 30, CompletionTokens: 12, TotalTokens: 42},
+						ChunkUsage: []*pbt.TokenUsage{
+							{},
+							{PromptTokens: 30, CompletionTokens: 12, TotalTokens: 42},
+						},
+						Model:     "gpt-4-turbo",
+						ElapsedMs: 1500,
+					}, nil,
+				)
+			},
+			expected: &domain.TranslationResult{
+				Text:               "Hallo Erde",
+				GlossaryViolations: []domain.GlossaryViolation{{ChunkIndex: 0, Source: "World", Target: "Welt"}},
+				MemoryHits:         1,
+				ChunkCount:         2,
+				Usage: domain.TranslationUsage{
+					Model:     "gpt-4-turbo",
+					ElapsedMs: 1500,
+					Total:     domain.TokenUsage{PromptTokens: 30, CompletionTokens: 12, TotalTokens: 42},
+					Chunks: []domain.TokenUsage{
+						{},
+						{PromptTokens: 30, CompletionTokens: 12, TotalTokens: 42},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name:        "backend of every chunk",
+			username:    "testuser",
+			fileContent: []byte("filecontent"),
+			options:     domain.TaskOptions{Lang: "en"},
+			mockSetup: func() {
+				mockOCRClient.EXPECT().ProcessOCR(gomock.Any(), gomock.Any(), "en", gomock.Nil()).Return(
+					&pb.StringListResponse{Lines: []string{"Hello", "World"}, PageNum: uint32(1)}, nil,
+				)
+				mockTaskRepo.EXPECT().UpdateTaskFields(
+					gomock.Any(), "testuser", "1", map[string]interface{}{"page_count": 1},
+				).Return(nil)
+				mockUserRepo.EXPECT().ReserveBalance("testuser", "1", 1, defaultReservationTTL).Return(true, nil)
+				mockTaskRepo.EXPECT().IncrementTaskField(gomock.Any(), "testuser", "1", "pages_billed", 1).Return(nil)
+				mockTranslateService.EXPECT().TranslateText(
+					gomock.Any(), &pbt.TranslateRequest{Text: "HelloWorld", SourceLang: "en"}, gomock.Nil(),
+				).Return(
+					&pbt.TranslateResult{
+						Lines:      "你好世界",
+						ChunkCount: 3,
+						ChunkUsage: []*pbt.TokenUsage{{TotalTokens: 5}, {}, {}},
+						Segments: []*pbt.Segment{
+							{Source: "Hello", Translation: "你好", Backend: "openai"},
+							{Source: "World", Translation: "世界", Backend: "deepl"},
+							{Source: "!", Translation: "！"},
+						},
+						FallbackChunks: 1,
+					}, nil,
+				)
+			},
+			expected: &domain.TranslationResult{
+				Text:       "你好世界",
+				ChunkCount: 3,
+				Usage: domain.TranslationUsage{
+					Chunks:         []domain.TokenUsage{{TotalTokens: 5}, {}, {}},
+					Backends:       []string{"openai", "deepl", ""},
+					FallbackChunks: 1,
+				},
+				Segments: []domain.Segment{
+					{Source: "Hello", Translation: "你好", Backend: "openai"},
+					{Source: "World", Translation: "世界", Backend: "deepl"},
+					{Source: "!", Translation: "！"},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name:        "resumed task already reserved",
+			username:    "testuser",
+			fileContent: []byte("filecontent"),
+			options:     domain.TaskOptions{Lang: "en"},
+			mockSetup: func() {
+				mockOCRClient.EXPECT().ProcessOCR(gomock.Any(), gomock.Any(), "en", gomock.Nil()).Return(
+					&pb.StringListResponse{Lines: []string{"Hello", "World"}, PageNum: uint32(1)}, nil,
+				)
+				mockTaskRepo.EXPECT().UpdateTaskFields(
+					gomock.Any(), "testuser", "1", map[string]interface{}{"page_count": 1},
+				).Return(nil)
+				mockUserRepo.EXPECT().ReserveBalance("testuser", "1", 1, defaultReservationTTL).Return(false, nil)
+				mockTranslateService.EXPECT().TranslateText(
+					gomock.Any(), &pbt.TranslateRequest{Text: "HelloWorld", SourceLang: "en"}, gomock.Nil(),
+				).Return(&pbt.TranslateResult{Lines: "Translated Text"}, nil)
+			},
+			expected: &domain.TranslationResult{
+				Text:  "Translated Text",
+				Usage: domain.TranslationUsage{Chunks: []domain.TokenUsage{}},
+			},
+			expectError: false,
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(
 			tc.name, func(t *testing.T) {
 				tc.mockSetup()
-				taskUsecase := &TaskUsecaseImpl{
-					ur:   mockUserRepo,
-					ocrc: mockOCRClient,
-					ts:   mockTranslateService,
+				taskUsecase := &TaskProcessingUsecaseImpl{
+					TaskUsecaseImpl: &TaskUsecaseImpl{ur: mockUserRepo, tr: mockTaskRepo},
+					ocrc:            mockOCRClient,
+					ts:              mockTranslateService,
+				}
+				var result *domain.TranslationResult
+				text, err := taskUsecase.ExtractText(
+					context.Background(), tc.username, tc.username+"-1", tc.fileContent, tc.options.Lang, nil,
+				)
+				if err == nil {
+					result, err = taskUsecase.TranslateDocument(context.Background(), text, tc.options, nil)
 				}
-				result, err := taskUsecase.ProcessOCRAndTranslate(tc.username, tc.fileContent, tc.lang)
 				if tc.expectError && err == nil {
 					t.Errorf("expected error but got none")
 				}
 				if !tc.expectError && err != nil {
 					t.Errorf("did not expect error but got: %v", err)
 				}
-				if result != tc.expected {
-					t.Errorf("expected: %s, got: %s", tc.expected, result)
+				if !reflect.DeepEqual(result, tc.expected) {
+					t.Errorf("expected: %+v, got: %+v", tc.expected, result)
+				}
+			},
+		)
+	}
+}
+
+func TestTaskUsecaseImpl_ProposeTerms(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTranslateService := service.NewMockTranslateService(ctrl)
+	taskUsecase := &TaskProcessingUsecaseImpl{ts: mockTranslateService}
+	options := domain.TaskOptions{Glossary: []domain.GlossaryTerm{{ID: 3, Source: "plaintiff", Target: "原告"}}}
+
+	testCases := []struct {
+		name        string
+		mockSetup   func()
+		expected    []domain.ReviewTerm
+		expectError bool
+	}{
+		{
+			name: "success",
+			mockSetup: func() {
+				mockTranslateService.EXPECT().ProposeTerms(
+					gomock.Any(),
+					&pbt.TermRequest{
+						Text:     "text",
+						Glossary: []*pbt.GlossaryTerm{{Source: "plaintiff", Target: "原告"}},
+					},
+				).Return(&pbt.TermResult{Terms: []*pbt.GlossaryTerm{{Source: "GDPR", Target: "通用数据保护条例"}}}, nil)
+			},
+			expected: []domain.ReviewTerm{{Source: "GDPR", Target: "通用数据保护条例"}},
+		},
+		{
+			name: "translation service error",
+			mockSetup: func() {
+				mockTranslateService.EXPECT().ProposeTerms(gomock.Any(), gomock.Any()).Return(nil, errors.New("unavailable"))
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				tc.mockSetup()
+				terms, err := taskUsecase.ProposeTerms(context.Background(), "text", options)
+				if tc.expectError != (err != nil) {
+					t.Errorf("expected error: %v, got: %v", tc.expectError, err)
+				}
+				if !reflect.DeepEqual(terms, tc.expected) {
+					t.Errorf("expected: %+v, got: %+v", tc.expected, terms)
 				}
 			},
 		)
@@ -128,14 +343,16 @@ func TestTaskUsecaseImpl_CreateDownloadLinkWithMdString(t *testing.T) {
 
 	testCases := []struct {
 		name        string
-		mdString    string
+		result      *domain.TranslationResult
+		output      domain.OutputOptions
 		mockSetup   func()
 		expected    string
 		expectError bool
 	}{
 		{
-			name:     "success",
-			mdString: "markdown content",
+			name:   "success",
+			result: &domain.TranslationResult{Text: "markdown content"},
+			output: domain.OutputOptions{Layout: domain.LayoutTranslation, Format: domain.FormatMarkdown},
 			mockSetup: func() {
 				mockS3Storage.EXPECT().UploadFileToS3(gomock.Any(), gomock.Any(), gomock.Any(), 1).Return(nil)
 				mockS3Storage.EXPECT().GeneratePresignedURL(
@@ -146,8 +363,9 @@ func TestTaskUsecaseImpl_CreateDownloadLinkWithMdString(t *testing.T) {
 			expectError: false,
 		},
 		{
-			name:     "upload error",
-			mdString: "markdown content",
+			name:   "upload error",
+			result: &domain.TranslationResult{Text: "markdown content"},
+			output: domain.OutputOptions{Layout: domain.LayoutTranslation, Format: domain.FormatMarkdown},
 			mockSetup: func() {
 				mockS3Storage.EXPECT().UploadFileToS3(
 					gomock.Any(), gomock.Any(), gomock.Any(), 1,
@@ -157,8 +375,17 @@ func TestTaskUsecaseImpl_CreateDownloadLinkWithMdString(t *testing.T) {
 			expectError: true,
 		},
 		{
-			name:     "generate link error",
-			mdString: "markdown content",
+			name:        "bilingual output without segments",
+			result:      &domain.TranslationResult{Text: "markdown content"},
+			output:      domain.OutputOptions{Layout: domain.LayoutTable, Format: domain.FormatHTML},
+			mockSetup:   func() {},
+			expected:    "",
+			expectError: true,
+		},
+		{
+			name:   "generate link error",
+			result: &domain.TranslationResult{Text: "markdown content"},
+			output: domain.OutputOptions{Layout: domain.LayoutTranslation, Format: domain.FormatMarkdown},
 			mockSetup: func() {
 				mockS3Storage.EXPECT().UploadFileToS3(gomock.Any(), gomock.Any(), gomock.Any(), 1).Return(nil)
 				mockS3Storage.EXPECT().GeneratePresignedURL(
@@ -177,7 +404,7 @@ func TestTaskUsecaseImpl_CreateDownloadLinkWithMdString(t *testing.T) {
 				taskUsecase := &TaskUsecaseImpl{
 					s3s: mockS3Storage,
 				}
-				result, err := taskUsecase.CreateDownloadLinkWithMdString(tc.mdString)
+				result, err := taskUsecase.CreateDownloadLinkWithMdString(tc.result, tc.output)
 				if tc.expectError && err == nil {
 					t.Errorf("expected error but got none")
 				}
@@ -191,3 +418,249 @@ func TestTaskUsecaseImpl_CreateDownloadLinkWithMdString(t *testing.T) {
 		)
 	}
 }
+
+func TestTaskUsecaseImpl_RefundTask(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := repository.NewMockUserRepository(ctrl)
+	mockTaskRepo := repository.NewMockTaskRepository(ctrl)
+	taskUsecase := &TaskUsecaseImpl{ur: mockUserRepo, tr: mockTaskRepo}
+
+	testCases := []struct {
+		name        string
+		mockSetup   func()
+		expected    int
+		expectError bool
+	}{
+		{
+			name: "releases reserved pages",
+			mockSetup: func() {
+				mockUserRepo.EXPECT().ReleaseReservation("testuser", "1", domain.ReasonTaskCancelled).Return(3, nil)
+				mockTaskRepo.EXPECT().IncrementTaskField(gomock.Any(), "testuser", "1", "pages_refunded", 3).Return(nil)
+			},
+			expected: 3,
+		},
+		{
+			name: "nothing left to refund",
+			mockSetup: func() {
+				mockUserRepo.EXPECT().ReleaseReservation("testuser", "1", domain.ReasonTaskCancelled).Return(0, nil)
+			},
+			expected: 0,
+		},
+		{
+			name: "balance update error",
+			mockSetup: func() {
+				mockUserRepo.EXPECT().ReleaseReservation("testuser", "1", domain.ReasonTaskCancelled).Return(
+					0, errors.New("user not found"),
+				)
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				tc.mockSetup()
+				pages, err := taskUsecase.RefundTask("testuser", "testuser-1", domain.ReasonTaskCancelled)
+				if tc.expectError != (err != nil) {
+					t.Errorf("expected error: %v, got: %v", tc.expectError, err)
+				}
+				if pages != tc.expected {
+					t.Errorf("expected: %d, got: %d", tc.expected, pages)
+				}
+			},
+		)
+	}
+}
+
+func TestTaskUsecaseImpl_ReverseTaskCharge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := repository.NewMockUserRepository(ctrl)
+	mockTaskRepo := repository.NewMockTaskRepository(ctrl)
+	taskUsecase := &TaskUsecaseImpl{ur: mockUserRepo, tr: mockTaskRepo}
+
+	testCases := []struct {
+		name        string
+		mockSetup   func()
+		expected    int
+		expectError bool
+	}{
+		{
+			name: "reverses charged pages",
+			mockSetup: func() {
+				mockUserRepo.EXPECT().ReverseCharge("testuser", "1", domain.ReasonTaskNotDelivered).Return(3, nil)
+				mockTaskRepo.EXPECT().IncrementTaskField(gomock.Any(), "testuser", "1", "pages_refunded", 3).Return(nil)
+			},
+			expected: 3,
+		},
+		{
+			name: "nothing charged",
+			mockSetup: func() {
+				mockUserRepo.EXPECT().ReverseCharge("testuser", "1", domain.ReasonTaskNotDelivered).Return(0, nil)
+			},
+			expected: 0,
+		},
+		{
+			name: "balance update error",
+			mockSetup: func() {
+				mockUserRepo.EXPECT().ReverseCharge("testuser", "1", domain.ReasonTaskNotDelivered).Return(
+					0, errors.New("connection refused"),
+				)
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				tc.mockSetup()
+				pages, err := taskUsecase.ReverseTaskCharge("testuser", "testuser-1")
+				if tc.expectError != (err != nil) {
+					t.Errorf("expected error: %v, got: %v", tc.expectError, err)
+				}
+				if pages != tc.expected {
+					t.Errorf("expected: %d, got: %d", tc.expected, pages)
+				}
+			},
+		)
+	}
+}
+
+func TestTaskUsecaseImpl_CommitTaskCharge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := repository.NewMockUserRepository(ctrl)
+	mockTaskRepo := repository.NewMockTaskRepository(ctrl)
+	taskUsecase := &TaskUsecaseImpl{ur: mockUserRepo, tr: mockTaskRepo}
+
+	testCases := []struct {
+		name      string
+		mockSetup func()
+		expected  error
+	}{
+		{
+			name: "commits reserved pages",
+			mockSetup: func() {
+				mockUserRepo.EXPECT().CommitReservation("testuser", "1").Return(3, nil)
+			},
+		},
+		{
+			name: "reserves released pages again",
+			mockSetup: func() {
+				gomock.InOrder(
+					mockUserRepo.EXPECT().CommitReservation("testuser", "1").Return(
+						0, repository.ErrReservationReleased,
+					),
+					mockUserRepo.EXPECT().RenewReservation("testuser", "1", defaultReservationTTL).Return(3, nil),
+					mockTaskRepo.EXPECT().IncrementTaskField(gomock.Any(), "testuser", "1", "pages_billed", 3).Return(nil),
+					mockUserRepo.EXPECT().CommitReservation("testuser", "1").Return(3, nil),
+				)
+			},
+		},
+		{
+			name: "released pages no longer covered",
+			mockSetup: func() {
+				mockUserRepo.EXPECT().CommitReservation("testuser", "1").Return(0, repository.ErrReservationReleased)
+				mockUserRepo.EXPECT().RenewReservation("testuser", "1", defaultReservationTTL).Return(
+					0, repository.ErrInsufficientBalance,
+				)
+			},
+			expected: repository.ErrInsufficientBalance,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				tc.mockSetup()
+				err := taskUsecase.CommitTaskCharge("testuser", "testuser-1")
+				if tc.expected == nil && err != nil {
+					t.Errorf("expected no error, got: %v", err)
+				}
+				if tc.expected != nil && !errors.Is(err, tc.expected) {
+					t.Errorf("expected error: %v, got: %v", tc.expected, err)
+				}
+			},
+		)
+	}
+}
+func TestTaskUsecaseImpl_RetryTask(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := repository.NewMockUserRepository(ctrl)
+	mockTaskRepo := repository.NewMockTaskRepository(ctrl)
+	mockQueue := repository.NewMockTaskQueue(ctrl)
+	taskUsecase := &TaskUsecaseImpl{ur: mockUserRepo, tr: mockTaskRepo, tq: mockQueue}
+
+	job := domain.TaskJob{Kind: domain.JobProcessDocument, TaskID: "testuser-1", Username: "testuser"}
+	encoded, _ := json.Marshal(job)
+	resumed := job
+	resumed.Resume = true
+	errQueue := errors.New("queue unavailable")
+
+	testCases := []struct {
+		name      string
+		mockSetup func()
+		expected  error
+	}{
+		{
+			name: "reserves released pages again",
+			mockSetup: func() {
+				mockTaskRepo.EXPECT().GetTaskFields(gomock.Any(), "testuser", "1", "job").Return(
+					map[string]string{"job": string(encoded)}, nil,
+				)
+				mockUserRepo.EXPECT().RenewReservation("testuser", "1", defaultReservationTTL).Return(2, nil)
+				mockTaskRepo.EXPECT().IncrementTaskField(gomock.Any(), "testuser", "1", "pages_billed", 2).Return(nil)
+				mockQueue.EXPECT().Enqueue(gomock.Any(), resumed).Return(nil)
+			},
+		},
+		{
+			name: "insufficient balance",
+			mockSetup: func() {
+				mockTaskRepo.EXPECT().GetTaskFields(gomock.Any(), "testuser", "1", "job").Return(
+					map[string]string{"job": string(encoded)}, nil,
+				)
+				mockUserRepo.EXPECT().RenewReservation("testuser", "1", defaultReservationTTL).Return(
+					0, repository.ErrInsufficientBalance,
+				)
+			},
+			expected: repository.ErrInsufficientBalance,
+		},
+		{
+			name: "queue error releases pages again",
+			mockSetup: func() {
+				mockTaskRepo.EXPECT().GetTaskFields(gomock.Any(), "testuser", "1", "job").Return(
+					map[string]string{"job": string(encoded)}, nil,
+				)
+				mockUserRepo.EXPECT().RenewReservation("testuser", "1", defaultReservationTTL).Return(2, nil)
+				mockTaskRepo.EXPECT().IncrementTaskField(gomock.Any(), "testuser", "1", "pages_billed", 2).Return(nil)
+				mockQueue.EXPECT().Enqueue(gomock.Any(), resumed).Return(errQueue)
+				mockUserRepo.EXPECT().ReleaseReservation("testuser", "1", domain.ReasonRetryNotQueued).Return(2, nil)
+				mockTaskRepo.EXPECT().IncrementTaskField(gomock.Any(), "testuser", "1", "pages_refunded", 2).Return(nil)
+			},
+			expected: errQueue,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				tc.mockSetup()
+				err := taskUsecase.RetryTask("testuser", "testuser-1")
+				if tc.expected == nil && err != nil {
+					t.Errorf("expected no error, got: %v", err)
+				}
+				if tc.expected != nil && !errors.Is(err, tc.expected) {
+					t.Errorf("expected error: %v, got: %v", tc.expected, err)
+				}
+			},
+		)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"github.com/oOSomnus/transflate/internal/task_manager/domain"
 	"github.com/oOSomnus/transflate/internal/task_manager/repository"
 	"golang.org/x/crypto/bcrypt"
 	"log"
@@ -117,7 +118,7 @@ func (u *UserUsecaseImpl) createUserInRepository(username, password string) erro
 
 // DecreaseBalance decreases the specified balance for a user identified by username and returns an error if the operation fails.
 func (u *UserUsecaseImpl) DecreaseBalance(username string, balance int) error {
-	if err := u.Repo.DecreaseBalance(username, balance); err != nil {
+	if err := u.Repo.DecreaseBalance(username, balance, domain.ReasonBalanceDebited); err != nil {
 		return fmt.Errorf("failed to decrease balance: %w", err)
 	}
 	return nil
@@ -3,6 +3,7 @@ package usecase
 import (
 	"database/sql"
 	"errors"
+	"github.com/oOSomnus/transflate/internal/task_manager/domain"
 	"github.com/oOSomnus/transflate/internal/task_manager/repository"
 	"golang.org/x/crypto/bcrypt"
 	"testing"
@@ -98,8 +99,8 @@ func TestDecreaseBalance(t *testing.T) {
 	mockRepo := repository.NewMockUserRepository(ctrl)
 	usecase := NewUserUsecase(mockRepo)
 
-	mockRepo.EXPECT().DecreaseBalance("validUser", 50).Return(nil).AnyTimes()
-	mockRepo.EXPECT().DecreaseBalance("invalidUser", 50).Return(errors.New("decrease balance error")).AnyTimes()
+	mockRepo.EXPECT().DecreaseBalance("validUser", 50, domain.ReasonBalanceDebited).Return(nil).AnyTimes()
+	mockRepo.EXPECT().DecreaseBalance("invalidUser", 50, domain.ReasonBalanceDebited).Return(errors.New("decrease balance error")).AnyTimes()
 
 	tests := []struct {
 		name     string
@@ -0,0 +1,384 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/oOSomnus/transflate/internal/task_manager/domain"
+	"github.com/oOSomnus/transflate/internal/task_manager/repository"
+	"github.com/oOSomnus/transflate/internal/task_manager/service"
+	"github.com/spf13/viper"
+	"log"
+	"net"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WebhookUsecase defines the operations related to the webhooks of users and the delivery of their notifications.
+// CreateWebhook validates and registers a webhook URL and returns the webhook.
+// ListWebhooks retrieves all webhooks of a user.
+// DeleteWebhook removes a webhook together with its deliveries.
+// GetWebhookSecret returns the secret signing the webhook payloads of a user, creating it on first use.
+// RotateWebhookSecret replaces the secret signing the webhook payloads of a user and returns the new secret.
+// ListDeliveries retrieves the delivery log of a user selected by the filter.
+// ReplayDelivery queues a new delivery of the payload of a delivery and returns the new delivery.
+// NotifyTask queues a delivery of an event of a task to every webhook of its user.
+// DeliverDue attempts the deliveries whose next attempt is due and returns how many were attempted.
+type WebhookUsecase interface {
+	CreateWebhook(username string, req domain.WebhookRequest) (*domain.Webhook, error)
+	ListWebhooks(username string) ([]domain.Webhook, error)
+	DeleteWebhook(username string, webhookId int) error
+	GetWebhookSecret(username string) (string, error)
+	RotateWebhookSecret(username string) (string, error)
+	ListDeliveries(username string, filter domain.DeliveryFilter) ([]domain.WebhookDelivery, error)
+	ReplayDelivery(username string, deliveryId int) (*domain.WebhookDelivery, error)
+	NotifyTask(username string, taskId string, event string) error
+	DeliverDue(ctx context.Context) (int, error)
+}
+
+// WebhookUsecaseImpl is a struct implementing business use cases for webhooks using a WebhookRepository, reading the
+// tasks to notify from a TaskStatusService and posting their payloads with a WebhookSender. The hosts of webhook URLs
+// are resolved with lookupHost.
+type WebhookUsecaseImpl struct {
+	Repo              repository.WebhookRepository
+	TaskStatusService service.TaskStatusService
+	Sender            service.WebhookSender
+	maxAttempts       int
+	lookupHost        func(ctx context.Context, host string) ([]netip.Addr, error)
+}
+
+// NewWebhookUsecase initializes and returns a new instance of WebhookUsecaseImpl giving up deliveries after
+// webhook.max-attempts attempts. The sender may be nil if the instance never delivers notifications.
+func NewWebhookUsecase(
+	r repository.WebhookRepository, tss service.TaskStatusService, sender service.WebhookSender,
+) *WebhookUsecaseImpl {
+	maxAttempts := viper.GetInt("webhook.max-attempts")
+	if maxAttempts <= 0 {
+		maxAttempts = defaultWebhookAttempts
+	}
+	return &WebhookUsecaseImpl{
+		Repo:              r,
+		TaskStatusService: tss,
+		Sender:            sender,
+		maxAttempts:       maxAttempts,
+		lookupHost: func(ctx context.Context, host string) ([]netip.Addr, error) {
+			return net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+		},
+	}
+}
+
+// ErrInvalidWebhook represents an error message for webhook URLs that are not absolute http or https URLs.
+// ErrWebhookAddress represents an error message for webhook URLs whose host is not a public internet address or does
+// not resolve to public internet addresses only.
+// ErrTooManyWebhooks represents an error message for users registering more than maxWebhooks webhooks.
+// ErrInvalidDeliveryStatus represents an error message for delivery log filters with an unknown status.
+const (
+	ErrInvalidWebhook        = "webhook URL must be an absolute http or https URL of at most 2048 characters"
+	ErrWebhookAddress        = "webhook URL must point to a public internet address"
+	ErrTooManyWebhooks       = "too many webhooks, delete one before registering another"
+	ErrInvalidDeliveryStatus = "delivery status must be pending, delivered or failed"
+)
+
+// maxWebhookURLLength is the maximum length of a webhook URL allowed by the webhooks table.
+// maxWebhooks is the maximum number of webhooks of a user.
+// defaultDeliveryLimit and maxDeliveryLimit are the default and maximum number of deliveries listed at once.
+// defaultWebhookAttempts is how many times a delivery is attempted unless webhook.max-attempts is configured.
+// webhookBatchSize is the number of due deliveries claimed and attempted at once.
+// webhookLookupTimeout is how long the host of a webhook URL may take to resolve when the webhook is registered.
+// webhookLease is how long claimed deliveries are hidden from other dispatchers, which must exceed the time taken to
+// attempt them.
+// firstRetryDelay is the delay before the second attempt of a delivery, doubling after each failed attempt up to
+// maxRetryDelay.
+const (
+	maxWebhookURLLength    = 2048
+	maxWebhooks            = 10
+	defaultDeliveryLimit   = 50
+	maxDeliveryLimit       = 200
+	defaultWebhookAttempts = 8
+	webhookBatchSize       = 20
+	webhookLookupTimeout   = 5 * time.Second
+	webhookLease           = 2 * time.Minute
+	firstRetryDelay        = 30 * time.Second
+	maxRetryDelay          = time.Hour
+)
+
+// webhookEventHeader and webhookDeliveryHeader are the headers naming the event and delivery of a webhook payload.
+// webhookTimestampHeader and webhookSignatureHeader are the headers carrying the Unix timestamp at which the payload
+// was signed and its signature, computed by domain.SignWebhookPayload.
+const (
+	webhookEventHeader     = "X-Transflate-Event"
+	webhookDeliveryHeader  = "X-Transflate-Delivery"
+	webhookTimestampHeader = "X-Transflate-Timestamp"
+	webhookSignatureHeader = "X-Transflate-Signature"
+)
+
+// CreateWebhook validates the URL of the request and registers it as a webhook of the user, creating the secret of the
+// user if it has none yet, so that the secret can be read before the first payload is posted.
+func (w *WebhookUsecaseImpl) CreateWebhook(username string, req domain.WebhookRequest) (*domain.Webhook, error) {
+	webhookURL, err := normalizeWebhookURL(req.URL)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.checkWebhookHost(webhookURL); err != nil {
+		return nil, err
+	}
+	webhooks, err := w.Repo.ListWebhooks(username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	if len(webhooks) >= maxWebhooks {
+		return nil, errors.New(ErrTooManyWebhooks)
+	}
+	if _, err := w.GetWebhookSecret(username); err != nil {
+		return nil, err
+	}
+	webhook, err := w.Repo.CreateWebhook(username, webhookURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+// ListWebhooks retrieves all webhooks owned by the user.
+func (w *WebhookUsecaseImpl) ListWebhooks(username string) ([]domain.Webhook, error) {
+	webhooks, err := w.Repo.ListWebhooks(username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+// DeleteWebhook removes a webhook owned by the user together with its deliveries.
+func (w *WebhookUsecaseImpl) DeleteWebhook(username string, webhookId int) error {
+	return w.Repo.DeleteWebhook(username, webhookId)
+}
+
+// GetWebhookSecret returns the webhook secret of the user, generating and storing one if the user has none.
+func (w *WebhookUsecaseImpl) GetWebhookSecret(username string) (string, error) {
+	candidate, err := generateWebhookSecret()
+	if err != nil {
+		return "", err
+	}
+	secret, err := w.Repo.EnsureWebhookSecret(username, candidate)
+	if err != nil {
+		return "", fmt.Errorf("failed to get webhook secret: %w", err)
+	}
+	return secret, nil
+}
+
+// RotateWebhookSecret generates a new webhook secret for the user and stores it in place of the previous one.
+// Payloads posted from now on, including retries and replays of earlier deliveries, are signed with the new secret.
+func (w *WebhookUsecaseImpl) RotateWebhookSecret(username string) (string, error) {
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return "", err
+	}
+	if err := w.Repo.SetWebhookSecret(username, secret); err != nil {
+		return "", fmt.Errorf("failed to rotate webhook secret: %w", err)
+	}
+	return secret, nil
+}
+
+// ListDeliveries validates the filter and retrieves the deliveries of the user it selects, listing defaultDeliveryLimit
+// deliveries unless the filter has a limit, and at most maxDeliveryLimit.
+func (w *WebhookUsecaseImpl) ListDeliveries(
+	username string, filter domain.DeliveryFilter,
+) ([]domain.WebhookDelivery, error) {
+	switch filter.Status {
+	case "", domain.DeliveryPending, domain.DeliveryDelivered, domain.DeliveryFailed:
+	default:
+		return nil, errors.New(ErrInvalidDeliveryStatus)
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultDeliveryLimit
+	}
+	if filter.Limit > maxDeliveryLimit {
+		filter.Limit = maxDeliveryLimit
+	}
+	deliveries, err := w.Repo.ListDeliveries(username, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// ReplayDelivery queues a copy of a delivery of the user, which posts the same payload to the same webhook as soon as
+// possible, and returns the copy. Any delivery can be replayed, whether it was delivered, failed or is still pending.
+func (w *WebhookUsecaseImpl) ReplayDelivery(username string, deliveryId int) (*domain.WebhookDelivery, error) {
+	replayId, err := w.Repo.ReplayDelivery(username, deliveryId)
+	if err != nil {
+		return nil, err
+	}
+	return w.Repo.GetDelivery(username, replayId)
+}
+
+// NotifyTask queues a delivery of the event to every webhook of the user, whose payload carries the detail of the task
+// at the time of the event. The deliveries are attempted by DeliverDue.
+func (w *WebhookUsecaseImpl) NotifyTask(username string, taskId string, event string) error {
+	detail, err := w.TaskStatusService.GetTaskDetail(username, taskId)
+	if err != nil {
+		return fmt.Errorf("failed to get task detail: %w", err)
+	}
+	payload, err := json.Marshal(domain.WebhookPayload{Event: event, Task: detail, Time: time.Now().UTC()})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+	created, err := w.Repo.CreateDeliveries(username, event, detail.ID, payload)
+	if err != nil {
+		return fmt.Errorf("failed to queue webhook deliveries: %w", err)
+	}
+	if created > 0 {
+		log.Printf("Queued %d webhook deliveries of %s for task %s", created, event, taskId)
+	}
+	return nil
+}
+
+// DeliverDue claims up to webhookBatchSize deliveries whose next attempt is due and attempts them concurrently,
+// recording the outcome of every attempt. Returns the number of deliveries claimed. Attempts interrupted because ctx is
+// done are not recorded, and are attempted again once their lease expires.
+func (w *WebhookUsecaseImpl) DeliverDue(ctx context.Context) (int, error) {
+	deliveries, err := w.Repo.ClaimDueDeliveries(webhookBatchSize, webhookLease)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	secrets := make(map[string]string)
+	var wg sync.WaitGroup
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		secret, ok := secrets[delivery.Username]
+		if !ok {
+			secret, err = w.Repo.GetWebhookSecret(delivery.Username)
+			if err != nil {
+				log.Printf("Error getting webhook secret of delivery %d: %v", delivery.ID, err)
+				continue
+			}
+			secrets[delivery.Username] = secret
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.attempt(ctx, delivery, secret)
+		}()
+	}
+	wg.Wait()
+	return len(deliveries), nil
+}
+
+// attempt posts the payload of a delivery signed with the secret and records the outcome. A delivery is delivered once
+// its webhook answers with a 2xx status. Otherwise it is attempted again after a backoff doubling with each attempt,
+// and failed after maxAttempts attempts.
+func (w *WebhookUsecaseImpl) attempt(ctx context.Context, delivery *domain.WebhookDelivery, secret string) {
+	timestamp := time.Now().Unix()
+	headers := map[string]string{
+		"Content-Type":         "application/json",
+		webhookEventHeader:     delivery.Event,
+		webhookDeliveryHeader:  strconv.Itoa(delivery.ID),
+		webhookTimestampHeader: strconv.FormatInt(timestamp, 10),
+		webhookSignatureHeader: "sha256=" + domain.SignWebhookPayload(secret, timestamp, delivery.Payload),
+	}
+	statusCode, err := w.Sender.Send(ctx, delivery.URL, headers, delivery.Payload)
+	if ctx.Err() != nil {
+		return
+	}
+
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastStatusCode = statusCode
+	switch {
+	case err == nil && statusCode >= 200 && statusCode < 300:
+		delivery.Status = domain.DeliveryDelivered
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= w.maxAttempts:
+		delivery.Status = domain.DeliveryFailed
+		delivery.LastError = attemptError(statusCode, err)
+	default:
+		delivery.LastError = attemptError(statusCode, err)
+		delivery.NextAttemptAt = now.Add(retryDelay(delivery.Attempts))
+	}
+	if err := w.Repo.UpdateDelivery(delivery); err != nil {
+		log.Printf("Error recording attempt of webhook delivery %d: %v", delivery.ID, err)
+	}
+}
+
+// attemptError describes why an attempt that got the status code, or the error if no response was received, failed.
+func attemptError(statusCode int, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return fmt.Sprintf("webhook responded with status %d", statusCode)
+}
+
+// retryDelay returns the delay before the next attempt of a delivery that has failed the given number of attempts:
+// firstRetryDelay after the first attempt, doubling after each further attempt up to maxRetryDelay.
+func retryDelay(attempts int) time.Duration {
+	delay := firstRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
+
+// normalizeWebhookURL trims the URL and checks that it is an absolute http or https URL no longer than
+// maxWebhookURLLength. Other schemes are rejected so that webhooks cannot reach files or services through them.
+func normalizeWebhookURL(rawURL string) (string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" || len(rawURL) > maxWebhookURLLength {
+		return "", errors.New(ErrInvalidWebhook)
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", errors.New(ErrInvalidWebhook)
+	}
+	return rawURL, nil
+}
+
+// checkWebhookHost resolves the host of a normalized webhook URL and returns ErrWebhookAddress unless every address it
+// resolves to is public, see service.IsPublicAddress, so that webhooks cannot be pointed at the internal network of the
+// workers. The sender checks the address again when connecting, since the host may resolve differently later.
+func (w *WebhookUsecaseImpl) checkWebhookHost(webhookURL string) error {
+	parsed, err := url.Parse(webhookURL)
+	if err != nil {
+		return errors.New(ErrInvalidWebhook)
+	}
+	host := parsed.Hostname()
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !service.IsPublicAddress(addr) {
+			return errors.New(ErrWebhookAddress)
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), webhookLookupTimeout)
+	defer cancel()
+	addrs, err := w.lookupHost(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		log.Printf("Error resolving webhook host %s: %v", host, err)
+		return errors.New(ErrWebhookAddress)
+	}
+	for _, addr := range addrs {
+		if !service.IsPublicAddress(addr) {
+			return errors.New(ErrWebhookAddress)
+		}
+	}
+	return nil
+}
+
+// generateWebhookSecret returns a new random webhook secret of 32 hex encoded bytes.
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(secret), nil
+}
@@ -0,0 +1,333 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/netip"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/oOSomnus/transflate/internal/task_manager/domain"
+	"github.com/oOSomnus/transflate/internal/task_manager/repository"
+	"github.com/oOSomnus/transflate/internal/task_manager/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateWebhook(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := repository.NewMockWebhookRepository(ctrl)
+	usecase := NewWebhookUsecase(mockRepo, nil, nil)
+	hosts := map[string][]netip.Addr{
+		"example.com":        {netip.MustParseAddr("93.184.215.14")},
+		"internal.example":   {netip.MustParseAddr("93.184.215.14"), netip.MustParseAddr("10.0.0.5")},
+		"metadata.example":   {netip.MustParseAddr("169.254.169.254")},
+		"ipv6-local.example": {netip.MustParseAddr("::ffff:127.0.0.1")},
+	}
+	usecase.lookupHost = func(ctx context.Context, host string) ([]netip.Addr, error) {
+		if addrs, ok := hosts[host]; ok {
+			return addrs, nil
+		}
+		return nil, errors.New("no such host")
+	}
+
+	tests := []struct {
+		name      string
+		url       string
+		mockSetup func()
+		want      *domain.Webhook
+		wantErr   string
+	}{
+		{
+			name: "registers trimmed URL and ensures secret",
+			url:  " https://example.com/hooks/transflate ",
+			mockSetup: func() {
+				mockRepo.EXPECT().ListWebhooks("testuser").Return([]domain.Webhook{}, nil)
+				mockRepo.EXPECT().EnsureWebhookSecret("testuser", gomock.Any()).DoAndReturn(
+					func(username, candidate string) (string, error) {
+						assert.Len(t, candidate, 64)
+						return candidate, nil
+					},
+				)
+				mockRepo.EXPECT().CreateWebhook("testuser", "https://example.com/hooks/transflate").Return(
+					&domain.Webhook{ID: 4, URL: "https://example.com/hooks/transflate"}, nil,
+				)
+			},
+			want: &domain.Webhook{ID: 4, URL: "https://example.com/hooks/transflate"},
+		},
+		{
+			name:      "file scheme",
+			url:       "file:///etc/passwd",
+			mockSetup: func() {},
+			wantErr:   ErrInvalidWebhook,
+		},
+		{
+			name:      "relative URL",
+			url:       "/hooks/transflate",
+			mockSetup: func() {},
+			wantErr:   ErrInvalidWebhook,
+		},
+		{
+			name:      "overlong URL",
+			url:       "https://example.com/" + strings.Repeat("a", maxWebhookURLLength),
+			mockSetup: func() {},
+			wantErr:   ErrInvalidWebhook,
+		},
+		{
+			name:      "loopback address",
+			url:       "http://127.0.0.1:8080/hooks",
+			mockSetup: func() {},
+			wantErr:   ErrWebhookAddress,
+		},
+		{
+			name:      "private address",
+			url:       "http://192.168.1.10/hooks",
+			mockSetup: func() {},
+			wantErr:   ErrWebhookAddress,
+		},
+		{
+			name:      "metadata address",
+			url:       "http://169.254.169.254/latest/meta-data",
+			mockSetup: func() {},
+			wantErr:   ErrWebhookAddress,
+		},
+		{
+			name:      "IPv6 loopback address",
+			url:       "http://[::1]/hooks",
+			mockSetup: func() {},
+			wantErr:   ErrWebhookAddress,
+		},
+		{
+			name:      "host resolving to a private address",
+			url:       "https://internal.example/hooks",
+			mockSetup: func() {},
+			wantErr:   ErrWebhookAddress,
+		},
+		{
+			name:      "host resolving to a link-local address",
+			url:       "https://metadata.example/hooks",
+			mockSetup: func() {},
+			wantErr:   ErrWebhookAddress,
+		},
+		{
+			name:      "host resolving to a mapped loopback address",
+			url:       "https://ipv6-local.example/hooks",
+			mockSetup: func() {},
+			wantErr:   ErrWebhookAddress,
+		},
+		{
+			name:      "unresolvable host",
+			url:       "https://unknown.example/hooks",
+			mockSetup: func() {},
+			wantErr:   ErrWebhookAddress,
+		},
+		{
+			name: "too many webhooks",
+			url:  "https://example.com/hooks",
+			mockSetup: func() {
+				mockRepo.EXPECT().ListWebhooks("testuser").Return(make([]domain.Webhook, maxWebhooks), nil)
+			},
+			wantErr: ErrTooManyWebhooks,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.mockSetup()
+				got, err := usecase.CreateWebhook("testuser", domain.WebhookRequest{URL: tt.url})
+				if tt.wantErr != "" {
+					assert.EqualError(t, err, tt.wantErr)
+					return
+				}
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			},
+		)
+	}
+}
+
+func TestListDeliveries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := repository.NewMockWebhookRepository(ctrl)
+	usecase := NewWebhookUsecase(mockRepo, nil, nil)
+
+	tests := []struct {
+		name      string
+		filter    domain.DeliveryFilter
+		mockSetup func()
+		wantErr   string
+	}{
+		{
+			name:   "default limit",
+			filter: domain.DeliveryFilter{Status: domain.DeliveryFailed},
+			mockSetup: func() {
+				mockRepo.EXPECT().ListDeliveries(
+					"testuser", domain.DeliveryFilter{Status: domain.DeliveryFailed, Limit: defaultDeliveryLimit},
+				).Return([]domain.WebhookDelivery{}, nil)
+			},
+		},
+		{
+			name:   "limit capped",
+			filter: domain.DeliveryFilter{WebhookID: 2, Limit: 1000},
+			mockSetup: func() {
+				mockRepo.EXPECT().ListDeliveries(
+					"testuser", domain.DeliveryFilter{WebhookID: 2, Limit: maxDeliveryLimit},
+				).Return([]domain.WebhookDelivery{}, nil)
+			},
+		},
+		{
+			name:      "unknown status",
+			filter:    domain.DeliveryFilter{Status: "lost"},
+			mockSetup: func() {},
+			wantErr:   ErrInvalidDeliveryStatus,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.mockSetup()
+				_, err := usecase.ListDeliveries("testuser", tt.filter)
+				if tt.wantErr != "" {
+					assert.EqualError(t, err, tt.wantErr)
+					return
+				}
+				assert.NoError(t, err)
+			},
+		)
+	}
+}
+
+func TestNotifyTask(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := repository.NewMockWebhookRepository(ctrl)
+	mockTSS := service.NewMockTaskStatusService(ctrl)
+	usecase := NewWebhookUsecase(mockRepo, mockTSS, nil)
+
+	detail := &domain.TaskDetail{ID: "uuid", Filename: "doc.pdf", Status: service.Done, Link: "https://s3/link"}
+	mockTSS.EXPECT().GetTaskDetail("testuser", "testuser-uuid").Return(detail, nil)
+	mockRepo.EXPECT().CreateDeliveries("testuser", domain.WebhookTaskCompleted, "uuid", gomock.Any()).DoAndReturn(
+		func(username, event, taskId string, payload []byte) (int, error) {
+			var decoded domain.WebhookPayload
+			assert.NoError(t, json.Unmarshal(payload, &decoded))
+			assert.Equal(t, domain.WebhookTaskCompleted, decoded.Event)
+			assert.Equal(t, detail, decoded.Task)
+			return 1, nil
+		},
+	)
+
+	assert.NoError(t, usecase.NotifyTask("testuser", "testuser-uuid", domain.WebhookTaskCompleted))
+}
+
+func TestDeliverDue(t *testing.T) {
+	payload := []byte(`{"event":"task.completed"}`)
+
+	tests := []struct {
+		name       string
+		attempts   int
+		statusCode int
+		sendErr    error
+		check      func(t *testing.T, delivery *domain.WebhookDelivery)
+	}{
+		{
+			name:       "accepted",
+			statusCode: 204,
+			check: func(t *testing.T, delivery *domain.WebhookDelivery) {
+				assert.Equal(t, domain.DeliveryDelivered, delivery.Status)
+				assert.Equal(t, 1, delivery.Attempts)
+				assert.NotNil(t, delivery.DeliveredAt)
+			},
+		},
+		{
+			name:       "server error retried",
+			attempts:   2,
+			statusCode: 503,
+			check: func(t *testing.T, delivery *domain.WebhookDelivery) {
+				assert.Equal(t, domain.DeliveryPending, delivery.Status)
+				assert.Equal(t, 3, delivery.Attempts)
+				assert.Equal(t, 503, delivery.LastStatusCode)
+				assert.Equal(t, "webhook responded with status 503", delivery.LastError)
+				assert.WithinDuration(t, time.Now().Add(2*time.Minute), delivery.NextAttemptAt, 5*time.Second)
+			},
+		},
+		{
+			name:     "given up after last attempt",
+			attempts: defaultWebhookAttempts - 1,
+			sendErr:  errors.New("connection refused"),
+			check: func(t *testing.T, delivery *domain.WebhookDelivery) {
+				assert.Equal(t, domain.DeliveryFailed, delivery.Status)
+				assert.Equal(t, defaultWebhookAttempts, delivery.Attempts)
+				assert.Equal(t, "connection refused", delivery.LastError)
+				assert.Nil(t, delivery.DeliveredAt)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				mockRepo := repository.NewMockWebhookRepository(ctrl)
+				mockSender := service.NewMockWebhookSender(ctrl)
+				usecase := NewWebhookUsecase(mockRepo, nil, mockSender)
+
+				delivery := domain.WebhookDelivery{
+					ID: 7, URL: "https://example.com/hooks", Username: "testuser", Event: domain.WebhookTaskCompleted,
+					Payload: payload, Status: domain.DeliveryPending, Attempts: tt.attempts,
+				}
+				mockRepo.EXPECT().ClaimDueDeliveries(webhookBatchSize, webhookLease).Return(
+					[]domain.WebhookDelivery{delivery}, nil,
+				)
+				mockRepo.EXPECT().GetWebhookSecret("testuser").Return("secret", nil)
+				mockSender.EXPECT().Send(gomock.Any(), "https://example.com/hooks", gomock.Any(), payload).DoAndReturn(
+					func(ctx context.Context, url string, headers map[string]string, body []byte) (int, error) {
+						assert.Equal(t, "7", headers[webhookDeliveryHeader])
+						assert.Equal(t, domain.WebhookTaskCompleted, headers[webhookEventHeader])
+						timestamp, err := strconv.ParseInt(headers[webhookTimestampHeader], 10, 64)
+						assert.NoError(t, err)
+						signature := "sha256=" + domain.SignWebhookPayload("secret", timestamp, body)
+						assert.Equal(t, signature, headers[webhookSignatureHeader])
+						return tt.statusCode, tt.sendErr
+					},
+				)
+				mockRepo.EXPECT().UpdateDelivery(gomock.Any()).Do(
+					func(delivery *domain.WebhookDelivery) {
+						tt.check(t, delivery)
+					},
+				).Return(nil)
+
+				attempted, err := usecase.DeliverDue(context.Background())
+				assert.NoError(t, err)
+				assert.Equal(t, 1, attempted)
+			},
+		)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: 30 * time.Second},
+		{attempts: 2, want: time.Minute},
+		{attempts: 4, want: 4 * time.Minute},
+		{attempts: 20, want: time.Hour},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, retryDelay(tt.attempts))
+	}
+}
@@ -0,0 +1,58 @@
+package worker
+
+import (
+	"context"
+	"github.com/oOSomnus/transflate/internal/task_manager/usecase"
+	"github.com/spf13/viper"
+	"log"
+	"time"
+)
+
+// defaultSweepInterval is how often expired balance reservations are looked for unless billing.sweep-interval is
+// configured.
+const defaultSweepInterval = time.Minute
+
+// ReservationSweeper releases the balance reservations of tasks that did not finish before their reservation expired,
+// such as tasks whose job was lost, so that their pages are not held forever. Reservations are released with
+// SKIP LOCKED, so any number of sweepers can run next to each other.
+type ReservationSweeper struct {
+	Usecase       usecase.TaskUsecase
+	sweepInterval time.Duration
+}
+
+// NewReservationSweeper initializes a ReservationSweeper looking for expired reservations every
+// billing.sweep-interval.
+func NewReservationSweeper(u usecase.TaskUsecase) *ReservationSweeper {
+	s := &ReservationSweeper{
+		Usecase:       u,
+		sweepInterval: viper.GetDuration("billing.sweep-interval"),
+	}
+	if s.sweepInterval <= 0 {
+		s.sweepInterval = defaultSweepInterval
+	}
+	return s
+}
+
+// Run releases the expired reservations every sweep interval until ctx is done. As long as expired reservations are
+// found, the next ones are looked for right away.
+func (s *ReservationSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.sweepInterval)
+	defer ticker.Stop()
+	for {
+		for ctx.Err() == nil {
+			released, err := s.Usecase.ReleaseExpiredReservations()
+			if err != nil {
+				log.Printf("Error releasing expired reservations: %v", err)
+				break
+			}
+			if released == 0 {
+				break
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package worker
+
+import (
+	"context"
+	"github.com/oOSomnus/transflate/internal/task_manager/usecase"
+	"github.com/spf13/viper"
+	"log"
+	"time"
+)
+
+// defaultPollInterval is how often due webhook deliveries are looked for unless webhook.poll-interval is configured.
+const defaultPollInterval = 5 * time.Second
+
+// WebhookDispatcher attempts the webhook deliveries queued for completed and failed tasks. Deliveries are claimed
+// with a lease, so any number of dispatchers can run next to each other, and the deliveries of a dispatcher that
+// crashes are attempted by another once their lease expires.
+type WebhookDispatcher struct {
+	Webhooks     usecase.WebhookUsecase
+	pollInterval time.Duration
+}
+
+// NewWebhookDispatcher initializes a WebhookDispatcher looking for due deliveries every webhook.poll-interval.
+func NewWebhookDispatcher(u usecase.WebhookUsecase) *WebhookDispatcher {
+	d := &WebhookDispatcher{
+		Webhooks:     u,
+		pollInterval: viper.GetDuration("webhook.poll-interval"),
+	}
+	if d.pollInterval <= 0 {
+		d.pollInterval = defaultPollInterval
+	}
+	return d
+}
+
+// Run attempts the due deliveries every poll interval until ctx is done. As long as due deliveries are found, the
+// next ones are looked for right away, so that a backlog of deliveries does not wait for the next poll.
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+	for {
+		for ctx.Err() == nil {
+			attempted, err := d.Webhooks.DeliverDue(ctx)
+			if err != nil {
+				log.Printf("Error delivering webhooks: %v", err)
+				break
+			}
+			if attempted == 0 {
+				break
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,571 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/oOSomnus/transflate/internal/task_manager/domain"
+	"github.com/oOSomnus/transflate/internal/task_manager/repository"
+	"github.com/oOSomnus/transflate/internal/task_manager/service"
+	"github.com/oOSomnus/transflate/internal/task_manager/usecase"
+	"github.com/spf13/viper"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// defaultConcurrency is the number of jobs a worker runs at once unless worker.concurrency is configured.
+// defaultReclaimIdle is how long a job may go without a sign of life from its worker before another worker takes it
+// over, unless worker.reclaim-idle is configured.
+// defaultMaxDeliveries is how many times a job is delivered before it is given up, unless worker.max-deliveries is
+// configured, so that a job crashing every worker does not circulate forever.
+// readBlock is how long a worker waits for a new job before looking for abandoned jobs again.
+// cancelPollInterval is how often a worker checks whether the task it is processing has been cancelled.
+// progressInterval is the least time between two progress updates of a stage, so that large documents do not flood the
+// task status with updates. The first and last updates of a stage are always recorded.
+const (
+	defaultConcurrency   = 2
+	defaultReclaimIdle   = 5 * time.Minute
+	defaultMaxDeliveries = 3
+	readBlock            = 5 * time.Second
+	cancelPollInterval   = 2 * time.Second
+	progressInterval     = time.Second
+)
+
+// reasonTooManyDeliveries, reasonStatus, reasonInput, reasonUnknownJob, reasonOCR, reasonInsufficientBalance,
+// reasonReview, reasonTranslation, reasonUpload and reasonCharge are the reasons recorded on tasks failing at each step
+// of their pipeline, which are shown to their user.
+const (
+	reasonTooManyDeliveries   = "Processing the task was interrupted too many times"
+	reasonStatus              = "The status of the task could not be updated"
+	reasonInput               = "The uploaded document is no longer available"
+	reasonUnknownJob          = "The task cannot be processed by this version of the service"
+	reasonOCR                 = "The text of the document could not be recognized"
+	reasonInsufficientBalance = "The balance does not cover the pages of the document"
+	reasonReview              = "The term review could not be started"
+	reasonTranslation         = "The document could not be translated"
+	reasonUpload              = "The translated document could not be uploaded"
+	reasonCharge              = "The pages of the document could not be charged"
+)
+
+// Worker consumes task jobs from the TaskQueue and runs their pipeline: OCR, translation, upload and delivery of the
+// download link, or OCR and term proposals for tasks whose terms are reviewed first.
+// A job is acknowledged once its pipeline has finished, whether it succeeded or not, so the jobs of a worker that
+// crashes stay in the queue and are reclaimed by another worker. The pipeline of a task cancelled by the user is
+// aborted. The pages reserved for a task are charged once it completes and refunded if it fails or is cancelled. The
+// webhooks of the user are notified when a task completes or fails.
+type Worker struct {
+	Queue             repository.TaskQueue
+	Usecase           usecase.TaskProcessingUsecase
+	TaskStatusService service.TaskStatusService
+	Webhooks          usecase.WebhookUsecase
+	consumer          string
+	concurrency       int
+	reclaimIdle       time.Duration
+	maxDeliveries     int64
+}
+
+// NewWorker initializes a Worker with a unique consumer name, running worker.concurrency jobs at once and reclaiming
+// jobs idle for worker.reclaim-idle, up to worker.max-deliveries deliveries per job.
+func NewWorker(
+	q repository.TaskQueue, u usecase.TaskProcessingUsecase, tss service.TaskStatusService,
+	webhooks usecase.WebhookUsecase,
+) *Worker {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "worker"
+	}
+	w := &Worker{
+		Queue:             q,
+		Usecase:           u,
+		TaskStatusService: tss,
+		Webhooks:          webhooks,
+		consumer:          hostname + "-" + uuid.New().String()[:8],
+		concurrency:       viper.GetInt("worker.concurrency"),
+		reclaimIdle:       viper.GetDuration("worker.reclaim-idle"),
+		maxDeliveries:     viper.GetInt64("worker.max-deliveries"),
+	}
+	if w.concurrency <= 0 {
+		w.concurrency = defaultConcurrency
+	}
+	if w.reclaimIdle <= 0 {
+		w.reclaimIdle = defaultReclaimIdle
+	}
+	if w.maxDeliveries <= 0 {
+		w.maxDeliveries = defaultMaxDeliveries
+	}
+	return w
+}
+
+// Run processes jobs until ctx is done, then waits for the jobs in progress to finish. Only the cancellation of their
+// task aborts the jobs in progress.
+func (w *Worker) Run(ctx context.Context) {
+	log.Printf("Worker %s processing up to %d jobs at once", w.consumer, w.concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < w.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.loop(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// loop processes one job after another until ctx is done, backing off for a second after queue errors.
+func (w *Worker) loop(ctx context.Context) {
+	for ctx.Err() == nil {
+		jobs, err := w.next(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("Error reading task queue: %v", err)
+			}
+			select {
+			case <-time.After(time.Second):
+			case <-ctx.Done():
+			}
+			continue
+		}
+		for _, job := range jobs {
+			w.handle(ctx, job)
+		}
+	}
+}
+
+// next returns a job abandoned by another worker if there is one, and otherwise waits for a new job.
+func (w *Worker) next(ctx context.Context) ([]domain.QueuedJob, error) {
+	jobs, err := w.Queue.Reclaim(ctx, w.consumer, w.reclaimIdle, 1)
+	if err != nil || len(jobs) > 0 {
+		return jobs, err
+	}
+	return w.Queue.Read(ctx, w.consumer, 1, readBlock)
+}
+
+// handle runs the pipeline of a job and acknowledges it. While the pipeline runs the job is touched regularly so that
+// no other worker reclaims it, and the pipeline is aborted if its task is cancelled. A job delivered more than
+// maxDeliveries times fails its task without running again.
+func (w *Worker) handle(ctx context.Context, queued domain.QueuedJob) {
+	job := queued.Job
+	if queued.Deliveries > w.maxDeliveries {
+		log.Printf("Giving up task %s after %d deliveries", job.TaskID, queued.Deliveries-1)
+		w.markFailed(job, reasonTooManyDeliveries)
+		w.ack(queued)
+		return
+	}
+	if queued.Deliveries > 1 {
+		log.Printf("Resuming task %s abandoned by another worker", job.TaskID)
+	}
+
+	jobCtx, cancelJob := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go w.keepAlive(ctx, queued.ID, done)
+	go w.watchCancellation(job, cancelJob, done)
+	w.process(jobCtx, job)
+	close(done)
+	cancelJob()
+	if w.isCancelled(job) {
+		w.settleCancelledTask(job)
+	}
+	w.ack(queued)
+}
+
+// watchCancellation calls cancelJob as soon as the task of the job is cancelled, checking its status every
+// cancelPollInterval until done is closed.
+func (w *Worker) watchCancellation(job domain.TaskJob, cancelJob context.CancelFunc, done <-chan struct{}) {
+	ticker := time.NewTicker(cancelPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if w.isCancelled(job) {
+				log.Printf("Task %s cancelled, aborting its pipeline", job.TaskID)
+				cancelJob()
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// isCancelled reports whether the task of the job has been cancelled, logging any error checking its status.
+func (w *Worker) isCancelled(job domain.TaskJob) bool {
+	status, err := w.TaskStatusService.GetTaskStatus(job.Username, job.TaskID)
+	if err != nil {
+		log.Printf("Error checking status of task %s: %v", job.TaskID, err)
+		return false
+	}
+	return status == service.Cancelled
+}
+
+// settleCancelledTask refunds the pages billed for a cancelled task that have not been refunded when it was cancelled,
+// such as pages billed while the cancellation was on its way, and removes the input of its job.
+func (w *Worker) settleCancelledTask(job domain.TaskJob) {
+	pages, err := w.Usecase.RefundTask(job.Username, job.TaskID, domain.ReasonTaskCancelled)
+	if err != nil {
+		log.Printf("Error refunding cancelled task %s: %v", job.TaskID, err)
+	} else if pages > 0 {
+		log.Printf("Refunded %d pages of cancelled task %s", pages, job.TaskID)
+	}
+	w.deleteInput(job)
+}
+
+// keepAlive touches the job with the given ID three times per reclaim interval until done is closed.
+func (w *Worker) keepAlive(ctx context.Context, id string, done <-chan struct{}) {
+	ticker := time.NewTicker(w.reclaimIdle / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := w.Queue.Touch(ctx, w.consumer, id); err != nil {
+				log.Printf("Error touching job %s: %v", id, err)
+			}
+		case <-done:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// ack acknowledges a finished job, which removes it from the queue. It is done even if the worker is stopping, since
+// the job has already run.
+func (w *Worker) ack(queued domain.QueuedJob) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := w.Queue.Ack(ctx, queued.ID); err != nil {
+		log.Printf("Error acknowledging job %s: %v", queued.ID, err)
+	}
+}
+
+// process runs the pipeline of a job until ctx is done, recording its progress and outcome in the task status and the
+// start and end of every stage it runs on the task. A document is in the RecognizingText status until its text is
+// recognized, and in the Translating status while it is translated, during which the pages recognized and the chunks
+// translated are recorded on the task. The OCR text and translation result are stored as they complete, so that a
+// resumed job continues from its last completed stage. The input and artifacts of the job are removed once they are no
+// longer needed, and kept if the task fails.
+func (w *Worker) process(ctx context.Context, job domain.TaskJob) {
+	initialStatus := service.Translating
+	if job.Kind == domain.JobProcessDocument {
+		initialStatus = service.RecognizingText
+	}
+	if !w.setStatus(job, initialStatus) {
+		return
+	}
+
+	var err error
+	transResponse := w.resumeTranslation(job)
+	if transResponse == nil {
+		text, ok := w.sourceText(ctx, job)
+		if !ok {
+			return
+		}
+		if job.Kind == domain.JobProcessDocument && job.Options.ReviewTerms {
+			if w.startTermReview(ctx, job, text) {
+				w.deleteInput(job)
+			}
+			return
+		}
+		if initialStatus != service.Translating && !w.setStatus(job, service.Translating) {
+			return
+		}
+		w.startStage(job.TaskID, domain.StageTranslation)
+		progress := w.reportProgress(job.TaskID, domain.ProgressChunks)
+		transResponse, err = w.Usecase.TranslateDocument(ctx, text, job.Options, progress)
+		if ctx.Err() != nil {
+			log.Printf("Pipeline of task %s aborted", job.TaskID)
+			return
+		}
+		if err != nil {
+			log.Printf("Error processing translation: %v", err)
+			w.markFailed(job, reasonTranslation)
+			return
+		}
+		w.endStage(job.TaskID, domain.StageTranslation)
+		if err := w.Usecase.SaveTranslationResult(job, transResponse); err != nil {
+			log.Printf("Error storing translation of task %s: %v", job.TaskID, err)
+		}
+	}
+	if w.deliverTranslation(job.Username, job.TaskID, transResponse, job.Options.Output) {
+		w.deleteInput(job)
+	}
+}
+
+// setStatus moves the task of a job to the given status. Returns false if the task has been cancelled or has already
+// finished, whose pipeline is then skipped, or if its status could not be updated, which fails the task.
+func (w *Worker) setStatus(job domain.TaskJob, status int) bool {
+	err := w.TaskStatusService.UpdateTaskStatus(job.Username, job.TaskID, status)
+	if errors.Is(err, service.ErrTaskCancelled) || errors.Is(err, service.ErrTaskFinished) {
+		log.Printf("Skipping cancelled or finished task %s", job.TaskID)
+		return false
+	}
+	if err != nil {
+		log.Printf("Error updating task status: %v", err)
+		w.markFailed(job, reasonStatus)
+		return false
+	}
+	return true
+}
+
+// resumeTranslation returns the translation result stored by the previous run of a resumed job, or nil if the job is
+// not resumed or its document has not been translated yet.
+func (w *Worker) resumeTranslation(job domain.TaskJob) *domain.TranslationResult {
+	if !job.Resume {
+		return nil
+	}
+	result, err := w.Usecase.LoadTranslationResult(job)
+	if err != nil {
+		log.Printf("No translation stored for task %s: %v", job.TaskID, err)
+		return nil
+	}
+	log.Printf("Resuming task %s from its stored translation", job.TaskID)
+	return result
+}
+
+// sourceText returns the text to translate for a job: the reviewed text given as input of JobTranslateReviewed, or the
+// OCR text of the PDF given as input of JobProcessDocument, which is stored once extracted. A resumed job reuses the OCR
+// text stored by its previous run, so that it is neither extracted nor billed again. Returns false if the task has
+// failed or its pipeline has been aborted.
+func (w *Worker) sourceText(ctx context.Context, job domain.TaskJob) (string, bool) {
+	if job.Kind != domain.JobProcessDocument && job.Kind != domain.JobTranslateReviewed {
+		log.Printf("Unknown kind %q of job of task %s", job.Kind, job.TaskID)
+		w.markFailed(job, reasonUnknownJob)
+		return "", false
+	}
+	if job.Kind == domain.JobProcessDocument && job.Resume {
+		text, err := w.Usecase.LoadExtractedText(job)
+		if err == nil {
+			log.Printf("Resuming task %s from its stored OCR text", job.TaskID)
+			return text, true
+		}
+		log.Printf("No OCR text stored for task %s: %v", job.TaskID, err)
+	}
+	input, err := w.Usecase.LoadTaskInput(job)
+	if err != nil {
+		log.Printf("Error loading task input: %v", err)
+		w.markFailed(job, reasonInput)
+		return "", false
+	}
+	if job.Kind == domain.JobTranslateReviewed {
+		return string(input), true
+	}
+
+	w.startStage(job.TaskID, domain.StageOCR)
+	progress := w.reportProgress(job.TaskID, domain.ProgressPages)
+	text, err := w.Usecase.ExtractText(ctx, job.Username, job.TaskID, input, job.Options.Lang, progress)
+	if ctx.Err() != nil {
+		log.Printf("Pipeline of task %s aborted", job.TaskID)
+		return "", false
+	}
+	if err != nil {
+		log.Printf("Error processing OCR: %v", err)
+		if errors.Is(err, repository.ErrInsufficientBalance) {
+			w.markFailed(job, reasonInsufficientBalance)
+		} else {
+			w.markFailed(job, reasonOCR)
+		}
+		return "", false
+	}
+	w.endStage(job.TaskID, domain.StageOCR)
+	if err := w.Usecase.SaveExtractedText(job, text); err != nil {
+		log.Printf("Error storing OCR text of task %s: %v", job.TaskID, err)
+	}
+	return text, true
+}
+
+// deliverTranslation records the reports of a finished translation on the task, uploads the rendered document and
+// completes the task with its download link, charging its reserved pages and notifying the webhooks of the user.
+// The task can no longer be cancelled once it is Uploading, and its charge is reversed if it cannot be completed after
+// it has been charged. Reports that fail to be recorded are logged without failing the task. Returns whether the task
+// has been completed.
+func (w *Worker) deliverTranslation(
+	username string, taskId string, transResponse *domain.TranslationResult, output domain.OutputOptions,
+) bool {
+	if len(transResponse.GlossaryViolations) > 0 {
+		// Violations are reported, not fatal: the translation is still delivered
+		err := w.TaskStatusService.UpdateTaskGlossaryViolations(taskId, transResponse.GlossaryViolations)
+		if err != nil {
+			log.Printf("Error updating task glossary violations: %v", err)
+		}
+	}
+	if transResponse.ChunkCount > 0 {
+		err := w.TaskStatusService.UpdateTaskMemoryHits(taskId, transResponse.MemoryHits, transResponse.ChunkCount)
+		if err != nil {
+			log.Printf("Error updating task memory hits: %v", err)
+		}
+	}
+	if err := w.TaskStatusService.UpdateTaskUsage(taskId, transResponse.Usage); err != nil {
+		log.Printf("Error updating task usage: %v", err)
+	}
+	if len(transResponse.TermSheet) > 0 {
+		if err := w.TaskStatusService.UpdateTaskTermSheet(taskId, transResponse.TermSheet); err != nil {
+			log.Printf("Error updating task term sheet: %v", err)
+		}
+	}
+	if len(transResponse.QualityIssues) > 0 || transResponse.RetriedChunks > 0 {
+		err := w.TaskStatusService.UpdateTaskQuality(taskId, transResponse.QualityIssues, transResponse.RetriedChunks)
+		if err != nil {
+			log.Printf("Error updating task quality issues: %v", err)
+		}
+	}
+	err := w.TaskStatusService.UpdateTaskStatus(username, taskId, service.Uploading)
+	if errors.Is(err, service.ErrTaskCancelled) || errors.Is(err, service.ErrTaskFinished) {
+		log.Printf("Skipping delivery of cancelled or finished task %s", taskId)
+		return false
+	}
+	if err != nil {
+		log.Printf("Error updating task status: %v", err)
+		w.markTaskFailed(username, taskId, reasonStatus)
+		return false
+	}
+	w.startStage(taskId, domain.StageUpload)
+	// Create download link
+	downLink, err := w.Usecase.CreateDownloadLinkWithMdString(transResponse, output)
+	if err != nil {
+		log.Printf("Error generating download link: %v", err)
+		w.markTaskFailed(username, taskId, reasonUpload)
+		return false
+	}
+	w.endStage(taskId, domain.StageUpload)
+	// The task is charged before its link is shown, so that its result is never delivered without being paid for
+	if err := w.Usecase.CommitTaskCharge(username, taskId); err != nil {
+		log.Printf("Error charging task %s: %v", taskId, err)
+		if errors.Is(err, repository.ErrInsufficientBalance) {
+			w.markTaskFailed(username, taskId, reasonInsufficientBalance)
+		} else {
+			w.markTaskFailed(username, taskId, reasonCharge)
+		}
+		return false
+	}
+	if err = w.TaskStatusService.UpdateTaskDownloadLink(taskId, downLink); err != nil {
+		log.Printf("Error updating task download link: %v", err)
+		w.markChargedTaskFailed(username, taskId, reasonUpload)
+		return false
+	}
+	err = w.TaskStatusService.UpdateTaskStatus(username, taskId, service.Done)
+	if err != nil {
+		log.Printf("Error updating task status: %v", err)
+		w.markChargedTaskFailed(username, taskId, reasonStatus)
+		return false
+	}
+	w.notify(username, taskId, domain.WebhookTaskCompleted)
+	return true
+}
+
+// startTermReview proposes the terms of the OCR text of a document and pauses the task in the WaitingForReview status
+// until the user confirms the review. If no terms can be proposed the review starts empty, so that the user can still
+// add terms of their own. Returns whether the review has been started.
+func (w *Worker) startTermReview(ctx context.Context, job domain.TaskJob, text string) bool {
+	terms, err := w.Usecase.ProposeTerms(ctx, text, job.Options)
+	if ctx.Err() != nil {
+		log.Printf("Pipeline of task %s aborted", job.TaskID)
+		return false
+	}
+	if err != nil {
+		log.Printf("Error proposing terms, starting an empty review: %v", err)
+		terms = []domain.ReviewTerm{}
+	}
+	review := &domain.TermReview{Text: text, Options: job.Options, Terms: terms}
+	if err := w.TaskStatusService.SaveTermReview(job.TaskID, review); err != nil {
+		log.Printf("Error saving term review: %v", err)
+		w.markFailed(job, reasonReview)
+		return false
+	}
+	if err := w.TaskStatusService.UpdateTaskStatus(job.Username, job.TaskID, service.WaitingForReview); err != nil {
+		log.Printf("Error updating task status: %v", err)
+		w.markFailed(job, reasonStatus)
+		return false
+	}
+	w.startStage(job.TaskID, domain.StageReview)
+	return true
+}
+
+// deleteInput removes the input of a job whose task no longer needs it, logging any failure.
+func (w *Worker) deleteInput(job domain.TaskJob) {
+	if err := w.Usecase.DeleteTaskInput(job); err != nil {
+		log.Printf("Error deleting input of task %s: %v", job.TaskID, err)
+	}
+}
+
+// startStage records the start of a stage of a task. The stage timings are informative only, so any failure is
+// logged without failing the task.
+func (w *Worker) startStage(taskId string, stage string) {
+	if err := w.TaskStatusService.StartTaskStage(taskId, stage); err != nil {
+		log.Printf("Error recording start of stage %s of task %s: %v", stage, taskId, err)
+	}
+}
+
+// endStage records the end of a stage of a task, logging any failure like startStage.
+func (w *Worker) endStage(taskId string, stage string) {
+	if err := w.TaskStatusService.EndTaskStage(taskId, stage); err != nil {
+		log.Printf("Error recording end of stage %s of task %s: %v", stage, taskId, err)
+	}
+}
+
+// reportProgress returns a progress callback recording the units of a stage of a task done so far, at most once per
+// progressInterval apart from the first and last updates. Progress is informative only, so any failure is logged
+// without failing the task.
+func (w *Worker) reportProgress(taskId string, unit string) service.ProgressFunc {
+	var last time.Time
+	return func(done, total int) {
+		if done < total && !last.IsZero() && time.Since(last) < progressInterval {
+			return
+		}
+		last = time.Now()
+		if err := w.TaskStatusService.UpdateTaskProgress(taskId, unit, done, total); err != nil {
+			log.Printf("Error recording %s progress of task %s: %v", unit, taskId, err)
+		}
+	}
+}
+
+// markFailed sets the task of a job to the error state with the given reason.
+func (w *Worker) markFailed(job domain.TaskJob, reason string) {
+	w.markTaskFailed(job.Username, job.TaskID, reason)
+}
+
+// markTaskFailed refunds the pages reserved for the task, then sets the task to the error state, records the reason it
+// failed and notifies the webhooks of the user, logging any error encountered during the update. The pages are refunded
+// before the task can be seen as failed, since a failed task may be retried right away and its retry reserves the
+// pages again only if they have been released. A task that is already done, failed or cancelled keeps its status and
+// gets no reason, and no notification.
+func (w *Worker) markTaskFailed(username string, taskId string, reason string) {
+	pages, err := w.Usecase.RefundTask(username, taskId, domain.ReasonTaskFailed)
+	if err != nil {
+		log.Printf("Error refunding failed task %s: %v", taskId, err)
+	} else if pages > 0 {
+		log.Printf("Refunded %d pages of failed task %s", pages, taskId)
+	}
+	err = w.TaskStatusService.UpdateTaskStatus(username, taskId, service.Error)
+	if errors.Is(err, service.ErrTaskCancelled) || errors.Is(err, service.ErrTaskFinished) {
+		return
+	}
+	if err != nil {
+		log.Printf("Error updating task status: %v", err)
+		return
+	}
+	if err := w.TaskStatusService.UpdateTaskError(taskId, reason); err != nil {
+		log.Printf("Error updating task error: %v", err)
+	}
+	w.notify(username, taskId, domain.WebhookTaskFailed)
+}
+
+// markChargedTaskFailed reverses the charge of a task that could not be completed after it was charged, then fails it
+// like markTaskFailed.
+func (w *Worker) markChargedTaskFailed(username string, taskId string, reason string) {
+	pages, err := w.Usecase.ReverseTaskCharge(username, taskId)
+	if err != nil {
+		log.Printf("Error reversing charge of task %s: %v", taskId, err)
+	} else if pages > 0 {
+		log.Printf("Reversed charge of %d pages of undelivered task %s", pages, taskId)
+	}
+	w.markTaskFailed(username, taskId, reason)
+}
+
+// notify queues the notification of an event of a task to the webhooks of the user. The outcome of the task does not
+// depend on its notification, so any failure is logged without failing the task.
+func (w *Worker) notify(username string, taskId string, event string) {
+	if err := w.Webhooks.NotifyTask(username, taskId, event); err != nil {
+		log.Printf("Error notifying webhooks of %s for task %s: %v", event, taskId, err)
+	}
+}
@@ -0,0 +1,284 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/oOSomnus/transflate/internal/task_manager/domain"
+	"github.com/oOSomnus/transflate/internal/task_manager/repository"
+	"github.com/oOSomnus/transflate/internal/task_manager/service"
+	"github.com/oOSomnus/transflate/internal/task_manager/usecase"
+)
+
+func TestHandle(t *testing.T) {
+	job := domain.TaskJob{
+		Kind: domain.JobProcessDocument, TaskID: "testuser-1", Username: "testuser", InputKey: "inputs/testuser-1.pdf",
+	}
+	resumed := job
+	resumed.Resume = true
+	result := &domain.TranslationResult{}
+
+	tests := []struct {
+		name       string
+		deliveries int64
+		job        domain.TaskJob
+		mockSetup  func(u *usecase.MockTaskProcessingUsecase, tss *service.MockTaskStatusService)
+		notified   string
+	}{
+		{
+			name:       "translated and delivered",
+			deliveries: 1,
+			mockSetup: func(u *usecase.MockTaskProcessingUsecase, tss *service.MockTaskStatusService) {
+				gomock.InOrder(
+					tss.EXPECT().UpdateTaskStatus("testuser", "testuser-1", service.RecognizingText).Return(nil),
+					u.EXPECT().LoadTaskInput(job).Return([]byte("%PDF"), nil),
+					tss.EXPECT().StartTaskStage("testuser-1", domain.StageOCR).Return(nil),
+					u.EXPECT().ExtractText(
+						gomock.Any(), "testuser", "testuser-1", []byte("%PDF"), "", gomock.Any(),
+					).DoAndReturn(
+						func(
+							ctx context.Context, username, taskId string, input []byte, lang string,
+							progress service.ProgressFunc,
+						) (string, error) {
+							// The update of the second page comes too soon after the first and is skipped
+							progress(0, 3)
+							progress(1, 3)
+							progress(3, 3)
+							return "text", nil
+						},
+					),
+					tss.EXPECT().UpdateTaskProgress("testuser-1", domain.ProgressPages, 0, 3).Return(nil),
+					tss.EXPECT().UpdateTaskProgress("testuser-1", domain.ProgressPages, 3, 3).Return(nil),
+					tss.EXPECT().EndTaskStage("testuser-1", domain.StageOCR).Return(nil),
+					u.EXPECT().SaveExtractedText(job, "text").Return(nil),
+					tss.EXPECT().UpdateTaskStatus("testuser", "testuser-1", service.Translating).Return(nil),
+					tss.EXPECT().StartTaskStage("testuser-1", domain.StageTranslation).Return(nil),
+					u.EXPECT().TranslateDocument(gomock.Any(), "text", job.Options, gomock.Any()).Return(result, nil),
+					tss.EXPECT().EndTaskStage("testuser-1", domain.StageTranslation).Return(nil),
+					u.EXPECT().SaveTranslationResult(job, result).Return(nil),
+					tss.EXPECT().UpdateTaskUsage("testuser-1", result.Usage).Return(nil),
+					tss.EXPECT().UpdateTaskStatus("testuser", "testuser-1", service.Uploading).Return(nil),
+					tss.EXPECT().StartTaskStage("testuser-1", domain.StageUpload).Return(nil),
+					u.EXPECT().CreateDownloadLinkWithMdString(result, job.Options.Output).Return("link", nil),
+					tss.EXPECT().EndTaskStage("testuser-1", domain.StageUpload).Return(nil),
+					u.EXPECT().CommitTaskCharge("testuser", "testuser-1").Return(nil),
+					tss.EXPECT().UpdateTaskDownloadLink("testuser-1", "link").Return(nil),
+					tss.EXPECT().UpdateTaskStatus("testuser", "testuser-1", service.Done).Return(nil),
+					u.EXPECT().DeleteTaskInput(job).Return(nil),
+					tss.EXPECT().GetTaskStatus("testuser", "testuser-1").Return(service.Done, nil),
+				)
+			},
+			notified: domain.WebhookTaskCompleted,
+		},
+		{
+			name:       "failed OCR keeps input",
+			deliveries: 2,
+			mockSetup: func(u *usecase.MockTaskProcessingUsecase, tss *service.MockTaskStatusService) {
+				gomock.InOrder(
+					tss.EXPECT().UpdateTaskStatus("testuser", "testuser-1", service.RecognizingText).Return(nil),
+					u.EXPECT().LoadTaskInput(job).Return([]byte("%PDF"), nil),
+					tss.EXPECT().StartTaskStage("testuser-1", domain.StageOCR).Return(nil),
+					u.EXPECT().ExtractText(gomock.Any(), "testuser", "testuser-1", []byte("%PDF"), "", gomock.Any()).Return(
+						"", errors.New("ocr failed"),
+					),
+					u.EXPECT().RefundTask("testuser", "testuser-1", domain.ReasonTaskFailed).Return(3, nil),
+					tss.EXPECT().UpdateTaskStatus("testuser", "testuser-1", service.Error).Return(nil),
+					tss.EXPECT().UpdateTaskError("testuser-1", reasonOCR).Return(nil),
+					tss.EXPECT().GetTaskStatus("testuser", "testuser-1").Return(service.Error, nil),
+				)
+			},
+			notified: domain.WebhookTaskFailed,
+		},
+		{
+			name:       "resumed from stored OCR text",
+			deliveries: 1,
+			job:        resumed,
+			mockSetup: func(u *usecase.MockTaskProcessingUsecase, tss *service.MockTaskStatusService) {
+				gomock.InOrder(
+					tss.EXPECT().UpdateTaskStatus("testuser", "testuser-1", service.RecognizingText).Return(nil),
+					u.EXPECT().LoadTranslationResult(resumed).Return(nil, errors.New("not found")),
+					u.EXPECT().LoadExtractedText(resumed).Return("text", nil),
+					tss.EXPECT().UpdateTaskStatus("testuser", "testuser-1", service.Translating).Return(nil),
+					tss.EXPECT().StartTaskStage("testuser-1", domain.StageTranslation).Return(nil),
+					u.EXPECT().TranslateDocument(gomock.Any(), "text", job.Options, gomock.Any()).Return(result, nil),
+					tss.EXPECT().EndTaskStage("testuser-1", domain.StageTranslation).Return(nil),
+					u.EXPECT().SaveTranslationResult(resumed, result).Return(nil),
+					tss.EXPECT().UpdateTaskUsage("testuser-1", result.Usage).Return(nil),
+					tss.EXPECT().UpdateTaskStatus("testuser", "testuser-1", service.Uploading).Return(nil),
+					tss.EXPECT().StartTaskStage("testuser-1", domain.StageUpload).Return(nil),
+					u.EXPECT().CreateDownloadLinkWithMdString(result, job.Options.Output).Return("link", nil),
+					tss.EXPECT().EndTaskStage("testuser-1", domain.StageUpload).Return(nil),
+					u.EXPECT().CommitTaskCharge("testuser", "testuser-1").Return(nil),
+					tss.EXPECT().UpdateTaskDownloadLink("testuser-1", "link").Return(nil),
+					tss.EXPECT().UpdateTaskStatus("testuser", "testuser-1", service.Done).Return(nil),
+					u.EXPECT().DeleteTaskInput(resumed).Return(nil),
+					tss.EXPECT().GetTaskStatus("testuser", "testuser-1").Return(service.Done, nil),
+				)
+			},
+			notified: domain.WebhookTaskCompleted,
+		},
+		{
+			name:       "charge not covered by balance",
+			deliveries: 1,
+			job:        resumed,
+			mockSetup: func(u *usecase.MockTaskProcessingUsecase, tss *service.MockTaskStatusService) {
+				gomock.InOrder(
+					tss.EXPECT().UpdateTaskStatus("testuser", "testuser-1", service.RecognizingText).Return(nil),
+					u.EXPECT().LoadTranslationResult(resumed).Return(result, nil),
+					tss.EXPECT().UpdateTaskUsage("testuser-1", result.Usage).Return(nil),
+					tss.EXPECT().UpdateTaskStatus("testuser", "testuser-1", service.Uploading).Return(nil),
+					tss.EXPECT().StartTaskStage("testuser-1", domain.StageUpload).Return(nil),
+					u.EXPECT().CreateDownloadLinkWithMdString(result, job.Options.Output).Return("link", nil),
+					tss.EXPECT().EndTaskStage("testuser-1", domain.StageUpload).Return(nil),
+					u.EXPECT().CommitTaskCharge("testuser", "testuser-1").Return(repository.ErrInsufficientBalance),
+					u.EXPECT().RefundTask("testuser", "testuser-1", domain.ReasonTaskFailed).Return(0, nil),
+					tss.EXPECT().UpdateTaskStatus("testuser", "testuser-1", service.Error).Return(nil),
+					tss.EXPECT().UpdateTaskError("testuser-1", reasonInsufficientBalance).Return(nil),
+					tss.EXPECT().GetTaskStatus("testuser", "testuser-1").Return(service.Error, nil),
+				)
+			},
+			notified: domain.WebhookTaskFailed,
+		},
+		{
+			name:       "cancelled before upload",
+			deliveries: 1,
+			job:        resumed,
+			mockSetup: func(u *usecase.MockTaskProcessingUsecase, tss *service.MockTaskStatusService) {
+				gomock.InOrder(
+					tss.EXPECT().UpdateTaskStatus("testuser", "testuser-1", service.RecognizingText).Return(nil),
+					u.EXPECT().LoadTranslationResult(resumed).Return(result, nil),
+					tss.EXPECT().UpdateTaskUsage("testuser-1", result.Usage).Return(nil),
+					tss.EXPECT().UpdateTaskStatus("testuser", "testuser-1", service.Uploading).Return(
+						service.ErrTaskCancelled,
+					),
+					tss.EXPECT().GetTaskStatus("testuser", "testuser-1").Return(service.Cancelled, nil),
+					u.EXPECT().RefundTask("testuser", "testuser-1", domain.ReasonTaskCancelled).Return(3, nil),
+					u.EXPECT().DeleteTaskInput(resumed).Return(nil),
+				)
+			},
+		},
+		{
+			name:       "download link not recorded",
+			deliveries: 1,
+			job:        resumed,
+			mockSetup: func(u *usecase.MockTaskProcessingUsecase, tss *service.MockTaskStatusService) {
+				gomock.InOrder(
+					tss.EXPECT().UpdateTaskStatus("testuser", "testuser-1", service.RecognizingText).Return(nil),
+					u.EXPECT().LoadTranslationResult(resumed).Return(result, nil),
+					tss.EXPECT().UpdateTaskUsage("testuser-1", result.Usage).Return(nil),
+					tss.EXPECT().UpdateTaskStatus("testuser", "testuser-1", service.Uploading).Return(nil),
+					tss.EXPECT().StartTaskStage("testuser-1", domain.StageUpload).Return(nil),
+					u.EXPECT().CreateDownloadLinkWithMdString(result, job.Options.Output).Return("link", nil),
+					tss.EXPECT().EndTaskStage("testuser-1", domain.StageUpload).Return(nil),
+					u.EXPECT().CommitTaskCharge("testuser", "testuser-1").Return(nil),
+					tss.EXPECT().UpdateTaskDownloadLink("testuser-1", "link").Return(errors.New("redis down")),
+					u.EXPECT().ReverseTaskCharge("testuser", "testuser-1").Return(3, nil),
+					u.EXPECT().RefundTask("testuser", "testuser-1", domain.ReasonTaskFailed).Return(0, nil),
+					tss.EXPECT().UpdateTaskStatus("testuser", "testuser-1", service.Error).Return(nil),
+					tss.EXPECT().UpdateTaskError("testuser-1", reasonUpload).Return(nil),
+					tss.EXPECT().GetTaskStatus("testuser", "testuser-1").Return(service.Error, nil),
+				)
+			},
+			notified: domain.WebhookTaskFailed,
+		},
+		{
+			name:       "done not recorded",
+			deliveries: 1,
+			job:        resumed,
+			mockSetup: func(u *usecase.MockTaskProcessingUsecase, tss *service.MockTaskStatusService) {
+				gomock.InOrder(
+					tss.EXPECT().UpdateTaskStatus("testuser", "testuser-1", service.RecognizingText).Return(nil),
+					u.EXPECT().LoadTranslationResult(resumed).Return(result, nil),
+					tss.EXPECT().UpdateTaskUsage("testuser-1", result.Usage).Return(nil),
+					tss.EXPECT().UpdateTaskStatus("testuser", "testuser-1", service.Uploading).Return(nil),
+					tss.EXPECT().StartTaskStage("testuser-1", domain.StageUpload).Return(nil),
+					u.EXPECT().CreateDownloadLinkWithMdString(result, job.Options.Output).Return("link", nil),
+					tss.EXPECT().EndTaskStage("testuser-1", domain.StageUpload).Return(nil),
+					u.EXPECT().CommitTaskCharge("testuser", "testuser-1").Return(nil),
+					tss.EXPECT().UpdateTaskDownloadLink("testuser-1", "link").Return(nil),
+					tss.EXPECT().UpdateTaskStatus("testuser", "testuser-1", service.Done).Return(
+						errors.New(service.ErrorAccessingData),
+					),
+					u.EXPECT().ReverseTaskCharge("testuser", "testuser-1").Return(3, nil),
+					u.EXPECT().RefundTask("testuser", "testuser-1", domain.ReasonTaskFailed).Return(0, nil),
+					tss.EXPECT().UpdateTaskStatus("testuser", "testuser-1", service.Error).Return(nil),
+					tss.EXPECT().UpdateTaskError("testuser-1", reasonStatus).Return(nil),
+					tss.EXPECT().GetTaskStatus("testuser", "testuser-1").Return(service.Error, nil),
+				)
+			},
+			notified: domain.WebhookTaskFailed,
+		},
+		{
+			name:       "insufficient balance",
+			deliveries: 1,
+			mockSetup: func(u *usecase.MockTaskProcessingUsecase, tss *service.MockTaskStatusService) {
+				gomock.InOrder(
+					tss.EXPECT().UpdateTaskStatus("testuser", "testuser-1", service.RecognizingText).Return(nil),
+					u.EXPECT().LoadTaskInput(job).Return([]byte("%PDF"), nil),
+					tss.EXPECT().StartTaskStage("testuser-1", domain.StageOCR).Return(nil),
+					u.EXPECT().ExtractText(gomock.Any(), "testuser", "testuser-1", []byte("%PDF"), "", gomock.Any()).Return(
+						"", repository.ErrInsufficientBalance,
+					),
+					u.EXPECT().RefundTask("testuser", "testuser-1", domain.ReasonTaskFailed).Return(0, nil),
+					tss.EXPECT().UpdateTaskStatus("testuser", "testuser-1", service.Error).Return(nil),
+					tss.EXPECT().UpdateTaskError("testuser-1", reasonInsufficientBalance).Return(nil),
+					tss.EXPECT().GetTaskStatus("testuser", "testuser-1").Return(service.Error, nil),
+				)
+			},
+			notified: domain.WebhookTaskFailed,
+		},
+		{
+			name:       "cancelled before processing",
+			deliveries: 1,
+			mockSetup: func(u *usecase.MockTaskProcessingUsecase, tss *service.MockTaskStatusService) {
+				gomock.InOrder(
+					tss.EXPECT().UpdateTaskStatus("testuser", "testuser-1", service.RecognizingText).Return(
+						service.ErrTaskCancelled,
+					),
+					tss.EXPECT().GetTaskStatus("testuser", "testuser-1").Return(service.Cancelled, nil),
+					u.EXPECT().RefundTask("testuser", "testuser-1", domain.ReasonTaskCancelled).Return(0, nil),
+					u.EXPECT().DeleteTaskInput(job).Return(nil),
+				)
+			},
+		},
+		{
+			name:       "too many deliveries",
+			deliveries: defaultMaxDeliveries + 1,
+			mockSetup: func(u *usecase.MockTaskProcessingUsecase, tss *service.MockTaskStatusService) {
+				u.EXPECT().RefundTask("testuser", "testuser-1", domain.ReasonTaskFailed).Return(0, nil)
+				tss.EXPECT().UpdateTaskStatus("testuser", "testuser-1", service.Error).Return(nil)
+				tss.EXPECT().UpdateTaskError("testuser-1", reasonTooManyDeliveries).Return(nil)
+			},
+			notified: domain.WebhookTaskFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				mockQueue := repository.NewMockTaskQueue(ctrl)
+				mockUsecase := usecase.NewMockTaskProcessingUsecase(ctrl)
+				mockStatus := service.NewMockTaskStatusService(ctrl)
+				mockWebhooks := usecase.NewMockWebhookUsecase(ctrl)
+				tt.mockSetup(mockUsecase, mockStatus)
+				if tt.notified != "" {
+					mockWebhooks.EXPECT().NotifyTask("testuser", "testuser-1", tt.notified).Return(nil)
+				}
+				// Every job is acknowledged once handled, whatever its outcome
+				mockQueue.EXPECT().Ack(gomock.Any(), "1-0").Return(nil)
+
+				queued := domain.QueuedJob{ID: "1-0", Job: job, Deliveries: tt.deliveries}
+				if tt.job.TaskID != "" {
+					queued.Job = tt.job
+				}
+				w := NewWorker(mockQueue, mockUsecase, mockStatus, mockWebhooks)
+				w.handle(context.Background(), queued)
+			},
+		)
+	}
+}
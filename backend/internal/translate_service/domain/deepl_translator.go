@@ -0,0 +1,162 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-resty/resty/v2"
+	"html"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultDeepLURL is the DeepL API used unless another base URL is configured, such as the free API at
+// https://api-free.deepl.com.
+const defaultDeepLURL = "https://api.deepl.com"
+
+// ErrStructureLost is returned when a machine translation of a structured chunk drops or duplicates one of its
+// placeholders, which the chunk cannot be rendered without.
+var ErrStructureLost = errors.New("translation lost the placeholders of the structured text")
+
+// segmentMarkupPattern matches the placeholders and line break markers within a segment of a structured chunk.
+// segmentMarkupTagPattern matches the XML tag standing for a marker in a text sent to DeepL.
+var (
+	segmentMarkupPattern    = regexp.MustCompile(`⟦([A-Z]+\d*)⟧`)
+	segmentMarkupTagPattern = regexp.MustCompile(`<m id="([A-Z]+\d*)"\s*/>`)
+)
+
+// deepLRequest is the body of a request to the translate endpoint of the DeepL API.
+type deepLRequest struct {
+	Text               []string `json:"text"`
+	TargetLang         string   `json:"target_lang"`
+	Context            string   `json:"context,omitempty"`
+	PreserveFormatting bool     `json:"preserve_formatting"`
+	TagHandling        string   `json:"tag_handling,omitempty"`
+}
+
+// deepLResponse is the body of a successful response of the translate endpoint of the DeepL API.
+type deepLResponse struct {
+	Translations []struct {
+		Text string `json:"text"`
+	} `json:"translations"`
+}
+
+// DeepLTranslator translates with the DeepL machine translation API.
+// Unlike the LLM backends it cannot follow instructions, so the glossary, term sheet, style and feedback of a request
+// are ignored and only its previous context is passed along; the glossary and quality checks of the usecase still
+// apply to its translations. The segments of a structured chunk are translated as separate texts whose markers are
+// protected as XML tags, so that the structure of the chunk survives the translation.
+type DeepLTranslator struct {
+	client *resty.Client
+}
+
+// NewDeepLTranslator initializes a DeepLTranslator for the DeepL API at baseURL, which defaults to the paid API,
+// authenticating with apiKey.
+func NewDeepLTranslator(apiKey, baseURL string) *DeepLTranslator {
+	if baseURL == "" {
+		baseURL = defaultDeepLURL
+	}
+	client := resty.New().
+		SetBaseURL(strings.TrimSuffix(baseURL, "/")).
+		SetHeader("Authorization", "DeepL-Auth-Key "+apiKey).
+		SetTimeout(60 * time.Second)
+	return &DeepLTranslator{client: client}
+}
+
+// Translate translates the text of the request into Chinese, passing its previous context to improve the translation.
+// The segments of a structured request are translated one by one, and ErrStructureLost is returned if the translation
+// of any segment does not keep each of its placeholders exactly once.
+// The request is aborted when ctx is done, and in any case after 60 seconds.
+// Returns an error if the request fails or DeepL answers without a translation. No tokens are reported, since DeepL
+// bills characters.
+func (d *DeepLTranslator) Translate(ctx context.Context, req ChunkRequest) (*ChunkResponse, error) {
+	if req.Structured {
+		return d.translateStructured(ctx, req)
+	}
+	translations, err := d.translate(ctx, deepLRequest{Text: []string{req.Text}, Context: req.PrevContext})
+	if err != nil {
+		return nil, err
+	}
+	return &ChunkResponse{Text: translations[0]}, nil
+}
+
+// translateStructured translates every segment of a structured chunk as a separate text of a single request, with
+// its placeholders replaced by XML tags that DeepL keeps in place, and reassembles the translated chunk with the
+// markers of the segments.
+func (d *DeepLTranslator) translateStructured(ctx context.Context, req ChunkRequest) (*ChunkResponse, error) {
+	segments := parseTranslatedSegments(req.Text)
+	numbers := make([]int, 0, len(segments))
+	for number := range segments {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+	if len(numbers) == 0 {
+		return nil, fmt.Errorf("%w: no segment marker in the chunk", ErrStructureLost)
+	}
+
+	texts := make([]string, len(numbers))
+	for i, number := range numbers {
+		texts[i] = segmentMarkupPattern.ReplaceAllString(html.EscapeString(segments[number]), `<m id="$1"/>`)
+	}
+	translations, err := d.translate(
+		ctx, deepLRequest{Text: texts, Context: StripMarkers(req.PrevContext), TagHandling: "xml"},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(translations) != len(texts) {
+		return nil, fmt.Errorf("DeepL API returned %d translations for %d segments", len(translations), len(texts))
+	}
+
+	parts := make([]string, len(numbers))
+	for i, number := range numbers {
+		translation := html.UnescapeString(segmentMarkupTagPattern.ReplaceAllString(translations[i], "⟦$1⟧"))
+		if !slices.Equal(sortedMarkup(segments[number]), sortedMarkup(translation)) {
+			return nil, fmt.Errorf("%w: segment %d", ErrStructureLost, number)
+		}
+		parts[i] = fmt.Sprintf("⟦S%d⟧ %s", number, strings.TrimSpace(translation))
+	}
+	return &ChunkResponse{Text: strings.Join(parts, "\n\n")}, nil
+}
+
+// sortedMarkup returns the placeholders and line break markers of a segment in sorted order.
+func sortedMarkup(segment string) []string {
+	markup := segmentMarkupPattern.FindAllString(segment, -1)
+	sort.Strings(markup)
+	return markup
+}
+
+// translate sends the texts of body to the translate endpoint of the DeepL API, targeting Chinese, and returns their
+// translations in order.
+func (d *DeepLTranslator) translate(ctx context.Context, body deepLRequest) ([]string, error) {
+	body.TargetLang = strings.ToUpper(TargetLanguage)
+	body.PreserveFormatting = true
+	var result deepLResponse
+	resp, err := d.client.R().
+		SetContext(ctx).
+		SetBody(body).
+		SetResult(&result).
+		Post("/v2/translate")
+	if err != nil {
+		return nil, err
+	}
+	if resp.IsError() {
+		return nil, fmt.Errorf("DeepL API error: %s", resp.Status())
+	}
+	if len(result.Translations) == 0 {
+		return nil, errors.New("empty response from DeepL API")
+	}
+	translations := make([]string, len(result.Translations))
+	for i, translation := range result.Translations {
+		translations[i] = translation.Text
+	}
+	return translations, nil
+}
+
+// Model returns the name of the DeepL backend, which scopes the translation memory if DeepL is the primary backend.
+func (d *DeepLTranslator) Model() string {
+	return "deepl"
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newDeepLServer starts a fake DeepL API that translates every text with translate and records the requests.
+func newDeepLServer(t *testing.T, translate func(string) string, requests *[]deepLRequest) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req deepLRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		*requests = append(*requests, req)
+		var resp deepLResponse
+		for _, text := range req.Text {
+			resp.Translations = append(resp.Translations, struct {
+				Text string `json:"text"`
+			}{translate(text)})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDeepLTranslatorStructured(t *testing.T) {
+	tagPattern := regexp.MustCompile(`<m id="P\d+"/>`)
+	tests := []struct {
+		name      string
+		translate func(string) string
+		want      string
+		wantErr   error
+	}{
+		{
+			name:      "markers kept",
+			translate: func(text string) string { return "译 " + text },
+			want:      "⟦S1⟧ 译 Title\n\n⟦S2⟧ 译 See ⟦P1⟧here⟦P2⟧ &amp; ⟦BR⟧there",
+		},
+		{
+			name:      "placeholder dropped",
+			translate: func(text string) string { return tagPattern.ReplaceAllString(text, "") },
+			wantErr:   ErrStructureLost,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []deepLRequest
+			server := newDeepLServer(t, tt.translate, &requests)
+			translator := NewDeepLTranslator("key", server.URL)
+
+			response, err := translator.Translate(context.Background(), ChunkRequest{
+				Text:       "⟦S1⟧ Title\n\n⟦S2⟧ See ⟦P1⟧here⟦P2⟧ &amp; ⟦BR⟧there",
+				Structured: true,
+			})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, response.Text)
+			assert.Len(t, requests, 1)
+			assert.Equal(t, "xml", requests[0].TagHandling)
+			assert.Equal(
+				t, []string{"Title", `See <m id="P1"/>here<m id="P2"/> &amp;amp; <m id="BR"/>there`}, requests[0].Text,
+			)
+		})
+	}
+}
+
+func TestFallbackTranslatorFallsBackToDeepLOnMarkdown(t *testing.T) {
+	var requests []deepLRequest
+	server := newDeepLServer(t, strings.NewReplacer("intro", "引言", "item", "条目").Replace, &requests)
+	primary := &stubTranslator{prefix: "a:", err: errors.New("service unavailable")}
+	translator, err := NewFallbackTranslator(
+		[]TranslationBackend{{"primary", primary}, {"deepl", NewDeepLTranslator("key", server.URL)}},
+		BreakerSettings{},
+	)
+	assert.NoError(t, err)
+
+	response, err := translator.Translate(context.Background(), ChunkRequest{
+		Text:       "⟦S1⟧ ## ⟦P1⟧intro\n\n⟦S2⟧ - item ⟦P1⟧",
+		Structured: true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "⟦S1⟧ ## ⟦P1⟧引言\n\n⟦S2⟧ - 条目 ⟦P1⟧", response.Text)
+	assert.Equal(t, "deepl", response.Backend)
+	assert.True(t, response.Fallback)
+}
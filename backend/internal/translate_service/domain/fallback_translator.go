@@ -0,0 +1,131 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/sony/gobreaker"
+	"log"
+	"time"
+)
+
+// ErrAllBackendsFailed is returned when no backend of a FallbackTranslator could translate a request, either because
+// they failed or because their circuit breakers were open.
+var ErrAllBackendsFailed = errors.New("all translation backends failed")
+
+// defaultBreakerFailures and defaultBreakerTimeout apply to BreakerSettings left at zero.
+const (
+	defaultBreakerFailures = 5
+	defaultBreakerTimeout  = 30 * time.Second
+)
+
+// TranslationBackend is a Translator of a FallbackTranslator, identified by Name in logs and translated chunks.
+type TranslationBackend struct {
+	Name       string
+	Translator Translator
+}
+
+// BreakerSettings configures the circuit breaker of every backend of a FallbackTranslator.
+// Failures is the number of consecutive failures that opens the breaker, and OpenTimeout how long an open breaker
+// skips its backend before letting a single trial request through.
+type BreakerSettings struct {
+	Failures    uint32
+	OpenTimeout time.Duration
+}
+
+// FallbackTranslator is a Translator trying an ordered list of backends for every request, such as a primary LLM, a
+// secondary LLM and classic machine translation, until one of them succeeds.
+// Every backend is guarded by a circuit breaker, so that a backend failing repeatedly is skipped right away instead of
+// delaying every chunk until it recovers.
+type FallbackTranslator struct {
+	backends []TranslationBackend
+	breakers []*gobreaker.CircuitBreaker
+}
+
+// NewFallbackTranslator initializes a FallbackTranslator trying the backends in the given order, with a circuit
+// breaker per backend configured by settings. Returns an error if no backend is given or two share a name.
+func NewFallbackTranslator(backends []TranslationBackend, settings BreakerSettings) (*FallbackTranslator, error) {
+	if len(backends) == 0 {
+		return nil, errors.New("no translation backends configured")
+	}
+	if settings.Failures == 0 {
+		settings.Failures = defaultBreakerFailures
+	}
+	if settings.OpenTimeout <= 0 {
+		settings.OpenTimeout = defaultBreakerTimeout
+	}
+
+	names := make(map[string]bool, len(backends))
+	breakers := make([]*gobreaker.CircuitBreaker, len(backends))
+	for i, backend := range backends {
+		if backend.Name == "" || names[backend.Name] {
+			return nil, fmt.Errorf("translation backend %d needs a unique name", i)
+		}
+		names[backend.Name] = true
+		breakers[i] = gobreaker.NewCircuitBreaker(
+			gobreaker.Settings{
+				Name:    backend.Name,
+				Timeout: settings.OpenTimeout,
+				ReadyToTrip: func(counts gobreaker.Counts) bool {
+					return counts.ConsecutiveFailures >= settings.Failures
+				},
+				OnStateChange: func(name string, from, to gobreaker.State) {
+					log.Printf("Translation backend %s changed from %s to %s\n", name, from, to)
+				},
+				IsSuccessful: isBackendHealthy,
+			},
+		)
+	}
+	return &FallbackTranslator{backends: backends, breakers: breakers}, nil
+}
+
+// Translate translates the request with the first backend that succeeds, skipping backends whose circuit breaker is
+// open, and records that backend in the response. The Model override of the request only applies to the primary
+// backend, since the other backends may not offer that model.
+// A malformed response is returned as it is rather than falling back, so that the usecase retries it with feedback,
+// and ctx.Err() is returned once ctx is done. Returns ErrAllBackendsFailed with the error of every backend otherwise.
+func (f *FallbackTranslator) Translate(ctx context.Context, req ChunkRequest) (*ChunkResponse, error) {
+	var errs []error
+	for i, backend := range f.backends {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		request := req
+		if i > 0 {
+			request.Model = ""
+		}
+		result, err := f.breakers[i].Execute(
+			func() (interface{}, error) {
+				return backend.Translator.Translate(ctx, request)
+			},
+		)
+		if err == nil {
+			response := *result.(*ChunkResponse)
+			response.Backend = backend.Name
+			response.Fallback = i > 0
+			return &response, nil
+		}
+		if errors.Is(err, ErrMalformedResponse) {
+			return nil, err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		log.Printf("Translation backend %s failed: %v\n", backend.Name, err)
+		errs = append(errs, fmt.Errorf("%s: %w", backend.Name, err))
+	}
+	return nil, fmt.Errorf("%w: %w", ErrAllBackendsFailed, errors.Join(errs...))
+}
+
+// Model returns the model of the primary backend, which scopes the translation memory. Translations of the other
+// backends are marked as Fallback and kept out of the memory.
+func (f *FallbackTranslator) Model() string {
+	return f.backends[0].Translator.Model()
+}
+
+// isBackendHealthy reports whether err leaves the circuit breaker of a backend closed. Malformed responses and lost
+// placeholders are caused by the document rather than the backend, and cancellation by the caller.
+func isBackendHealthy(err error) bool {
+	return err == nil || errors.Is(err, ErrMalformedResponse) || errors.Is(err, ErrStructureLost) ||
+		errors.Is(err, context.Canceled)
+}
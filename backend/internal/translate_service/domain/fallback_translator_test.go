@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stubTranslator answers every request with its prefix and the text of the request, or fails with err.
+type stubTranslator struct {
+	prefix string
+	err    error
+	calls  int
+	models []string
+}
+
+func (s *stubTranslator) Translate(_ context.Context, req ChunkRequest) (*ChunkResponse, error) {
+	s.calls++
+	s.models = append(s.models, req.Model)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &ChunkResponse{Text: s.prefix + req.Text, Usage: TokenUsage{TotalTokens: 1}}, nil
+}
+
+func (s *stubTranslator) Model() string {
+	return s.prefix + "model"
+}
+
+func TestFallbackTranslatorFallsBackInOrder(t *testing.T) {
+	primary := &stubTranslator{prefix: "a:", err: errors.New("service unavailable")}
+	secondary := &stubTranslator{prefix: "b:", err: errors.New("rate limited")}
+	mt := &stubTranslator{prefix: "c:"}
+	translator, err := NewFallbackTranslator(
+		[]TranslationBackend{{"primary", primary}, {"secondary", secondary}, {"mt", mt}}, BreakerSettings{},
+	)
+	assert.NoError(t, err)
+
+	response, err := translator.Translate(context.Background(), ChunkRequest{Text: "hello", Model: "gpt-4o"})
+	assert.NoError(t, err)
+	assert.Equal(
+		t, &ChunkResponse{Text: "c:hello", Usage: TokenUsage{TotalTokens: 1}, Backend: "mt", Fallback: true}, response,
+	)
+	assert.Equal(t, []string{"gpt-4o"}, primary.models)
+	assert.Equal(t, []string{""}, mt.models)
+	assert.Equal(t, "a:model", translator.Model())
+}
+
+func TestFallbackTranslatorOpensBreaker(t *testing.T) {
+	primary := &stubTranslator{prefix: "a:", err: errors.New("service unavailable")}
+	secondary := &stubTranslator{prefix: "b:"}
+	translator, err := NewFallbackTranslator(
+		[]TranslationBackend{{"primary", primary}, {"secondary", secondary}}, BreakerSettings{Failures: 2},
+	)
+	assert.NoError(t, err)
+
+	for i := 0; i < 4; i++ {
+		response, err := translator.Translate(context.Background(), ChunkRequest{Text: fmt.Sprint(i)})
+		assert.NoError(t, err)
+		assert.Equal(t, "secondary", response.Backend)
+	}
+	// The breaker opens after two consecutive failures, so later requests skip the primary backend
+	assert.Equal(t, 2, primary.calls)
+	assert.Equal(t, 4, secondary.calls)
+}
+
+func TestFallbackTranslatorErrors(t *testing.T) {
+	malformed := &stubTranslator{err: fmt.Errorf("%w: not JSON", ErrMalformedResponse)}
+	unused := &stubTranslator{prefix: "b:"}
+	translator, err := NewFallbackTranslator(
+		[]TranslationBackend{{"primary", malformed}, {"secondary", unused}}, BreakerSettings{},
+	)
+	assert.NoError(t, err)
+	_, err = translator.Translate(context.Background(), ChunkRequest{Text: "hello"})
+	assert.ErrorIs(t, err, ErrMalformedResponse)
+	assert.Equal(t, 0, unused.calls)
+
+	translator, err = NewFallbackTranslator(
+		[]TranslationBackend{{"primary", &stubTranslator{err: errors.New("down")}}}, BreakerSettings{},
+	)
+	assert.NoError(t, err)
+	_, err = translator.Translate(context.Background(), ChunkRequest{Text: "hello"})
+	assert.ErrorIs(t, err, ErrAllBackendsFailed)
+	assert.ErrorContains(t, err, "primary: down")
+
+	_, err = NewFallbackTranslator(
+		[]TranslationBackend{{"same", unused}, {"same", unused}}, BreakerSettings{},
+	)
+	assert.Error(t, err)
+}
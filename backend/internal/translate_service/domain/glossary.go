@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// GlossaryTerm represents a source term and the target term it must always be translated to.
+type GlossaryTerm struct {
+	Source string
+	Target string
+}
+
+// GlossaryViolation records a glossary term found in a chunk whose required translation is missing from the output.
+type GlossaryViolation struct {
+	ChunkIndex int
+	Source     string
+	Target     string
+}
+
+// MatchGlossary returns the glossary terms whose source term occurs in text.
+// Matching is case-insensitive and only accepts whole words for terms that start or end with a letter or digit, so
+// that short terms do not match inside longer words.
+func MatchGlossary(glossary []GlossaryTerm, text string) []GlossaryTerm {
+	var matched []GlossaryTerm
+	lowerText := strings.ToLower(text)
+	for _, term := range glossary {
+		if term.Source == "" {
+			continue
+		}
+		if containsTerm(lowerText, strings.ToLower(term.Source)) {
+			matched = append(matched, term)
+		}
+	}
+	return matched
+}
+
+// CheckGlossary returns a violation for every matched term whose target term does not appear in the translated text.
+func CheckGlossary(chunkIndex int, matched []GlossaryTerm, translated string) []GlossaryViolation {
+	var violations []GlossaryViolation
+	lowerTranslated := strings.ToLower(translated)
+	for _, term := range matched {
+		if term.Target == "" || strings.Contains(lowerTranslated, strings.ToLower(term.Target)) {
+			continue
+		}
+		violations = append(
+			violations, GlossaryViolation{ChunkIndex: chunkIndex, Source: term.Source, Target: term.Target},
+		)
+	}
+	return violations
+}
+
+// containsTerm reports whether term occurs in text with word boundaries on both sides.
+// Characters of unspaced scripts such as Chinese never need a boundary.
+func containsTerm(text, term string) bool {
+	for offset := 0; offset <= len(text)-len(term); {
+		index := strings.Index(text[offset:], term)
+		if index < 0 {
+			return false
+		}
+		start := offset + index
+		end := start + len(term)
+		if isBoundary(text[:start], term, false) && isBoundary(text[end:], term, true) {
+			return true
+		}
+		_, size := utf8.DecodeRuneInString(text[start:])
+		offset = start + size
+	}
+	return false
+}
+
+// isBoundary reports whether the text next to a match of term ends a word, looking at the start of rest when after is
+// true and at the end of rest otherwise.
+func isBoundary(rest, term string, after bool) bool {
+	var outer, inner rune
+	if after {
+		outer, _ = utf8.DecodeRuneInString(rest)
+		inner, _ = utf8.DecodeLastRuneInString(term)
+	} else {
+		outer, _ = utf8.DecodeLastRuneInString(rest)
+		inner, _ = utf8.DecodeRuneInString(term)
+	}
+	if rest == "" || !isWordRune(inner) || !isWordRune(outer) {
+		return true
+	}
+	return unicode.In(inner, unicode.Han, unicode.Hiragana, unicode.Katakana) ||
+		unicode.In(outer, unicode.Han, unicode.Hiragana, unicode.Katakana)
+}
+
+// isWordRune reports whether r can be part of a word.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchGlossary(t *testing.T) {
+	glossary := []GlossaryTerm{
+		{Source: "contract", Target: "合同"},
+		{Source: "Force Majeure", Target: "不可抗力"},
+		{Source: "art", Target: "艺术"},
+		{Source: "合同", Target: "contract"},
+	}
+
+	tests := []struct {
+		name     string
+		text     string
+		expected []GlossaryTerm
+	}{
+		{"case insensitive", "The CONTRACT is signed.", []GlossaryTerm{glossary[0]}},
+		{"multi word term", "A force majeure event.", []GlossaryTerm{glossary[1]}},
+		{"no match inside word", "The party departed.", nil},
+		{"whole word match", "Modern art, contracts aside.", []GlossaryTerm{glossary[2]}},
+		{"unspaced script", "本合同自签署之日起生效", []GlossaryTerm{glossary[3]}},
+		{"empty text", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				assert.Equal(t, tt.expected, MatchGlossary(glossary, tt.text))
+			},
+		)
+	}
+}
+
+func TestCheckGlossary(t *testing.T) {
+	matched := []GlossaryTerm{
+		{Source: "contract", Target: "合同"},
+		{Source: "Force Majeure", Target: "不可抗力"},
+	}
+
+	violations := CheckGlossary(3, matched, "本合同因不可預見的事件終止。")
+	assert.Equal(t, []GlossaryViolation{{ChunkIndex: 3, Source: "Force Majeure", Target: "不可抗力"}}, violations)
+	assert.Empty(t, CheckGlossary(0, matched, "本合同因不可抗力终止。"))
+}
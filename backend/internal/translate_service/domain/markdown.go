@@ -0,0 +1,290 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// markdownParser parses documents with the GitHub flavored markdown extensions, so that tables, strikethrough and bare
+// URLs are recognized.
+var markdownParser = goldmark.New(goldmark.WithExtensions(extension.GFM)).Parser()
+
+// segmentMarkerPattern matches the marker introducing a segment in a batch of segments.
+// placeholderPattern matches a placeholder standing for protected content within a segment.
+// protectedPattern matches the content of text nodes that must not be translated: URLs, numbers and reference markers.
+// softBreakPattern matches the source between two lines of a paragraph, including the prefix of enclosing containers.
+var (
+	segmentMarkerPattern = regexp.MustCompile(`⟦S(\d+)⟧`)
+	placeholderPattern   = regexp.MustCompile(`⟦P(\d+)⟧`)
+	protectedPattern     = regexp.MustCompile(
+		`https?://[^\s<>()]+|\[(?:\^[^\]\s]+|\d+(?:[,–-]\d+)*)\]|\d+(?:[.,:/]\d+)*%?`,
+	)
+	softBreakPattern = regexp.MustCompile(`^\r?\n[ \t>]*$`)
+)
+
+// MarkdownDocument is a markdown document whose translatable text has been split into segments.
+// Everything outside the segments, such as markup, code blocks and link destinations, is kept verbatim when the
+// document is rendered.
+type MarkdownDocument struct {
+	source     string
+	Segments   []MarkdownSegment
+	Structured bool
+}
+
+// MarkdownSegment is the text of a single paragraph, heading, list item or table cell.
+// Template is the text to translate, with every protected part replaced by a placeholder ⟦Pn⟧ standing for
+// placeholders[n-1]. The translation replaces the source bytes from Start to End, and LineStart is the offset of the
+// line the segment starts on.
+type MarkdownSegment struct {
+	Template     string
+	Start        int
+	End          int
+	LineStart    int
+	placeholders []string
+	inTable      bool
+}
+
+// MarkdownChunk is a run of consecutive segments, from First up to but excluding Last, together with the source bytes
+// From up to To that are rendered with them.
+type MarkdownChunk struct {
+	From  int
+	To    int
+	First int
+	Last  int
+}
+
+// segmentPiece is a part of a segment: translatable text, protected source or a soft line break.
+type segmentPiece struct {
+	text      string
+	protected bool
+	softBreak bool
+}
+
+// ParseMarkdown parses text as markdown and splits the text nodes of its leaf blocks into segments.
+// Structured reports whether the document uses any markdown beyond plain paragraphs, such as headings, lists, code,
+// links, emphasis or tables.
+func ParseMarkdown(source string) *MarkdownDocument {
+	src := []byte(source)
+	root := markdownParser.Parse(text.NewReader(src))
+	doc := &MarkdownDocument{source: source}
+
+	_ = ast.Walk(
+		root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+			if !entering {
+				return ast.WalkContinue, nil
+			}
+			switch n.Kind() {
+			case ast.KindDocument, ast.KindText:
+			case ast.KindParagraph, ast.KindHeading, ast.KindTextBlock, east.KindTableCell:
+				if n.Kind() != ast.KindParagraph {
+					doc.Structured = true
+				}
+				if segment, ok := newMarkdownSegment(src, n); ok {
+					doc.Segments = append(doc.Segments, segment)
+				}
+				return ast.WalkContinue, nil
+			default:
+				doc.Structured = true
+			}
+			return ast.WalkContinue, nil
+		},
+	)
+	sort.SliceStable(doc.Segments, func(i, j int) bool { return doc.Segments[i].Start < doc.Segments[j].Start })
+	return doc
+}
+
+// newMarkdownSegment builds the segment of a leaf block from its text nodes. Text inside code spans, autolinks and raw
+// HTML is protected. Returns false if the block contains no letters to translate.
+func newMarkdownSegment(src []byte, block ast.Node) (MarkdownSegment, bool) {
+	var texts []*ast.Text
+	_ = ast.Walk(
+		block, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+			if !entering {
+				return ast.WalkContinue, nil
+			}
+			switch n.Kind() {
+			case ast.KindCodeSpan, ast.KindAutoLink, ast.KindRawHTML:
+				return ast.WalkSkipChildren, nil
+			case ast.KindText:
+				texts = append(texts, n.(*ast.Text))
+			}
+			return ast.WalkContinue, nil
+		},
+	)
+	if len(texts) == 0 {
+		return MarkdownSegment{}, false
+	}
+
+	var pieces []segmentPiece
+	for i, t := range texts {
+		if i > 0 {
+			prev := texts[i-1]
+			if gap := string(src[prev.Segment.Stop:t.Segment.Start]); gap != "" {
+				if prev.SoftLineBreak() && softBreakPattern.MatchString(gap) {
+					pieces = append(pieces, segmentPiece{softBreak: true})
+				} else {
+					pieces = append(pieces, segmentPiece{text: gap, protected: true})
+				}
+			}
+		}
+		value := string(src[t.Segment.Start:t.Segment.Stop])
+		if len(pieces) > 0 && !pieces[len(pieces)-1].protected && !pieces[len(pieces)-1].softBreak {
+			pieces[len(pieces)-1].text += value
+		} else {
+			pieces = append(pieces, segmentPiece{text: value})
+		}
+	}
+
+	segment := MarkdownSegment{
+		Start:   texts[0].Segment.Start,
+		End:     texts[len(texts)-1].Segment.Stop,
+		inTable: block.Kind() == east.KindTableCell,
+	}
+	segment.LineStart = strings.LastIndexByte(string(src[:segment.Start]), '\n') + 1
+
+	var template strings.Builder
+	translatable := false
+	for _, piece := range pieces {
+		switch {
+		case piece.softBreak:
+			template.WriteString(" ")
+		case piece.protected:
+			template.WriteString(segment.addPlaceholder(piece.text))
+		default:
+			last := 0
+			for _, loc := range protectedPattern.FindAllStringIndex(piece.text, -1) {
+				template.WriteString(piece.text[last:loc[0]])
+				template.WriteString(segment.addPlaceholder(piece.text[loc[0]:loc[1]]))
+				last = loc[1]
+			}
+			template.WriteString(piece.text[last:])
+			translatable = translatable || strings.IndexFunc(piece.text, unicode.IsLetter) >= 0
+		}
+	}
+	segment.Template = template.String()
+	return segment, translatable && strings.IndexFunc(stripPlaceholders(segment.Template), unicode.IsLetter) >= 0
+}
+
+// addPlaceholder records protected content and returns the placeholder standing for it.
+func (s *MarkdownSegment) addPlaceholder(content string) string {
+	s.placeholders = append(s.placeholders, content)
+	return fmt.Sprintf("⟦P%d⟧", len(s.placeholders))
+}
+
+// apply restores the protected content of the segment in its translation. Line breaks are collapsed, pipes are escaped
+// inside table cells, and placeholders the translation dropped are appended so that no protected content is lost.
+func (s *MarkdownSegment) apply(translation string) string {
+	translation = strings.Join(strings.Fields(translation), " ")
+	if s.inTable {
+		translation = escapeTablePipes(translation)
+	}
+
+	used := make([]bool, len(s.placeholders))
+	rendered := placeholderPattern.ReplaceAllStringFunc(
+		translation, func(placeholder string) string {
+			n, err := strconv.Atoi(placeholderPattern.FindStringSubmatch(placeholder)[1])
+			if err != nil || n < 1 || n > len(s.placeholders) || used[n-1] {
+				return ""
+			}
+			used[n-1] = true
+			return s.placeholders[n-1]
+		},
+	)
+	for i, content := range s.placeholders {
+		if !used[i] {
+			rendered += content
+		}
+	}
+	return rendered
+}
+
+// escapeTablePipes escapes every pipe that is not escaped yet, so that it does not split a table cell.
+func escapeTablePipes(s string) string {
+	var builder strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '|' && (i == 0 || s[i-1] != '\\') {
+			builder.WriteByte('\\')
+		}
+		builder.WriteByte(s[i])
+	}
+	return builder.String()
+}
+
+// stripPlaceholders removes all placeholders from a template.
+func stripPlaceholders(template string) string {
+	return placeholderPattern.ReplaceAllString(template, "")
+}
+
+// StripMarkers removes segment markers and placeholders from a batch of segments, leaving only its plain text.
+func StripMarkers(batch string) string {
+	return stripPlaceholders(segmentMarkerPattern.ReplaceAllString(batch, ""))
+}
+
+// FormatChunk builds the text sent to the translator for a chunk: every segment of the chunk on its own paragraph,
+// introduced by its marker ⟦Sn⟧ with n counting from 1 within the chunk.
+func (d *MarkdownDocument) FormatChunk(chunk MarkdownChunk) string {
+	parts := make([]string, 0, chunk.Last-chunk.First)
+	for i := chunk.First; i < chunk.Last; i++ {
+		parts = append(parts, fmt.Sprintf("⟦S%d⟧ %s", i-chunk.First+1, d.Segments[i].Template))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// SourceOf returns the markdown source covered by a chunk.
+func (d *MarkdownDocument) SourceOf(chunk MarkdownChunk) string {
+	return d.source[chunk.From:chunk.To]
+}
+
+// RenderChunk returns the markdown source covered by a chunk with every segment replaced by its translation from the
+// translated chunk. Segments whose marker is missing from translated keep their source text, so a failed chunk renders
+// as its source.
+func (d *MarkdownDocument) RenderChunk(chunk MarkdownChunk, translated string) string {
+	translations := parseTranslatedSegments(translated)
+	var builder strings.Builder
+	last := chunk.From
+	for i := chunk.First; i < chunk.Last; i++ {
+		translation := translations[i-chunk.First+1]
+		if translation == "" {
+			continue
+		}
+		segment := &d.Segments[i]
+		builder.WriteString(d.source[last:segment.Start])
+		builder.WriteString(segment.apply(translation))
+		last = segment.End
+	}
+	builder.WriteString(d.source[last:chunk.To])
+	return builder.String()
+}
+
+// parseTranslatedSegments splits a translated chunk into the translation of every segment by its marker number.
+func parseTranslatedSegments(translated string) map[int]string {
+	segments := make(map[int]string)
+	locs := segmentMarkerPattern.FindAllStringSubmatchIndex(translated, -1)
+	for i, loc := range locs {
+		index, err := strconv.Atoi(translated[loc[2]:loc[3]])
+		if err != nil {
+			continue
+		}
+		end := len(translated)
+		if i+1 < len(locs) {
+			end = locs[i+1][0]
+		}
+		segments[index] = strings.TrimSpace(translated[loc[1]:end])
+	}
+	return segments
+}
+
+// Len returns the length of the markdown source in bytes.
+func (d *MarkdownDocument) Len() int {
+	return len(d.source)
+}
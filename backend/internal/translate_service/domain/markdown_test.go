@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMarkdown(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		structured bool
+		templates  []string
+	}{
+		{"plain paragraphs", "First paragraph\ncontinues.\n\nSecond one.", false, []string{"First paragraph continues.", "Second one."}},
+		{
+			"code, links and numbers protected",
+			"Run `make build` and read [the docs](https://example.com/docs), section 2.1 [3].",
+			true,
+			[]string{"Run ⟦P1⟧ and read ⟦P2⟧the docs⟦P3⟧, section ⟦P4⟧ ⟦P5⟧."},
+		},
+		{"code block skipped", "# Usage\n\n```sh\necho hello\n```\n", true, []string{"Usage"}},
+		{"table cells", "| Name | Value |\n| --- | --- |\n| alpha | 42 |\n", true, []string{"Name", "Value", "alpha"}},
+		{"bare url", "- Visit https://example.org now", true, []string{"Visit ⟦P1⟧ now"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				doc := ParseMarkdown(tt.input)
+				assert.Equal(t, tt.structured, doc.Structured)
+				var templates []string
+				for _, segment := range doc.Segments {
+					templates = append(templates, segment.Template)
+				}
+				assert.Equal(t, tt.templates, templates)
+			},
+		)
+	}
+}
+
+func TestRenderChunk(t *testing.T) {
+	source := "# Getting started\n\nSee [the docs](https://example.com/docs) and `go test`.\n\n" +
+		"| Name | Value |\n| --- | --- |\n| alpha | 42 |\n\n```go\nfmt.Println(\"hello\")\n```\n"
+	doc := ParseMarkdown(source)
+	chunk := MarkdownChunk{To: doc.Len(), Last: len(doc.Segments)}
+
+	tests := []struct {
+		name       string
+		translated string
+		expected   string
+	}{
+		{
+			"every segment translated",
+			strings.ToUpper(doc.FormatChunk(chunk)),
+			"# GETTING STARTED\n\nSEE [THE DOCS](https://example.com/docs) AND `go test`.\n\n" +
+				"| NAME | VALUE |\n| --- | --- |\n| ALPHA | 42 |\n\n```go\nfmt.Println(\"hello\")\n```\n",
+		},
+		{
+			"missing segments and placeholders",
+			"⟦S2⟧ 请参阅⟦P2⟧文档⟦P2⟧。\n\n⟦S5⟧ a|b",
+			"# Getting started\n\n请参阅](https://example.com/docs)文档。[`go test`\n\n" +
+				"| Name | Value |\n| --- | --- |\n| a\\|b | 42 |\n\n```go\nfmt.Println(\"hello\")\n```\n",
+		},
+		{"failed chunk", "", source},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				assert.Equal(t, tt.expected, doc.RenderChunk(chunk, tt.translated))
+			},
+		)
+	}
+}
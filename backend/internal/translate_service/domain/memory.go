@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"unicode"
+)
+
+// MemoryKey identifies the translation setup a translation memory entry is valid for.
+// Segments translated from a different source language, into a different target language, by a different model or in
+// a different style are never reused. Style is the key of the TranslationStyle, which is empty for the default style.
+type MemoryKey struct {
+	SourceLang string
+	TargetLang string
+	Model      string
+	Style      string
+}
+
+// Scope returns the model of the key together with its style, if any, for use in storage keys. Entries of the default
+// style keep the scope of the model alone.
+func (k MemoryKey) Scope() string {
+	if k.Style == "" {
+		return k.Model
+	}
+	return k.Model + "+" + k.Style
+}
+
+// MemoryMatch is a previously translated segment found in the translation memory.
+// Exact reports whether Source equals the looked-up segment after normalization; otherwise Source only matches the
+// skeleton of the segment and the translation may only serve as a reference.
+type MemoryMatch struct {
+	Source      string
+	Translation string
+	Exact       bool
+}
+
+// TranslationMemory is an interface for storing and reusing translations of previously seen segments.
+// Lookup returns the best match for segment, or nil if there is none.
+// Store saves the translation of segment so that later lookups can reuse it.
+type TranslationMemory interface {
+	Lookup(ctx context.Context, key MemoryKey, segment string) (*MemoryMatch, error)
+	Store(ctx context.Context, key MemoryKey, segment, translation string) error
+}
+
+// NormalizeSegment trims a segment and collapses every run of whitespace into a single space, so that re-extracted
+// text with different line wrapping still matches.
+func NormalizeSegment(segment string) string {
+	return strings.Join(strings.Fields(segment), " ")
+}
+
+// SegmentSkeleton reduces a segment to its lower-cased words without digits and punctuation. Segments that differ
+// only in numbers, punctuation or case share the same skeleton, which is used for fuzzy matches.
+func SegmentSkeleton(segment string) string {
+	stripped := strings.Map(
+		func(r rune) rune {
+			if unicode.IsDigit(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) {
+				return ' '
+			}
+			return unicode.ToLower(r)
+		}, segment,
+	)
+	return NormalizeSegment(stripped)
+}
+
+// HashSegment returns the hex encoded SHA-256 hash of a segment.
+func HashSegment(segment string) string {
+	sum := sha256.Sum256([]byte(segment))
+	return hex.EncodeToString(sum[:])
+}
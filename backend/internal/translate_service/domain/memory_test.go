@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeSegment(t *testing.T) {
+	tests := []struct {
+		name     string
+		segment  string
+		expected string
+	}{
+		{"collapses whitespace", "  The contract\n\nis   signed. ", "The contract is signed."},
+		{"keeps case and punctuation", "Section 2.1: Terms", "Section 2.1: Terms"},
+		{"empty segment", " \n ", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				assert.Equal(t, tt.expected, NormalizeSegment(tt.segment))
+			},
+		)
+	}
+}
+
+func TestSegmentSkeleton(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        string
+		b        string
+		expected bool
+	}{
+		{"numbers differ", "Payment is due in 30 days.", "Payment is due in 45 days.", true},
+		{"punctuation and case differ", "Section 2.1: Terms", "section 3.4 terms", true},
+		{"words differ", "Payment is due in 30 days.", "Delivery is due in 30 days.", false},
+		{"chinese", "第1条 合同生效。", "第2条 合同生效", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				assert.Equal(t, tt.expected, SegmentSkeleton(tt.a) == SegmentSkeleton(tt.b))
+			},
+		)
+	}
+}
@@ -0,0 +1,267 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CheckTargetLanguage flags translations that are mostly not written in the target script.
+// CheckLengthRatio flags translations that are suspiciously short or long compared to their source.
+// CheckUntranslatedSpan flags long runs of source text copied into the translation.
+// CheckMissingNumber flags numbers of the source that are missing from the translation.
+// CheckNotTranslation flags answers that are not a translation of the source at all, such as replies to instructions
+// hidden in a document or answers without the requested structure.
+const (
+	CheckTargetLanguage   = "target_language"
+	CheckLengthRatio      = "length_ratio"
+	CheckUntranslatedSpan = "untranslated_span"
+	CheckMissingNumber    = "missing_number"
+	CheckNotTranslation   = "not_translation"
+)
+
+// numberPattern matches numbers with optional thousands separators and decimals.
+// latinRunPattern matches runs of Latin words and numbers separated by spaces and light punctuation.
+// markupPattern matches segment markers and placeholders, which are neither translated nor counted.
+var (
+	numberPattern   = regexp.MustCompile(`\d+(?:[.,]\d+)*`)
+	latinRunPattern = regexp.MustCompile(`[A-Za-z][A-Za-z0-9]*(?:[\s,.;:'’"-]+[A-Za-z0-9]+)*`)
+	markupPattern   = regexp.MustCompile(`⟦[SP]\d+⟧`)
+)
+
+// assistantPhrase is a phrase of an assistant talking to its user rather than translating, which is flagged unless the
+// source contains one of sources, the wordings the phrase is a faithful translation of. Both are matched in lower case.
+type assistantPhrase struct {
+	phrase  string
+	sources []string
+}
+
+// assistantPhrases are the phrases flagged by AssistantReplyValidator: English phrases that never belong in a Chinese
+// translation unless the source has them too, the delimiters of the prompt, and Chinese phrases checked against the
+// English they translate. Chinese apologies and refusals such as "抱歉" or "我无法" are not listed, since they are the
+// ordinary translation of "sorry" or "I cannot" in a document.
+var assistantPhrases = []assistantPhrase{
+	{phrase: "as an ai", sources: []string{"as an ai"}},
+	{phrase: "i'm sorry", sources: []string{"i'm sorry"}},
+	{phrase: "i am sorry", sources: []string{"i am sorry"}},
+	{phrase: "i cannot", sources: []string{"i cannot"}},
+	{phrase: "i can't", sources: []string{"i can't"}},
+	{phrase: "here is the translation", sources: []string{"here is the translation"}},
+	{phrase: "here's the translation", sources: []string{"here's the translation"}},
+	{phrase: "ignore previous instructions", sources: []string{"ignore previous instructions"}},
+	{phrase: "<<<begin", sources: []string{"<<<begin"}},
+	{phrase: "<<<end", sources: []string{"<<<end"}},
+	{phrase: "作为一个ai", sources: []string{"as an ai", "作为一个ai"}},
+	{phrase: "作为ai", sources: []string{"as an ai", "as ai", "作为ai"}},
+	{phrase: "以下是翻译", sources: []string{"translation", "以下是翻译"}},
+	{phrase: "翻译如下", sources: []string{"translation", "翻译如下"}},
+}
+
+// QualityIssue reports a check the translation of the chunk at ChunkIndex failed, with a human-readable detail.
+type QualityIssue struct {
+	ChunkIndex int
+	Check      string
+	Detail     string
+}
+
+// QualityValidator is an interface for checking a translation against its source.
+// Validate returns the issue found in the translation, or nil if the translation passes.
+type QualityValidator interface {
+	Validate(source, translation string) *QualityIssue
+}
+
+// TargetScriptValidator requires at least MinShare of the letters of a translation to belong to Script.
+// Sources with fewer than MinLetters letters are not checked, since names and codes may legitimately stay as they are.
+type TargetScriptValidator struct {
+	Script     *unicode.RangeTable
+	MinShare   float64
+	MinLetters int
+}
+
+// Validate reports a CheckTargetLanguage issue if too few letters of the translation belong to the target script.
+func (v TargetScriptValidator) Validate(source, translation string) *QualityIssue {
+	if countLetters(source) < v.MinLetters {
+		return nil
+	}
+	letters, inScript := 0, 0
+	for _, r := range stripMarkup(translation) {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.Is(v.Script, r) {
+				inScript++
+			}
+		}
+	}
+	if letters == 0 {
+		return nil
+	}
+	if share := float64(inScript) / float64(letters); share < v.MinShare {
+		return &QualityIssue{
+			Check:  CheckTargetLanguage,
+			Detail: fmt.Sprintf("only %.0f%% of the letters are in the target language", share*100),
+		}
+	}
+	return nil
+}
+
+// LengthRatioValidator requires the ratio between the non-space characters of a translation and its source to lie
+// between Min and Max. Sources with fewer than MinSourceRunes characters are not checked.
+type LengthRatioValidator struct {
+	Min            float64
+	Max            float64
+	MinSourceRunes int
+}
+
+// Validate reports a CheckLengthRatio issue if the translation looks truncated or padded.
+func (v LengthRatioValidator) Validate(source, translation string) *QualityIssue {
+	sourceRunes := countNonSpace(stripMarkup(source))
+	if sourceRunes < v.MinSourceRunes || sourceRunes == 0 {
+		return nil
+	}
+	ratio := float64(countNonSpace(stripMarkup(translation))) / float64(sourceRunes)
+	if ratio < v.Min || ratio > v.Max {
+		return &QualityIssue{
+			Check:  CheckLengthRatio,
+			Detail: fmt.Sprintf("length ratio %.2f is outside [%.2f, %.2f]", ratio, v.Min, v.Max),
+		}
+	}
+	return nil
+}
+
+// UntranslatedSpanValidator rejects translations containing a run of at least MinWords Latin words that also occurs
+// in the source, which indicates that part of the source was copied instead of translated.
+type UntranslatedSpanValidator struct {
+	MinWords int
+}
+
+// Validate reports a CheckUntranslatedSpan issue with the first untranslated span found.
+func (v UntranslatedSpanValidator) Validate(source, translation string) *QualityIssue {
+	normalizedSource := strings.ToLower(strings.Join(strings.Fields(source), " "))
+	for _, span := range latinRunPattern.FindAllString(stripMarkup(translation), -1) {
+		if len(strings.Fields(span)) < v.MinWords {
+			continue
+		}
+		normalizedSpan := strings.ToLower(strings.Join(strings.Fields(span), " "))
+		if strings.Contains(normalizedSource, normalizedSpan) {
+			return &QualityIssue{Check: CheckUntranslatedSpan, Detail: fmt.Sprintf("untranslated: %q", span)}
+		}
+	}
+	return nil
+}
+
+// NumberValidator requires every number of the source to appear in the translation, ignoring thousands separators.
+type NumberValidator struct{}
+
+// Validate reports a CheckMissingNumber issue listing the numbers of the source missing from the translation.
+func (v NumberValidator) Validate(source, translation string) *QualityIssue {
+	translated := make(map[string]bool)
+	for _, number := range numberPattern.FindAllString(stripMarkup(translation), -1) {
+		translated[normalizeNumber(number)] = true
+	}
+	var missing []string
+	for _, number := range numberPattern.FindAllString(stripMarkup(source), -1) {
+		if !translated[normalizeNumber(number)] {
+			missing = append(missing, number)
+		}
+	}
+	if len(missing) > 0 {
+		return &QualityIssue{Check: CheckMissingNumber, Detail: "missing: " + strings.Join(missing, ", ")}
+	}
+	return nil
+}
+
+// AssistantReplyValidator rejects translations containing phrases of an assistant addressing its user, or the
+// delimiters of the prompt, that the source does not account for. Such answers are typically the model obeying instructions
+// hidden in the document instead of translating it.
+type AssistantReplyValidator struct{}
+
+// Validate reports a CheckNotTranslation issue with the first assistant phrase found.
+func (v AssistantReplyValidator) Validate(source, translation string) *QualityIssue {
+	lowerSource, lowerTranslation := strings.ToLower(source), strings.ToLower(translation)
+	for _, phrase := range assistantPhrases {
+		if strings.Contains(lowerTranslation, phrase.phrase) && !containsAny(lowerSource, phrase.sources) {
+			return &QualityIssue{Check: CheckNotTranslation, Detail: fmt.Sprintf("answer contains %q", phrase.phrase)}
+		}
+	}
+	return nil
+}
+
+// containsAny reports whether s contains any of the substrings.
+func containsAny(s string, substrings []string) bool {
+	for _, substring := range substrings {
+		if strings.Contains(s, substring) {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultValidators returns the validators for translations into TargetLanguage with the given length ratio bounds.
+func DefaultValidators(minLengthRatio, maxLengthRatio float64) []QualityValidator {
+	return []QualityValidator{
+		TargetScriptValidator{Script: unicode.Han, MinShare: 0.5, MinLetters: 20},
+		LengthRatioValidator{Min: minLengthRatio, Max: maxLengthRatio, MinSourceRunes: 40},
+		UntranslatedSpanValidator{MinWords: 6},
+		NumberValidator{},
+		AssistantReplyValidator{},
+	}
+}
+
+// CheckQuality runs all validators on the translation of the chunk at chunkIndex and returns the issues found, in the
+// order of the validators.
+func CheckQuality(chunkIndex int, validators []QualityValidator, source, translation string) []QualityIssue {
+	var issues []QualityIssue
+	for _, validator := range validators {
+		if issue := validator.Validate(source, translation); issue != nil {
+			issue.ChunkIndex = chunkIndex
+			issues = append(issues, *issue)
+		}
+	}
+	return issues
+}
+
+// normalizeNumber removes thousands separators from a number, so that "1,000" and "1000" compare equal. A comma
+// followed by fewer than three digits is taken as a decimal comma.
+func normalizeNumber(number string) string {
+	parts := strings.Split(number, ",")
+	for _, part := range parts[1:] {
+		if len(part) < 3 || strings.Contains(part[:3], ".") {
+			return strings.ReplaceAll(number, ",", ".")
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// stripMarkup removes segment markers and placeholders from text.
+func stripMarkup(text string) string {
+	return markupPattern.ReplaceAllString(text, "")
+}
+
+// countLetters returns the number of letters in text.
+func countLetters(text string) int {
+	count := 0
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			count++
+		}
+	}
+	return count
+}
+
+// countNonSpace returns the number of characters in text that are not white space.
+func countNonSpace(text string) int {
+	return utf8.RuneCountInString(text) - countSpaces(text)
+}
+
+// countSpaces returns the number of white space characters in text.
+func countSpaces(text string) int {
+	count := 0
+	for _, r := range text {
+		if unicode.IsSpace(r) {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckQuality(t *testing.T) {
+	validators := DefaultValidators(0.1, 2.0)
+	source := "The court ordered the company to pay 1,000 euros in damages within 30 days of the judgment."
+
+	tests := []struct {
+		name        string
+		translation string
+		checks      []string
+	}{
+		{"faithful translation", "法院责令该公司在判决后30天内支付1000欧元的损害赔偿。", nil},
+		{"wrong language", "Le tribunal a condamné la société à payer 1,000 euros sous 30 jours.", []string{CheckTargetLanguage}},
+		{"truncated", "法院。", []string{CheckLengthRatio, CheckMissingNumber}},
+		{
+			"untranslated span",
+			"法院责令该公司 pay 1,000 euros in damages within 30 days，并在判决生效后依法履行全部赔偿义务，不得拖延或者拒绝支付。",
+			[]string{CheckUntranslatedSpan},
+		},
+		{"missing number", "法院责令该公司在判决后一个月内支付一千欧元的损害赔偿金，以弥补原告的损失。", []string{CheckMissingNumber}},
+		{"assistant reply", "好的，以下是翻译：法院责令该公司在判决后30天内支付1000欧元的损害赔偿。", []string{CheckNotTranslation}},
+		{"markers ignored", "⟦S1⟧法院责令该公司在判决后30天内支付1000欧元的损害赔偿。", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				var checks []string
+				for _, issue := range CheckQuality(3, validators, "⟦S1⟧"+source, tt.translation) {
+					assert.Equal(t, 3, issue.ChunkIndex)
+					checks = append(checks, issue.Check)
+				}
+				assert.Equal(t, tt.checks, checks)
+			},
+		)
+	}
+}
+
+func TestAssistantReplyValidator(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		translation string
+		flagged     bool
+	}{
+		{"apology in source", "We are sorry for the delay.", "我们对延误深表抱歉。", false},
+		{"refusal in source", "I cannot attend the meeting.", "我无法出席会议。", false},
+		{"translation announced by source", "Here is the translation of the contract.", "以下是翻译的合同。", false},
+		{"translation announced by model", "The contract is signed.", "以下是翻译：合同已签署。", true},
+		{"ai persona", "The contract is signed.", "作为一个AI，我不能签署合同。", true},
+		{"ai persona in source", "As an AI researcher, I disagree.", "作为一个AI研究者，我不同意。", false},
+		{"prompt delimiter", "The contract is signed.", "<<<BEGIN 1a2b>>>合同已签署。", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				issue := AssistantReplyValidator{}.Validate(tt.source, tt.translation)
+				assert.Equal(t, tt.flagged, issue != nil)
+			},
+		)
+	}
+}
+
+func TestNormalizeNumber(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1,000", "1000"},
+		{"1,000,000.50", "1000000.50"},
+		{"3,5", "3.5"},
+		{"42", "42"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, normalizeNumber(tt.input), tt.input)
+	}
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// lineBreakMarker stands for a line break within a text of a batch, so that every text stays on the line of its
+// marker ⟦Sn⟧ while keeping its line breaks.
+const lineBreakMarker = "⟦BR⟧"
+
+// lineBreakMarkerPattern matches a line break marker in the translation of a batch, along with the blanks around it.
+var lineBreakMarkerPattern = regexp.MustCompile(`[ \t]*⟦BR⟧[ \t]*`)
+
+// FormatSegmentBatch joins independent texts into a single batch to translate in one request, introducing the i-th
+// text with the marker ⟦S(i+1)⟧ and replacing its line breaks with ⟦BR⟧. The batch is translated like a structured
+// chunk, see ChunkRequest.Structured.
+func FormatSegmentBatch(texts []string) string {
+	parts := make([]string, len(texts))
+	for i, text := range texts {
+		text = strings.ReplaceAll(strings.TrimSpace(text), "\r\n", "\n")
+		parts[i] = fmt.Sprintf("⟦S%d⟧ %s", i+1, strings.ReplaceAll(text, "\n", lineBreakMarker))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// ParseSegmentBatch splits the translation of a batch of the texts formatted by FormatSegmentBatch into the
+// translation of every text, in order, restoring their line breaks. The translation of a text is empty if its marker
+// is missing from translated or if it does not keep every line break of the text, so that the text is translated on
+// its own instead.
+func ParseSegmentBatch(translated string, texts []string) []string {
+	segments := parseTranslatedSegments(translated)
+	translations := make([]string, len(texts))
+	for i, text := range texts {
+		lineBreaks := strings.Count(strings.TrimSpace(text), "\n")
+		if strings.Count(segments[i+1], lineBreakMarker) != lineBreaks {
+			continue
+		}
+		translations[i] = lineBreakMarkerPattern.ReplaceAllString(segments[i+1], "\n")
+	}
+	return translations
+}
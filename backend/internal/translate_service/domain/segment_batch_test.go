@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSegmentBatch(t *testing.T) {
+	texts := []string{"Hello\nworld", "Goodbye"}
+	batch := FormatSegmentBatch(texts)
+	assert.Equal(t, "⟦S1⟧ Hello⟦BR⟧world\n\n⟦S2⟧ Goodbye", batch)
+
+	tests := []struct {
+		name       string
+		translated string
+		expected   []string
+	}{
+		{"all segments", "⟦S1⟧ 你好⟦BR⟧世界\n\n⟦S2⟧ 再见", []string{"你好\n世界", "再见"}},
+		{"blanks around line break", "⟦S1⟧ 你好 ⟦BR⟧ 世界\n\n⟦S2⟧ 再见", []string{"你好\n世界", "再见"}},
+		{"line break dropped", "⟦S1⟧ 你好，世界\n\n⟦S2⟧ 再见", []string{"", "再见"}},
+		{"line break added", "⟦S1⟧ 你好⟦BR⟧世界\n\n⟦S2⟧ 再⟦BR⟧见", []string{"你好\n世界", ""}},
+		{"missing segment", "⟦S2⟧ 再见", []string{"", "再见"}},
+		{"unknown segment ignored", "⟦S1⟧ 你好⟦BR⟧世界\n⟦S3⟧ 多余", []string{"你好\n世界", ""}},
+		{"no markers", "你好，世界。再见", []string{"", ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				assert.Equal(t, tt.expected, ParseSegmentBatch(tt.translated, texts))
+			},
+		)
+	}
+}
+
+func TestSegmentBatchMultiLineRoundTrip(t *testing.T) {
+	texts := []string{"First line\r\nSecond line\n\nThird\tline", "Single line"}
+
+	batch := FormatSegmentBatch(texts)
+
+	assert.Equal(t, "⟦S1⟧ First line⟦BR⟧Second line⟦BR⟧⟦BR⟧Third\tline\n\n⟦S2⟧ Single line", batch)
+	assert.Equal(
+		t,
+		[]string{"First line\nSecond line\n\nThird\tline", "Single line"},
+		ParseSegmentBatch(batch, texts),
+	)
+}
@@ -0,0 +1,211 @@
+package domain
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// minPhraseFrequency is how often a lowercase phrase must occur before it is proposed as a term.
+// minNameFrequency is how often a single capitalized word must occur mid-sentence before it is proposed as a name.
+const (
+	minPhraseFrequency = 3
+	minNameFrequency   = 2
+)
+
+// acronymPattern matches acronyms such as "GDPR", "EU" or "COVID-19", optionally in plural.
+// candidateWordPattern matches the words candidates are built from, including inner hyphens and apostrophes.
+var (
+	acronymPattern       = regexp.MustCompile(`\b[A-Z][A-Z0-9]*[A-Z][A-Z0-9]*(?:-\d+)?s?\b`)
+	candidateWordPattern = regexp.MustCompile(`[\p{L}\p{N}]+(?:['’-][\p{L}\p{N}]+)*`)
+)
+
+// nameConnectors may appear inside a multi-word name, as in "Court of Justice".
+var nameConnectors = map[string]bool{"of": true, "for": true, "and": true, "de": true}
+
+// stopWords never start or end a proposed noun phrase.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "and": true, "or": true, "but": true, "of": true, "to": true, "in": true,
+	"on": true, "at": true, "by": true, "for": true, "with": true, "from": true, "as": true, "is": true, "are": true,
+	"was": true, "were": true, "be": true, "been": true, "it": true, "its": true, "this": true, "that": true,
+	"these": true, "those": true, "which": true, "who": true, "not": true, "no": true, "can": true, "may": true,
+	"will": true, "shall": true, "should": true, "would": true, "has": true, "have": true, "had": true, "do": true,
+	"does": true, "such": true, "any": true, "all": true, "each": true, "other": true, "than": true, "into": true,
+	"if": true, "then": true, "there": true, "their": true, "they": true, "we": true, "our": true, "you": true,
+	"under": true, "after": true, "before": true, "during": true, "since": true, "while": true, "when": true,
+	"however": true, "although": true, "because": true, "also": true, "he": true, "she": true, "his": true, "her": true,
+}
+
+// candidate accumulates the occurrences of a proposed term.
+type candidate struct {
+	term  string
+	count int
+	first int
+	boost int
+}
+
+// ExtractCandidates proposes up to limit domain terms of a text: acronyms, capitalized names and frequent noun phrases.
+// Without a part-of-speech tagger, noun phrases are approximated by word pairs and triples that neither start nor end
+// with a stop word and occur at least minPhraseFrequency times. Candidates are ranked by frequency, preferring
+// acronyms and names, and ties keep the order in which the terms first occur.
+func ExtractCandidates(text string, limit int) []string {
+	candidates := make(map[string]*candidate)
+	add := func(term string, offset, boost int) {
+		key := strings.ToLower(term)
+		if c, ok := candidates[key]; ok {
+			c.count++
+			return
+		}
+		candidates[key] = &candidate{term: term, count: 1, first: offset, boost: boost}
+	}
+
+	for _, loc := range acronymPattern.FindAllStringIndex(text, -1) {
+		add(strings.TrimSuffix(text[loc[0]:loc[1]], "s"), loc[0], 2)
+	}
+	for _, sentence := range splitCandidateSentences(text) {
+		collectNames(sentence.text, sentence.offset, add)
+		collectPhrases(sentence.text, sentence.offset, add)
+	}
+
+	var ranked []*candidate
+	for _, c := range candidates {
+		isPhrase := c.boost == 0
+		if (isPhrase && (c.count < minPhraseFrequency || isSubsumed(c, candidates))) ||
+			(c.boost == 1 && !strings.Contains(c.term, " ") && c.count < minNameFrequency) {
+			continue
+		}
+		ranked = append(ranked, c)
+	}
+	sort.Slice(
+		ranked, func(i, j int) bool {
+			if ranked[i].count+ranked[i].boost != ranked[j].count+ranked[j].boost {
+				return ranked[i].count+ranked[i].boost > ranked[j].count+ranked[j].boost
+			}
+			return ranked[i].first < ranked[j].first
+		},
+	)
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	terms := make([]string, 0, len(ranked))
+	for _, c := range ranked {
+		terms = append(terms, c.term)
+	}
+	return terms
+}
+
+// candidateSentence is a sentence of a text with its byte offset in the text.
+type candidateSentence struct {
+	text   string
+	offset int
+}
+
+// splitCandidateSentences splits text at sentence terminators and line breaks, so that capitalized sentence starts can
+// be told apart from names.
+func splitCandidateSentences(text string) []candidateSentence {
+	var sentences []candidateSentence
+	start := 0
+	for i, r := range text {
+		if r == '.' || r == '!' || r == '?' || r == '\n' || r == ';' || r == ':' {
+			sentences = append(sentences, candidateSentence{text: text[start:i], offset: start})
+			start = i + len(string(r))
+		}
+	}
+	return append(sentences, candidateSentence{text: text[start:], offset: start})
+}
+
+// collectNames adds runs of capitalized words to the candidates, leaving out capitalized stop words such as "The".
+// The first word of a sentence only counts as part of a name if the run continues with another capitalized word.
+func collectNames(sentence string, offset int, add func(term string, offset, boost int)) {
+	words := candidateWordPattern.FindAllStringIndex(sentence, -1)
+	for i := 0; i < len(words); {
+		word := sentence[words[i][0]:words[i][1]]
+		if !isCapitalized(word) || stopWords[strings.ToLower(word)] {
+			i++
+			continue
+		}
+		end := i + 1
+		for end < len(words) {
+			word := sentence[words[end][0]:words[end][1]]
+			if isCapitalized(word) {
+				end++
+			} else if nameConnectors[word] && end+1 < len(words) &&
+				isCapitalized(sentence[words[end+1][0]:words[end+1][1]]) {
+				end += 2
+			} else {
+				break
+			}
+		}
+		if end-i > 1 || i > 0 {
+			name := sentence[words[i][0]:words[end-1][1]]
+			if !acronymPattern.MatchString(name) || end-i > 1 {
+				add(name, offset+words[i][0], 1)
+			}
+		}
+		i = end
+	}
+}
+
+// collectPhrases adds every lowercase word pair and triple of a sentence that neither starts nor ends with a stop word
+// to the candidates. Phrases may start with the capitalized first word of the sentence.
+func collectPhrases(sentence string, offset int, add func(term string, offset, boost int)) {
+	words := candidateWordPattern.FindAllStringIndex(sentence, -1)
+	for size := 2; size <= 3; size++ {
+		for i := 0; i+size <= len(words); i++ {
+			first := sentence[words[i][0]:words[i][1]]
+			last := sentence[words[i+size-1][0]:words[i+size-1][1]]
+			if stopWords[strings.ToLower(first)] || stopWords[strings.ToLower(last)] {
+				continue
+			}
+			phrase := strings.Join(strings.Fields(sentence[words[i][0]:words[i+size-1][1]]), " ")
+			if i == 0 {
+				// The capital letter starting a sentence does not make a phrase a name
+				phrase = lowerFirst(phrase)
+			}
+			if !isLowercasePhrase(phrase) {
+				continue
+			}
+			add(phrase, offset+words[i][0], 0)
+		}
+	}
+}
+
+// isSubsumed reports whether a longer phrase containing the phrase of c occurs just as often, in which case c only
+// ever occurs as part of that phrase.
+func isSubsumed(c *candidate, candidates map[string]*candidate) bool {
+	key := " " + strings.ToLower(c.term) + " "
+	for other, o := range candidates {
+		if o != c && o.boost == 0 && o.count >= c.count && strings.Contains(" "+other+" ", key) {
+			return true
+		}
+	}
+	return false
+}
+
+// lowerFirst returns s with its first letter in lower case.
+func lowerFirst(s string) string {
+	for _, r := range s {
+		return string(unicode.ToLower(r)) + s[len(string(r)):]
+	}
+	return s
+}
+
+// isCapitalized reports whether word starts with an upper case letter.
+func isCapitalized(word string) bool {
+	for _, r := range word {
+		return unicode.IsUpper(r)
+	}
+	return false
+}
+
+// isLowercasePhrase reports whether phrase consists of lowercase letters and the separators between its words, which
+// excludes names, numbers and phrases spanning punctuation.
+func isLowercasePhrase(phrase string) bool {
+	for _, r := range phrase {
+		if !unicode.IsLower(r) && r != ' ' && r != '-' && r != '\'' && r != '’' {
+			return false
+		}
+	}
+	return true
+}
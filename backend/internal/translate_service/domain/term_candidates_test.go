@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractCandidates(t *testing.T) {
+	text := "The European Court of Justice ruled on the data protection directive. Under the GDPR, data " +
+		"controllers must report breaches.\nThe controller notified Acme Corp about the data protection directive. " +
+		"Acme Corp appealed, citing the data protection directive and the GDPR.\nIn 2020 the Commission fined " +
+		"Acme Corp. Data controllers objected to the Commission. Data controllers were angry."
+
+	tests := []struct {
+		name     string
+		limit    int
+		expected []string
+	}{
+		{
+			"acronyms, names and phrases", 10,
+			[]string{
+				"GDPR", "Acme Corp", "data protection directive", "data controllers", "Commission",
+				"European Court of Justice",
+			},
+		},
+		{"limited", 2, []string{"GDPR", "Acme Corp"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				assert.Equal(t, tt.expected, ExtractCandidates(text, tt.limit))
+			},
+		)
+	}
+}
+
+func TestExtractCandidatesIgnoresRareWords(t *testing.T) {
+	assert.Empty(t, ExtractCandidates("Yesterday the weather was fine. Tomorrow it will rain.", 10))
+}
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"context"
+	"strings"
+)
+
+// termSeparator separates a source term from its translation on every line of an extracted term sheet.
+const termSeparator = "=>"
+
+// TermSheet lists the key terms and named entities of a document together with the translation chosen for each, so
+// that every chunk translates them the same way. Usage counts the tokens spent extracting them.
+type TermSheet struct {
+	Terms []GlossaryTerm
+	Usage TokenUsage
+}
+
+// TermExtractor is an interface for extracting the key terms and named entities of a text with their translations.
+// ExtractTerms returns the term sheet of the text, or an error as soon as ctx is done or the extraction fails.
+type TermExtractor interface {
+	ExtractTerms(ctx context.Context, text string) (*TermSheet, error)
+}
+
+// TermTranslator is an interface for proposing translations of single terms.
+// TranslateTerms returns a term sheet with the translation proposed for each of the terms, or an error as soon as ctx
+// is done or the request fails.
+type TermTranslator interface {
+	TranslateTerms(ctx context.Context, terms []string) (*TermSheet, error)
+}
+
+// ParseTermLines parses lines of the form "source => target", optionally prefixed by a list marker, into terms.
+// Lines without a separator or with an empty side are ignored.
+func ParseTermLines(s string) []GlossaryTerm {
+	var terms []GlossaryTerm
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimLeft(strings.TrimSpace(line), "-*• ")
+		source, target, found := strings.Cut(line, termSeparator)
+		if !found {
+			continue
+		}
+		source, target = strings.TrimSpace(source), strings.TrimSpace(target)
+		if source == "" || target == "" {
+			continue
+		}
+		terms = append(terms, GlossaryTerm{Source: source, Target: target})
+	}
+	return terms
+}
+
+// MergeTerms combines term lists in order into a single term sheet. The first translation of a source term wins, and
+// terms already covered by the glossary are dropped so that the user's glossary always takes precedence.
+// Source terms are compared case-insensitively.
+func MergeTerms(glossary []GlossaryTerm, lists ...[]GlossaryTerm) []GlossaryTerm {
+	seen := make(map[string]bool, len(glossary))
+	for _, term := range glossary {
+		seen[strings.ToLower(term.Source)] = true
+	}
+	var merged []GlossaryTerm
+	for _, list := range lists {
+		for _, term := range list {
+			key := strings.ToLower(term.Source)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, term)
+		}
+	}
+	return merged
+}
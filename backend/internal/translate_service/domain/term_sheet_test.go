@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTermLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []GlossaryTerm
+	}{
+		{
+			"list of terms",
+			"- Acme Corp => 艾克米公司\n* John Smith=>约翰·史密斯\n",
+			[]GlossaryTerm{{Source: "Acme Corp", Target: "艾克米公司"}, {Source: "John Smith", Target: "约翰·史密斯"}},
+		},
+		{
+			"chatter and empty sides ignored",
+			"Here are the terms:\nfoo =>\n=> bar\nbaz => 巴兹",
+			[]GlossaryTerm{{Source: "baz", Target: "巴兹"}},
+		},
+		{"empty", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				assert.Equal(t, tt.expected, ParseTermLines(tt.input))
+			},
+		)
+	}
+}
+
+func TestMergeTerms(t *testing.T) {
+	glossary := []GlossaryTerm{{Source: "plaintiff", Target: "原告"}}
+	merged := MergeTerms(
+		glossary,
+		[]GlossaryTerm{{Source: "Acme", Target: "艾克米"}, {Source: "Plaintiff", Target: "起诉人"}},
+		[]GlossaryTerm{{Source: "acme", Target: "阿克米"}, {Source: "Berlin", Target: "柏林"}},
+	)
+	assert.Equal(t, []GlossaryTerm{{Source: "Acme", Target: "艾克米"}, {Source: "Berlin", Target: "柏林"}}, merged)
+}
@@ -1,7 +1,63 @@
 package domain
 
+import "context"
+
+// ChunkRequest carries a chunk of text to translate together with the reference material supplied alongside it.
+// PrevContext is the end of the preceding chunk, and Glossary holds the terms that occur in Text.
+// Reference is an optional earlier translation of a similar segment from the translation memory.
+// TermSheet holds the terms of the document's term sheet that occur in Text, whose translations keep the chunks of a
+// document consistent.
+// Structured reports that Text consists of markdown segments introduced by ⟦Sn⟧ markers, whose markers and ⟦Pn⟧
+// placeholders must be kept in the translation.
+// Notes holds optional guidance about Text from the requester, such as where the text appears or how to address the
+// reader.
+// Style selects the register, domain preset and custom instructions of the translation.
+// Feedback lists the quality issues of a rejected earlier translation of Text, which a retry must avoid, and Model
+// optionally overrides the model of the Translator for this request.
+type ChunkRequest struct {
+	PrevContext string
+	Text        string
+	Glossary    []GlossaryTerm
+	Reference   *MemoryMatch
+	TermSheet   []GlossaryTerm
+	Structured  bool
+	Notes       string
+	Style       TranslationStyle
+	Feedback    []string
+	Model       string
+}
+
+// TokenUsage counts the tokens a model consumed for a prompt and produced in its completion.
+type TokenUsage struct {
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+}
+
+// Add returns the sum of two token usages.
+func (u TokenUsage) Add(other TokenUsage) TokenUsage {
+	return TokenUsage{
+		PromptTokens:     u.PromptTokens + other.PromptTokens,
+		CompletionTokens: u.CompletionTokens + other.CompletionTokens,
+		TotalTokens:      u.TotalTokens + other.TotalTokens,
+	}
+}
+
+// ChunkResponse is the translation of a chunk together with the tokens spent producing it.
+// Backend names the backend of a FallbackTranslator that produced Text, and Fallback reports that it is not the
+// primary backend, whose model scopes the translation memory.
+type ChunkResponse struct {
+	Text     string
+	Usage    TokenUsage
+	Backend  string
+	Fallback bool
+}
+
 // Translator is an interface for handling text translation with context awareness.
-// Translate translates the provided text based on the previous context and returns the result or an error if any.
+// Translate translates the text of the request based on its context and glossary and returns the result or an error if any.
+// It stops and returns an error as soon as ctx is done.
+// Model returns the name of the model producing the translations, which scopes the translation memory.
 type Translator interface {
-	Translate(prevContext, text string) (string, error)
+	Translate(ctx context.Context, req ChunkRequest) (*ChunkResponse, error)
+	Model() string
 }
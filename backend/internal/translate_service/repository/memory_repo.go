@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/oOSomnus/transflate/internal/translate_service/domain"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+// RedisTranslationMemory stores translated segments in Redis so that they can be reused by later translations.
+// Every segment is stored twice: under the hash of its normalized text for exact reuse, and under the hash of its
+// skeleton together with its source text for fuzzy matches.
+type RedisTranslationMemory struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewRedisTranslationMemory initializes and returns a new RedisTranslationMemory whose entries expire after ttl.
+func NewRedisTranslationMemory(client *redis.Client, ttl time.Duration) *RedisTranslationMemory {
+	return &RedisTranslationMemory{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+// buildExactKey generates the Redis key holding the translation of a normalized segment.
+func buildExactKey(key domain.MemoryKey, segment string) string {
+	return fmt.Sprintf(
+		"tm:%s:%s:%s:%s", key.SourceLang, key.TargetLang, key.Scope(),
+		domain.HashSegment(domain.NormalizeSegment(segment)),
+	)
+}
+
+// buildFuzzyKey generates the Redis key holding the source and translation of the last segment with the same skeleton.
+func buildFuzzyKey(key domain.MemoryKey, segment string) string {
+	return fmt.Sprintf(
+		"tmf:%s:%s:%s:%s", key.SourceLang, key.TargetLang, key.Scope(),
+		domain.HashSegment(domain.SegmentSkeleton(segment)),
+	)
+}
+
+// Lookup returns the stored translation of segment as an exact match if there is one, and otherwise the last stored
+// segment sharing its skeleton as a fuzzy match. Returns nil if neither exists.
+func (m *RedisTranslationMemory) Lookup(ctx context.Context, key domain.MemoryKey, segment string) (
+	*domain.MemoryMatch, error,
+) {
+	translation, err := m.client.Get(ctx, buildExactKey(key, segment)).Result()
+	if err == nil {
+		return &domain.MemoryMatch{Source: segment, Translation: translation, Exact: true}, nil
+	}
+	if !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	values, err := m.client.HMGet(ctx, buildFuzzyKey(key, segment), "source", "translation").Result()
+	if err != nil {
+		return nil, err
+	}
+	source, _ := values[0].(string)
+	translation, _ = values[1].(string)
+	if source == "" || translation == "" {
+		return nil, nil
+	}
+	return &domain.MemoryMatch{Source: source, Translation: translation, Exact: false}, nil
+}
+
+// Store saves the translation of segment under both its exact and its fuzzy key and refreshes their TTL.
+func (m *RedisTranslationMemory) Store(ctx context.Context, key domain.MemoryKey, segment, translation string) error {
+	fuzzyKey := buildFuzzyKey(key, segment)
+	pipe := m.client.TxPipeline()
+	pipe.Set(ctx, buildExactKey(key, segment), translation, m.ttl)
+	pipe.HSet(ctx, fuzzyKey, "source", domain.NormalizeSegment(segment), "translation", translation)
+	pipe.Expire(ctx, fuzzyKey, m.ttl)
+	_, err := pipe.Exec(ctx)
+	return err
+}
@@ -2,26 +2,266 @@ package server
 
 import (
 	"context"
+	"errors"
 	pb "github.com/oOSomnus/transflate/api/generated/translate"
+	"github.com/oOSomnus/transflate/internal/translate_service/domain"
 	"github.com/oOSomnus/transflate/internal/translate_service/usecase"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"log"
 )
 
 // TranslateServiceServer implements the server API for the TranslateService service.
 // It embeds UnimplementedTranslateServiceServer for forward compatibility.
+// TermUsecase proposes the terminology of documents for review.
 type TranslateServiceServer struct {
 	pb.UnimplementedTranslateServiceServer
+	Usecase     usecase.TranslateUsecase
+	TermUsecase usecase.TermUsecase
+}
+
+// NewTranslateServiceServer initializes and returns a new TranslateServiceServer backed by the provided usecases.
+func NewTranslateServiceServer(u usecase.TranslateUsecase, tu usecase.TermUsecase) *TranslateServiceServer {
+	return &TranslateServiceServer{Usecase: u, TermUsecase: tu}
 }
 
 // ProcessTranslation handles incoming translation requests and returns the translated result or an error if translation fails.
+// The translation is abandoned as soon as the client cancels the request or its deadline passes.
 func (s *TranslateServiceServer) ProcessTranslation(ctx context.Context, req *pb.TranslateRequest) (
 	*pb.TranslateResult, error,
 ) {
+	options, err := toOptions(req)
+	if err != nil {
+		return nil, err
+	}
 	longString := req.Text
-	finalTranslation, err := usecase.TranslateText(longString)
+	translation, err := s.Usecase.TranslateText(ctx, longString, options)
 	if err != nil {
 		log.Println("translation error", err)
 		return nil, err
 	}
-	return &pb.TranslateResult{Lines: finalTranslation}, nil
+	return toPbResult(translation), nil
+}
+
+// TrackTranslation handles incoming translation requests like ProcessTranslation, but reports the chunks translated so
+// far on the stream as the translation progresses, and sends the translated result in a last message.
+// The translation is abandoned as soon as the stream's context is done or a progress message cannot be sent.
+func (s *TranslateServiceServer) TrackTranslation(
+	req *pb.TranslateRequest, stream pb.TranslateService_TrackTranslationServer,
+) error {
+	options, err := toOptions(req)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	translation, err := s.Usecase.TranslateTextWithProgress(
+		ctx, req.Text, options, func(done, total int) error {
+			progress := &pb.TranslateProgress{ChunksDone: uint32(done), ChunksTotal: uint32(total)}
+			if err := stream.Send(progress); err != nil {
+				log.Println("failed to send translation progress", err)
+				return err
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		log.Println("tracked translation error", err)
+		return err
+	}
+	return stream.Send(
+		&pb.TranslateProgress{
+			ChunksDone:  uint32(translation.ChunkCount),
+			ChunksTotal: uint32(translation.ChunkCount),
+			Result:      toPbResult(translation),
+		},
+	)
+}
+
+// toPbResult converts a complete translation into its protobuf representation.
+func toPbResult(translation *usecase.Translation) *pb.TranslateResult {
+	return &pb.TranslateResult{
+		Lines:              translation.Text,
+		GlossaryViolations: toPbViolations(translation.GlossaryViolations),
+		MemoryHits:         uint32(translation.MemoryHits),
+		ChunkCount:         uint32(translation.ChunkCount),
+		Usage:              toPbUsage(translation.Usage),
+		ChunkUsage:         toPbChunkUsage(translation.ChunkUsage),
+		Model:              translation.Model,
+		ElapsedMs:          uint64(translation.Elapsed.Milliseconds()),
+		Segments:           toPbSegments(translation.Segments),
+		TermSheet:          toPbTerms(translation.TermSheet),
+		QualityIssues:      toPbQualityIssues(translation.QualityIssues),
+		RetriedChunks:      uint32(translation.RetriedChunks),
+		FallbackChunks:     uint32(translation.FallbackChunks),
+	}
+}
+
+// ProposeTerms extracts the candidate terms of the requested text, leaving out terms of its glossary, and returns the
+// translations proposed for them along with the tokens spent.
+func (s *TranslateServiceServer) ProposeTerms(ctx context.Context, req *pb.TermRequest) (*pb.TermResult, error) {
+	sheet, err := s.TermUsecase.ProposeTerms(ctx, req.Text, toGlossary(req.Glossary))
+	if err != nil {
+		log.Println("term proposal error", err)
+		return nil, err
+	}
+	return &pb.TermResult{Terms: toPbTerms(sheet.Terms), Usage: toPbUsage(sheet.Usage)}, nil
+}
+
+// TranslateSegments translates the segments of the request and returns exactly one translated segment per segment id,
+// in the order of the request. Segments that fail to translate carry their error instead of a translation.
+// Requests whose segment ids are missing or not unique are rejected with codes.InvalidArgument.
+func (s *TranslateServiceServer) TranslateSegments(ctx context.Context, req *pb.SegmentRequest) (
+	*pb.SegmentResult, error,
+) {
+	segments := make([]usecase.SourceSegment, 0, len(req.Segments))
+	for _, segment := range req.Segments {
+		segments = append(
+			segments, usecase.SourceSegment{
+				ID:      segment.Id,
+				Text:    segment.Text,
+				Context: segment.Context,
+				Notes:   segment.Notes,
+			},
+		)
+	}
+	style, err := toStyle(req.Style)
+	if err != nil {
+		return nil, err
+	}
+	options := usecase.Options{Glossary: toGlossary(req.Glossary), SourceLang: req.SourceLang, Style: style}
+	translation, err := s.Usecase.TranslateSegments(ctx, segments, options)
+	if errors.Is(err, usecase.ErrInvalidSegments) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		log.Println("segment translation error", err)
+		return nil, err
+	}
+
+	result := &pb.SegmentResult{Usage: toPbUsage(translation.Usage), Model: translation.Model}
+	for _, segment := range translation.Segments {
+		translated := &pb.TranslatedSegment{
+			Id:            segment.ID,
+			Translation:   segment.Translation,
+			FromMemory:    segment.FromMemory,
+			QualityIssues: toPbQualityIssues(segment.QualityIssues),
+			Backend:       segment.Backend,
+		}
+		if segment.Err != nil {
+			translated.Error = segment.Err.Error()
+		}
+		result.Segments = append(result.Segments, translated)
+	}
+	return result, nil
+}
+
+// toOptions converts the settings of a translation request into usecase options.
+// Returns an error with codes.InvalidArgument if the style of the request is invalid.
+func toOptions(req *pb.TranslateRequest) (usecase.Options, error) {
+	style, err := toStyle(req.Style)
+	if err != nil {
+		return usecase.Options{}, err
+	}
+	return usecase.Options{
+		Glossary:    toGlossary(req.Glossary),
+		SourceLang:  req.SourceLang,
+		Consistency: req.Consistency,
+		Style:       style,
+	}, nil
+}
+
+// toStyle converts the translation style of a request, which may be nil for the default style.
+// Returns an error with codes.InvalidArgument if the formality or domain preset is unknown or the instructions are too
+// long.
+func toStyle(pbStyle *pb.TranslationStyle) (domain.TranslationStyle, error) {
+	if pbStyle == nil {
+		return domain.TranslationStyle{}, nil
+	}
+	style := domain.TranslationStyle{
+		Formality:    pbStyle.Formality,
+		Domain:       pbStyle.Domain,
+		Instructions: pbStyle.Instructions,
+	}
+	if err := style.Validate(); err != nil {
+		return domain.TranslationStyle{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return style, nil
+}
+
+// toGlossary converts protobuf glossary terms into domain glossary terms.
+func toGlossary(pbTerms []*pb.GlossaryTerm) []domain.GlossaryTerm {
+	glossary := make([]domain.GlossaryTerm, 0, len(pbTerms))
+	for _, term := range pbTerms {
+		glossary = append(glossary, domain.GlossaryTerm{Source: term.Source, Target: term.Target})
+	}
+	return glossary
+}
+
+// toPbTerms converts glossary terms into their protobuf representation, keeping their order.
+func toPbTerms(terms []domain.GlossaryTerm) []*pb.GlossaryTerm {
+	pbTerms := make([]*pb.GlossaryTerm, 0, len(terms))
+	for _, term := range terms {
+		pbTerms = append(pbTerms, &pb.GlossaryTerm{Source: term.Source, Target: term.Target})
+	}
+	return pbTerms
+}
+
+// toPbViolations converts glossary violations into their protobuf representation.
+func toPbViolations(violations []domain.GlossaryViolation) []*pb.GlossaryViolation {
+	pbViolations := make([]*pb.GlossaryViolation, 0, len(violations))
+	for _, violation := range violations {
+		pbViolations = append(
+			pbViolations, &pb.GlossaryViolation{
+				ChunkIndex: uint32(violation.ChunkIndex),
+				Source:     violation.Source,
+				Target:     violation.Target,
+			},
+		)
+	}
+	return pbViolations
+}
+
+// toPbQualityIssues converts quality issues into their protobuf representation.
+func toPbQualityIssues(issues []domain.QualityIssue) []*pb.QualityIssue {
+	pbIssues := make([]*pb.QualityIssue, 0, len(issues))
+	for _, issue := range issues {
+		pbIssues = append(
+			pbIssues, &pb.QualityIssue{
+				ChunkIndex: uint32(issue.ChunkIndex),
+				Check:      issue.Check,
+				Detail:     issue.Detail,
+			},
+		)
+	}
+	return pbIssues
+}
+
+// toPbUsage converts a token usage into its protobuf representation.
+func toPbUsage(usage domain.TokenUsage) *pb.TokenUsage {
+	return &pb.TokenUsage{
+		PromptTokens:     uint32(usage.PromptTokens),
+		CompletionTokens: uint32(usage.CompletionTokens),
+		TotalTokens:      uint32(usage.TotalTokens),
+	}
+}
+
+// toPbChunkUsage converts the token usages of all chunks into their protobuf representation, keeping their order.
+func toPbChunkUsage(chunkUsage []domain.TokenUsage) []*pb.TokenUsage {
+	pbUsage := make([]*pb.TokenUsage, 0, len(chunkUsage))
+	for _, usage := range chunkUsage {
+		pbUsage = append(pbUsage, toPbUsage(usage))
+	}
+	return pbUsage
+}
+
+// toPbSegments converts aligned source and translation segments into their protobuf representation, keeping their order.
+func toPbSegments(segments []usecase.Segment) []*pb.Segment {
+	pbSegments := make([]*pb.Segment, 0, len(segments))
+	for _, segment := range segments {
+		pbSegments = append(
+			pbSegments, &pb.Segment{Source: segment.Source, Translation: segment.Translation, Backend: segment.Backend},
+		)
+	}
+	return pbSegments
 }
@@ -0,0 +1,249 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/oOSomnus/transflate/internal/translate_service/domain"
+	"github.com/oOSomnus/transflate/pkg/utils"
+	"log"
+	"runtime"
+	"sync"
+)
+
+// ErrInvalidSegments is returned when the segments of a request lack an id or share one, since every translation is
+// returned under the id of its segment.
+var ErrInvalidSegments = errors.New("every segment needs a unique, non-empty id")
+
+// SourceSegment is a piece of text to translate, identified by an id chosen by the caller that is stable across
+// requests. Context is optional text surrounding the segment that is not translated, and Notes optional guidance for
+// the translator.
+type SourceSegment struct {
+	ID      string
+	Text    string
+	Context string
+	Notes   string
+}
+
+// TranslatedSegment is the translation of the segment with the same ID.
+// FromMemory reports whether Translation was reused from the translation memory, QualityIssues lists the quality
+// checks Translation fails, and Err is set instead of Translation if the segment could not be translated.
+// Backend names the backend that produced Translation, and fallback reports that it is not the primary one.
+type TranslatedSegment struct {
+	ID            string
+	Translation   string
+	FromMemory    bool
+	QualityIssues []domain.QualityIssue
+	Err           error
+	Backend       string
+	fallback      bool
+}
+
+// SegmentTranslation is the outcome of translating a list of segments.
+// Segments holds exactly one translated segment per source segment, in the order of the request, and Usage counts the
+// tokens spent on all of them with Model.
+type SegmentTranslation struct {
+	Segments []TranslatedSegment
+	Usage    domain.TokenUsage
+	Model    string
+}
+
+// TranslateSegments translates a list of segments and returns their translations keyed by segment id.
+// Segments reused from the translation memory are not translated again. Segments with context or notes are translated
+// one by one with their context and notes, and the others are packed into batches that fit the chunk token budget and
+// translated with one request per batch. A segment whose translation is missing from the batch or fails the quality
+// checks is translated again on its own, so every segment gets an answer even if the model merges or drops segments.
+// A segment that cannot be translated at all is reported with its error instead of failing the request.
+// Returns ErrInvalidSegments if the ids are not unique, or ctx.Err() once ctx is done.
+func (u *TranslateUsecaseImpl) TranslateSegments(
+	ctx context.Context, segments []SourceSegment, options Options,
+) (*SegmentTranslation, error) {
+	ids := make(map[string]bool, len(segments))
+	for _, segment := range segments {
+		if segment.ID == "" || ids[segment.ID] {
+			return nil, ErrInvalidSegments
+		}
+		ids[segment.ID] = true
+	}
+
+	translation := &SegmentTranslation{Segments: make([]TranslatedSegment, len(segments)), Model: u.translator.Model()}
+	memoryKey := u.memoryKey(options)
+	var batched, single []int
+	for i, segment := range segments {
+		translation.Segments[i].ID = segment.ID
+		if segment.Text == "" {
+			continue
+		}
+		if match := u.lookupMemory(ctx, memoryKey, segment.Text); match != nil && match.Exact {
+			glossary := domain.MatchGlossary(options.Glossary, segment.Text)
+			if violations := domain.CheckGlossary(i, glossary, match.Translation); len(violations) == 0 {
+				translation.Segments[i].Translation = match.Translation
+				translation.Segments[i].FromMemory = true
+				continue
+			}
+		}
+		if segment.Context != "" || segment.Notes != "" {
+			single = append(single, i)
+		} else {
+			batched = append(batched, i)
+		}
+	}
+
+	var mu sync.Mutex
+	addUsage := func(usage domain.TokenUsage) {
+		mu.Lock()
+		defer mu.Unlock()
+		translation.Usage = translation.Usage.Add(usage)
+	}
+	apiTokens := make(chan struct{}, max(runtime.NumCPU()*2, 10))
+	var wg sync.WaitGroup
+	translateAlone := func(i int) {
+		defer wg.Done()
+		usage := u.translateSegment(ctx, i, segments[i], options, &translation.Segments[i], apiTokens)
+		addUsage(usage)
+	}
+	for _, i := range single {
+		wg.Add(1)
+		go translateAlone(i)
+	}
+	maxTokens := configuredTokens("translate.chunk.max-tokens", defaultMaxTokensPerChunk)
+	for _, batch := range packSegments(segments, batched, maxTokens) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			failed, usage := u.translateSegmentBatch(ctx, batch, segments, options, translation.Segments, apiTokens)
+			addUsage(usage)
+			for _, i := range failed {
+				wg.Add(1)
+				go translateAlone(i)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range translation.Segments {
+		result := &translation.Segments[i]
+		if result.Err == nil && !result.FromMemory && !result.fallback && len(result.QualityIssues) == 0 &&
+			result.Translation != "" {
+			u.storeMemory(ctx, memoryKey, segments[i].Text, result.Translation)
+		}
+	}
+	return translation, nil
+}
+
+// packSegments groups the segments at the given indexes into batches of at most maxTokens tokens, keeping their
+// order. A segment exceeding the budget on its own forms a batch of its own.
+func packSegments(segments []SourceSegment, indexes []int, maxTokens int) [][]int {
+	var batches [][]int
+	var current []int
+	tokens := 0
+	for _, i := range indexes {
+		segmentTokens := utils.CountTokens(segments[i].Text)
+		if len(current) > 0 && tokens+segmentTokens > maxTokens {
+			batches = append(batches, current)
+			current, tokens = nil, 0
+		}
+		current = append(current, i)
+		tokens += segmentTokens
+	}
+	if len(current) > 0 {
+		batches = append(batches, current)
+	}
+	return batches
+}
+
+// translateSegmentBatch translates the segments at the indexes of batch with a single request and stores every
+// translation that is present and passes the quality checks in results. It returns the indexes of the other segments,
+// which must be translated on their own, along with the tokens spent. A batch of a single segment is translated on its
+// own right away.
+func (u *TranslateUsecaseImpl) translateSegmentBatch(
+	ctx context.Context,
+	batch []int,
+	segments []SourceSegment,
+	options Options,
+	results []TranslatedSegment,
+	apiTokens chan struct{},
+) ([]int, domain.TokenUsage) {
+	if len(batch) == 1 {
+		return batch, domain.TokenUsage{}
+	}
+	texts := make([]string, len(batch))
+	var glossary []domain.GlossaryTerm
+	for j, i := range batch {
+		texts[j] = segments[i].Text
+		glossary = append(glossary, domain.MatchGlossary(options.Glossary, segments[i].Text)...)
+	}
+
+	select {
+	case apiTokens <- struct{}{}:
+	case <-ctx.Done():
+		return nil, domain.TokenUsage{}
+	}
+	response, err := u.translator.Translate(
+		ctx, domain.ChunkRequest{
+			Text:       domain.FormatSegmentBatch(texts),
+			Glossary:   domain.MergeTerms(nil, glossary),
+			Structured: true,
+			Style:      options.Style,
+		},
+	)
+	<-apiTokens
+	if err != nil {
+		log.Printf("Error translating batch of %d segments: %v\n", len(batch), err)
+		return batch, domain.TokenUsage{}
+	}
+
+	var failed []int
+	for j, translated := range domain.ParseSegmentBatch(response.Text, texts) {
+		i := batch[j]
+		if translated == "" || len(domain.CheckQuality(i, u.validators, segments[i].Text, translated)) > 0 {
+			failed = append(failed, i)
+			continue
+		}
+		results[i].Translation = translated
+		results[i].Backend = response.Backend
+		results[i].fallback = response.Fallback
+	}
+	return failed, response.Usage
+}
+
+// translateSegment translates the segment at index on its own, with its context, notes and the matching glossary terms,
+// retrying translations that fail the quality checks, and stores the outcome in result. Returns the tokens spent.
+func (u *TranslateUsecaseImpl) translateSegment(
+	ctx context.Context,
+	index int,
+	segment SourceSegment,
+	options Options,
+	result *TranslatedSegment,
+	apiTokens chan struct{},
+) domain.TokenUsage {
+	select {
+	case apiTokens <- struct{}{}:
+	case <-ctx.Done():
+		result.Err = ctx.Err()
+		return domain.TokenUsage{}
+	}
+	defer func() { <-apiTokens }()
+	response, issues, _, err := u.translateWithRetries(
+		ctx, index, domain.ChunkRequest{
+			PrevContext: segment.Context,
+			Text:        segment.Text,
+			Glossary:    domain.MatchGlossary(options.Glossary, segment.Text),
+			Notes:       segment.Notes,
+			Style:       options.Style,
+		},
+	)
+	if err != nil {
+		log.Printf("Error translating segment %s: %v\n", segment.ID, err)
+		result.Err = fmt.Errorf("translating segment %s: %w", segment.ID, err)
+		return domain.TokenUsage{}
+	}
+	result.Translation = response.Text
+	result.QualityIssues = issues
+	result.Backend = response.Backend
+	result.fallback = response.Fallback
+	return response.Usage
+}
@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/oOSomnus/transflate/internal/translate_service/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+// batchTranslator upper-cases every segment of a batch except those containing "drop", which it leaves out, and
+// records the requests it receives.
+type batchTranslator struct {
+	mu       sync.Mutex
+	requests []domain.ChunkRequest
+}
+
+func (b *batchTranslator) Translate(ctx context.Context, req domain.ChunkRequest) (*domain.ChunkResponse, error) {
+	b.mu.Lock()
+	b.requests = append(b.requests, req)
+	b.mu.Unlock()
+	if !req.Structured {
+		return &domain.ChunkResponse{Text: strings.ToUpper(req.Text), Usage: domain.TokenUsage{TotalTokens: 1}}, nil
+	}
+	var parts []string
+	for _, part := range strings.Split(req.Text, "\n\n") {
+		if !strings.Contains(part, "drop") {
+			parts = append(parts, strings.ToUpper(part))
+		}
+	}
+	return &domain.ChunkResponse{Text: strings.Join(parts, "\n"), Usage: domain.TokenUsage{TotalTokens: 10}}, nil
+}
+
+func (b *batchTranslator) Model() string {
+	return "batch"
+}
+
+func TestTranslateSegments(t *testing.T) {
+	memory := &mapMemory{entries: map[string]string{"batch:cached": "from memory"}}
+	translator := &batchTranslator{}
+	u := NewTranslateUsecase(translator, memory, nil, nil)
+	segments := []SourceSegment{
+		{ID: "title", Text: "hello"},
+		{ID: "body", Text: "drop me"},
+		{ID: "button", Text: "save", Context: "a form", Notes: "imperative"},
+		{ID: "footer", Text: "cached"},
+		{ID: "empty"},
+		{ID: "caption", Text: "world\nwide"},
+	}
+
+	translation, err := u.TranslateSegments(context.Background(), segments, Options{})
+	assert.NoError(t, err)
+	got := make(map[string]string)
+	for i, segment := range translation.Segments {
+		assert.Equal(t, segments[i].ID, segment.ID)
+		assert.NoError(t, segment.Err)
+		got[segment.ID] = segment.Translation
+	}
+	assert.Equal(
+		t, map[string]string{
+			"title": "HELLO", "body": "DROP ME", "button": "SAVE", "footer": "from memory", "empty": "", "caption": "WORLD\nWIDE",
+		}, got,
+	)
+	assert.True(t, translation.Segments[3].FromMemory)
+	// One batch, the segment dropped from it and the segment with context and notes
+	assert.Len(t, translator.requests, 3)
+	assert.Equal(t, domain.TokenUsage{TotalTokens: 12}, translation.Usage)
+	for _, req := range translator.requests {
+		if req.Text == "save" {
+			assert.Equal(t, "a form", req.PrevContext)
+			assert.Equal(t, "imperative", req.Notes)
+		}
+	}
+	assert.Equal(t, "DROP ME", memory.entries["batch:drop me"])
+}
+
+func TestTranslateSegmentsRejectsInvalidIDs(t *testing.T) {
+	tests := []struct {
+		name     string
+		segments []SourceSegment
+	}{
+		{"missing id", []SourceSegment{{Text: "a"}}},
+		{"duplicate id", []SourceSegment{{ID: "a", Text: "a"}, {ID: "a", Text: "b"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				u := NewTranslateUsecase(&batchTranslator{}, nil, nil, nil)
+				_, err := u.TranslateSegments(context.Background(), tt.segments, Options{})
+				assert.ErrorIs(t, err, ErrInvalidSegments)
+			},
+		)
+	}
+}
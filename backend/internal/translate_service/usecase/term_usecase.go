@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"github.com/oOSomnus/transflate/internal/translate_service/domain"
+	"github.com/spf13/viper"
+	"strings"
+)
+
+// defaultMaxCandidates defines how many candidate terms are proposed when translate.terms.max-candidates is not
+// configured.
+const defaultMaxCandidates = 40
+
+// TermUsecase defines the operations for preparing the terminology of a document before it is translated.
+// ProposeTerms extracts the candidate terms of a document and proposes a translation for each of them.
+type TermUsecase interface {
+	ProposeTerms(ctx context.Context, text string, glossary []domain.GlossaryTerm) (*domain.TermSheet, error)
+}
+
+// TermUsecaseImpl proposes the terminology of documents, translating candidate terms with a TermTranslator.
+type TermUsecaseImpl struct {
+	translator domain.TermTranslator
+}
+
+// NewTermUsecase initializes and returns a new TermUsecaseImpl with the provided TermTranslator.
+func NewTermUsecase(translator domain.TermTranslator) *TermUsecaseImpl {
+	return &TermUsecaseImpl{translator: translator}
+}
+
+// ProposeTerms extracts acronyms, names and frequent noun phrases of text as candidate terms, leaving out those already
+// covered by the glossary, and returns the translations proposed for them in the order of the candidates.
+// Proposals for terms that were not asked for are dropped, and an empty term sheet is returned without calling the
+// TermTranslator if the text has no candidates.
+func (u *TermUsecaseImpl) ProposeTerms(
+	ctx context.Context, text string, glossary []domain.GlossaryTerm,
+) (*domain.TermSheet, error) {
+	limit := viper.GetInt("translate.terms.max-candidates")
+	if limit <= 0 {
+		limit = defaultMaxCandidates
+	}
+	covered := make(map[string]bool, len(glossary))
+	for _, term := range glossary {
+		covered[strings.ToLower(term.Source)] = true
+	}
+	var candidates []string
+	for _, candidate := range domain.ExtractCandidates(text, limit) {
+		if !covered[strings.ToLower(candidate)] {
+			candidates = append(candidates, candidate)
+		}
+	}
+	if len(candidates) == 0 {
+		return &domain.TermSheet{}, nil
+	}
+
+	proposed, err := u.translator.TranslateTerms(ctx, candidates)
+	if err != nil {
+		return nil, err
+	}
+	translations := make(map[string]string, len(proposed.Terms))
+	for _, term := range proposed.Terms {
+		translations[strings.ToLower(term.Source)] = term.Target
+	}
+	sheet := &domain.TermSheet{Usage: proposed.Usage}
+	for _, candidate := range candidates {
+		if target, ok := translations[strings.ToLower(candidate)]; ok {
+			sheet.Terms = append(sheet.Terms, domain.GlossaryTerm{Source: candidate, Target: target})
+		}
+	}
+	return sheet, nil
+}
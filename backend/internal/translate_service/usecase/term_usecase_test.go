@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/oOSomnus/transflate/internal/translate_service/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+// upperTermTranslator proposes the upper-cased term as translation, skipping terms listed in skip.
+type upperTermTranslator struct {
+	asked []string
+	skip  string
+}
+
+func (u *upperTermTranslator) TranslateTerms(ctx context.Context, terms []string) (*domain.TermSheet, error) {
+	u.asked = terms
+	sheet := &domain.TermSheet{Usage: domain.TokenUsage{TotalTokens: len(terms)}}
+	for _, term := range terms {
+		if term != u.skip {
+			sheet.Terms = append(sheet.Terms, domain.GlossaryTerm{Source: term, Target: strings.ToUpper(term)})
+		}
+	}
+	sheet.Terms = append(sheet.Terms, domain.GlossaryTerm{Source: "unrequested", Target: "UNREQUESTED"})
+	return sheet, nil
+}
+
+func TestProposeTerms(t *testing.T) {
+	text := "Acme Corp sued. Acme Corp lost under the GDPR. The GDPR applies to Acme Corp."
+	translator := &upperTermTranslator{skip: "Acme Corp"}
+	u := NewTermUsecase(translator)
+
+	glossary := []domain.GlossaryTerm{{Source: "gdpr", Target: "通用数据保护条例"}}
+	sheet, err := u.ProposeTerms(context.Background(), text, glossary)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Acme Corp"}, translator.asked)
+	assert.Empty(t, sheet.Terms)
+	assert.Equal(t, 1, sheet.Usage.TotalTokens)
+
+	translator.skip = ""
+	sheet, err = u.ProposeTerms(context.Background(), text, nil)
+	assert.NoError(t, err)
+	assert.Equal(
+		t, []domain.GlossaryTerm{{Source: "Acme Corp", Target: "ACME CORP"}, {Source: "GDPR", Target: "GDPR"}}, sheet.Terms,
+	)
+}
+
+func TestProposeTermsWithoutCandidates(t *testing.T) {
+	translator := &upperTermTranslator{}
+	sheet, err := NewTermUsecase(translator).ProposeTerms(context.Background(), "Nothing to see here.", nil)
+	assert.NoError(t, err)
+	assert.Empty(t, sheet.Terms)
+	assert.Nil(t, translator.asked)
+}
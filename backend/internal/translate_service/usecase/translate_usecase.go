@@ -1,97 +1,608 @@
 package usecase
 
 import (
+	"context"
+	"errors"
 	"github.com/oOSomnus/transflate/internal/translate_service/domain"
 	"github.com/oOSomnus/transflate/pkg/utils"
+	"github.com/spf13/viper"
 	"log"
 	"runtime"
 	"strings"
 	"sync"
+	"time"
 )
 
-// maxWordsPerChunk defines the maximum number of words allowed per chunk.
-// contextWordCount specifies the limit on the number of words for context.
+// defaultMaxTokensPerChunk defines the token budget of a chunk when translate.chunk.max-tokens is not configured.
+// defaultContextTokens defines the token budget of the context when translate.context.max-tokens is not configured.
+// defaultExtractionTokens defines the token budget of a part of the document sent to the TermExtractor when
+// translate.consistency.max-tokens is not configured.
+// All budgets are approximate: they are measured with utils.CountTokens, a heuristic that may be off by tens of percent
+// from the tokenizer of the model, so they should leave a margin below the context window of the model.
 const (
-	maxWordsPerChunk = 1000 // max num of words per chunk
-	contextWordCount = 50   // num of words provided as context
+	defaultMaxTokensPerChunk = 1500 // max num of tokens per chunk
+	defaultContextTokens     = 80   // num of tokens provided as context
+	defaultExtractionTokens  = 6000
 )
 
+// unknownLanguage is used as the source language of translation memory entries when a request does not specify one.
+// memoryTimeout bounds every translation memory operation so that a slow store never stalls a translation.
+// defaultQualityRetries is how often a chunk failing the quality checks is retranslated when
+// translate.quality.max-retries is not configured.
+const (
+	unknownLanguage       = "und"
+	memoryTimeout         = 5 * time.Second
+	defaultQualityRetries = 1
+)
+
+// Options holds the per-request settings applied while translating a document.
+// Glossary lists the terms that must be translated consistently wherever they occur.
+// SourceLang is the language of the document, which together with the target language and model scopes the
+// translation memory.
+// Consistency enables a first pass extracting a term sheet of the document, whose terms are supplied to every chunk.
+// Style selects the register, domain preset and custom instructions of the translation; translations in different
+// styles are kept apart in the translation memory.
+type Options struct {
+	Glossary    []domain.GlossaryTerm
+	SourceLang  string
+	Consistency bool
+	Style       domain.TranslationStyle
+	termSheet   []domain.GlossaryTerm
+	structured  bool
+}
+
+// Translation is the outcome of translating a whole document.
+// MemoryHits counts the chunks reused from the translation memory out of ChunkCount chunks in total.
+// Usage is the total token usage of the document, ChunkUsage the usage of every chunk in document order, Model the
+// model that translated it and Elapsed the wall time the translation took.
+// Segments pairs every source chunk with its translation in document order, so that both can be laid out side by side.
+// TermSheet lists the terms extracted in consistency mode with the translations every chunk was asked to use; the
+// tokens spent extracting them are included in Usage but not in ChunkUsage.
+// QualityIssues lists the quality checks the delivered translations still fail, and RetriedChunks counts the chunks
+// that were retranslated because of failed checks. FallbackChunks counts the chunks translated by a fallback backend
+// rather than the primary one.
+type Translation struct {
+	Text               string
+	GlossaryViolations []domain.GlossaryViolation
+	MemoryHits         int
+	ChunkCount         int
+	Usage              domain.TokenUsage
+	ChunkUsage         []domain.TokenUsage
+	Model              string
+	Elapsed            time.Duration
+	Segments           []Segment
+	TermSheet          []domain.GlossaryTerm
+	QualityIssues      []domain.QualityIssue
+	RetriedChunks      int
+	FallbackChunks     int
+}
+
+// Segment is a source chunk aligned with its translation, and Backend names the backend that translated it.
+type Segment struct {
+	Source      string
+	Translation string
+	Backend     string
+}
+
+// TranslatedChunk is a single translated chunk with its position in the document and the total number of chunks.
+// Source is the text of the chunk before translation.
+// GlossaryViolations lists the glossary terms of the chunk whose required translation is missing from Text.
+// FromMemory reports whether Text was reused from the translation memory instead of being translated.
+// Usage counts the tokens spent translating the chunk, which is zero for chunks reused from memory.
+// QualityIssues lists the quality checks Text fails, and Attempts counts the translations requested for the chunk,
+// which exceeds 1 if earlier translations failed the checks.
+// Backend names the backend of a FallbackTranslator that produced Text, and Fallback reports that it is not the
+// primary one.
+type TranslatedChunk struct {
+	Index              int
+	Total              int
+	Source             string
+	Text               string
+	GlossaryViolations []domain.GlossaryViolation
+	FromMemory         bool
+	Usage              domain.TokenUsage
+	QualityIssues      []domain.QualityIssue
+	Attempts           int
+	Backend            string
+	Fallback           bool
+}
+
+// ChunkHandler receives translated chunks in document order.
+// Returning an error stops the delivery of any further chunks.
+type ChunkHandler func(chunk TranslatedChunk) error
+
+// ProgressHandler receives the number of chunks of a document translated so far out of the total number of chunks.
+// Returning an error stops the translation of the document.
+type ProgressHandler func(done, total int) error
+
+// chunkResult holds the outcome of translating the chunk at index.
+type chunkResult struct {
+	index      int
+	text       string
+	violations []domain.GlossaryViolation
+	fromMemory bool
+	usage      domain.TokenUsage
+	issues     []domain.QualityIssue
+	attempts   int
+	backend    string
+	fallback   bool
+	err        error
+}
+
+// TranslateUsecase defines the operations for translating documents.
+// TranslateText translates a whole document and returns the complete translation.
+// TranslateTextWithProgress works like TranslateText and reports the progress of the translation as chunks complete.
+// TranslateTextInOrder translates a document and passes every translated chunk to a handler in document order.
+// TranslateSegments translates a list of segments and returns exactly one translation per segment id.
+// Cancelling ctx stops all of them: chunks waiting for a worker are never sent, in-flight Translator calls are aborted
+// and ctx.Err() is returned.
+type TranslateUsecase interface {
+	TranslateText(ctx context.Context, longString string, options Options) (*Translation, error)
+	TranslateTextWithProgress(
+		ctx context.Context, longString string, options Options, progress ProgressHandler,
+	) (*Translation, error)
+	TranslateTextInOrder(ctx context.Context, longString string, options Options, handle ChunkHandler) error
+	TranslateSegments(ctx context.Context, segments []SourceSegment, options Options) (*SegmentTranslation, error)
+}
+
+// TranslateUsecaseImpl translates documents chunk by chunk with a Translator, reusing earlier translations from an
+// optional TranslationMemory and extracting term sheets with an optional TermExtractor. Every new translation is
+// checked by the validators, and chunks failing them are retranslated.
+type TranslateUsecaseImpl struct {
+	translator domain.Translator
+	memory     domain.TranslationMemory
+	extractor  domain.TermExtractor
+	validators []domain.QualityValidator
+}
+
+// NewTranslateUsecase initializes and returns a new TranslateUsecaseImpl. memory may be nil to disable the translation
+// memory, extractor may be nil to ignore requests for consistency mode, and validators may be empty to skip the
+// quality checks.
+func NewTranslateUsecase(
+	translator domain.Translator,
+	memory domain.TranslationMemory,
+	extractor domain.TermExtractor,
+	validators []domain.QualityValidator,
+) *TranslateUsecaseImpl {
+	return &TranslateUsecaseImpl{translator: translator, memory: memory, extractor: extractor, validators: validators}
+}
+
 // TranslateText splits a long string into smaller chunks, translates each chunk in parallel, and returns the full translation.
-func TranslateText(longString string) (string, error) {
-	chunks := utils.SplitString(longString, maxWordsPerChunk)
+func (u *TranslateUsecaseImpl) TranslateText(ctx context.Context, longString string, options Options) (
+	*Translation, error,
+) {
+	return u.TranslateTextWithProgress(ctx, longString, options, nil)
+}
 
-	// Initialize parallel processing workers
-	maxNumTokens := max(runtime.NumCPU()*2, 10)
-	apiTokens := make(chan struct{}, maxNumTokens)
+// TranslateTextWithProgress translates a long string like TranslateText. When progress is not nil it is called each
+// time a chunk is added to the translation in document order, so the chunks done only grow once all chunks before
+// them are translated. An error returned by progress stops the translation and is returned as is; chunks still being
+// translated are only aborted once ctx is cancelled.
+func (u *TranslateUsecaseImpl) TranslateTextWithProgress(
+	ctx context.Context, longString string, options Options, progress ProgressHandler,
+) (*Translation, error) {
+	start := time.Now()
+	sheet := u.extractTermSheet(ctx, longString, options)
+	options.termSheet = sheet.Terms
+	translation := &Translation{Model: u.translator.Model(), Usage: sheet.Usage, TermSheet: sheet.Terms}
+	var translatedChunks []string
+	err := u.translateDocument(
+		ctx, longString, options, func(chunk TranslatedChunk) error {
+			translatedChunks = append(translatedChunks, chunk.Text)
+			translation.GlossaryViolations = append(translation.GlossaryViolations, chunk.GlossaryViolations...)
+			translation.ChunkCount = chunk.Total
+			if chunk.FromMemory {
+				translation.MemoryHits++
+			}
+			translation.Usage = translation.Usage.Add(chunk.Usage)
+			translation.ChunkUsage = append(translation.ChunkUsage, chunk.Usage)
+			translation.Segments = append(
+				translation.Segments, Segment{Source: chunk.Source, Translation: chunk.Text, Backend: chunk.Backend},
+			)
+			translation.QualityIssues = append(translation.QualityIssues, chunk.QualityIssues...)
+			if chunk.Attempts > 1 {
+				translation.RetriedChunks++
+			}
+			if chunk.Fallback {
+				translation.FallbackChunks++
+			}
+			if progress != nil {
+				return progress(chunk.Index+1, chunk.Total)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
 
-	translatedChunks, translationErrors := initResults(len(chunks))
+	translation.Text = strings.Join(translatedChunks, "\n")
+	translation.Elapsed = time.Since(start)
+	return translation, nil
+}
 
-	translator := domain.NewGPTTranslator()
-	var wg sync.WaitGroup
+// TranslateTextInOrder splits a long string into chunks and translates them in parallel, passing each translated chunk
+// to handle in document order as soon as every chunk before it has been translated.
+// In consistency mode the term sheet of the document is extracted before any chunk is translated.
+func (u *TranslateUsecaseImpl) TranslateTextInOrder(
+	ctx context.Context, longString string, options Options, handle ChunkHandler,
+) error {
+	options.termSheet = u.extractTermSheet(ctx, longString, options).Terms
+	return u.translateDocument(ctx, longString, options, handle)
+}
 
-	for i, chunk := range chunks {
+// extractTermSheet runs the first pass of consistency mode: the document is split into parts that fit the extraction
+// token budget, the terms of all parts are extracted in parallel and merged in document order, keeping the first
+// translation of every term and leaving out terms of the glossary.
+// Parts failing to extract are logged and skipped, since consistency mode only improves the translation. An empty
+// term sheet is returned if consistency mode is off or no TermExtractor is configured.
+func (u *TranslateUsecaseImpl) extractTermSheet(
+	ctx context.Context, longString string, options Options,
+) *domain.TermSheet {
+	if !options.Consistency || u.extractor == nil {
+		return &domain.TermSheet{}
+	}
+	parts := utils.ChunkText(longString, configuredTokens("translate.consistency.max-tokens", defaultExtractionTokens))
+	sheets := make([]*domain.TermSheet, len(parts))
+	var wg sync.WaitGroup
+	for i, part := range parts {
 		wg.Add(1)
-		go processChunk(i, chunks, chunk, translator, translatedChunks, translationErrors, apiTokens, &wg)
+		go func() {
+			defer wg.Done()
+			sheet, err := u.extractor.ExtractTerms(ctx, part)
+			if err != nil {
+				log.Printf("Error extracting terms of part %d: %v\n", i, err)
+				return
+			}
+			sheets[i] = sheet
+		}()
 	}
-
 	wg.Wait()
 
-	reportErrors(translationErrors)
+	merged := &domain.TermSheet{}
+	lists := make([][]domain.GlossaryTerm, 0, len(sheets))
+	for _, sheet := range sheets {
+		if sheet == nil {
+			continue
+		}
+		lists = append(lists, sheet.Terms)
+		merged.Usage = merged.Usage.Add(sheet.Usage)
+	}
+	merged.Terms = domain.MergeTerms(options.Glossary, lists...)
+	return merged
+}
+
+// translateDocument translates a document in chunks, passing each translated chunk to handle in document order.
+// Documents with markdown structure are translated by their text nodes only, see translateMarkdown.
+func (u *TranslateUsecaseImpl) translateDocument(
+	ctx context.Context, longString string, options Options, handle ChunkHandler,
+) error {
+	maxTokens := configuredTokens("translate.chunk.max-tokens", defaultMaxTokensPerChunk)
+	if doc := domain.ParseMarkdown(longString); doc.Structured {
+		return u.translateMarkdown(ctx, doc, maxTokens, options, handle)
+	}
+	return u.translateChunks(ctx, utils.ChunkText(longString, maxTokens), options, handle)
+}
+
+// translateMarkdown translates a markdown document without letting the Translator see its markup.
+// Only the segments of the document are sent for translation, packed into chunks of whole lines, and every translated
+// chunk is rendered back into the markdown source it covers, so headings, lists, code blocks, links and tables are kept
+// as they are. A document without any text to translate is delivered unchanged as a single chunk.
+func (u *TranslateUsecaseImpl) translateMarkdown(
+	ctx context.Context,
+	doc *domain.MarkdownDocument,
+	maxTokens int,
+	options Options,
+	handle ChunkHandler,
+) error {
+	chunks := splitMarkdown(doc, maxTokens)
+	if len(doc.Segments) == 0 {
+		source := doc.SourceOf(chunks[0])
+		return handle(TranslatedChunk{Index: 0, Total: 1, Source: source, Text: source})
+	}
 
-	finalTranslation := strings.Join(translatedChunks, "\n")
-	return finalTranslation, nil
+	texts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		texts[i] = doc.FormatChunk(chunk)
+	}
+	options.structured = true
+	return u.translateChunks(
+		ctx, texts, options, func(translated TranslatedChunk) error {
+			chunk := chunks[translated.Index]
+			translated.Source = doc.SourceOf(chunk)
+			translated.Text = doc.RenderChunk(chunk, translated.Text)
+			return handle(translated)
+		},
+	)
 }
 
-var mutex = &sync.Mutex{}
+// splitMarkdown packs the segments of a markdown document into chunks of at most maxTokens tokens, unless a single
+// segment exceeds the budget. Chunks only start at the beginning of a line that no earlier segment extends into, and
+// together with the newline separating them the chunks cover the whole source.
+func splitMarkdown(doc *domain.MarkdownDocument, maxTokens int) []domain.MarkdownChunk {
+	chunks := []domain.MarkdownChunk{{}}
+	tokens := 0
+	for i, segment := range doc.Segments {
+		segmentTokens := utils.CountTokens(segment.Template)
+		current := &chunks[len(chunks)-1]
+		canSplit := i > 0 && segment.LineStart > 0 && segment.LineStart >= doc.Segments[i-1].End
+		if canSplit && tokens > 0 && tokens+segmentTokens > maxTokens {
+			current.To = segment.LineStart - 1
+			chunks = append(chunks, domain.MarkdownChunk{From: segment.LineStart, First: i})
+			current = &chunks[len(chunks)-1]
+			tokens = 0
+		}
+		current.Last = i + 1
+		tokens += segmentTokens
+	}
+	chunks[len(chunks)-1].To = doc.Len()
+	return chunks
+}
+
+// configuredTokens returns the positive token budget configured under key, or fallback if it is not set.
+// Budgets are compared against the estimate of utils.CountTokens, not against the exact tokens of the model.
+func configuredTokens(key string, fallback int) int {
+	if tokens := viper.GetInt(key); tokens > 0 {
+		return tokens
+	}
+	return fallback
+}
 
-// processChunk processes a single text chunk by translating it using the provided Translator and updates the results concurrently.
+// translateChunks translates all chunks concurrently and hands the results to handle in order.
+// Chunks that fail to translate are logged and delivered as empty strings so that the document order is preserved.
+// Once ctx is done no further chunks are delivered and ctx.Err() is returned.
+func (u *TranslateUsecaseImpl) translateChunks(
+	ctx context.Context, chunks []string, options Options, handle ChunkHandler,
+) error {
+	// Initialize parallel processing workers
+	maxNumTokens := max(runtime.NumCPU()*2, 10)
+	apiTokens := make(chan struct{}, maxNumTokens)
+
+	// Buffered so that workers never block on delivery, even if handle stops early
+	results := make(chan chunkResult, len(chunks))
+	for i, chunk := range chunks {
+		go u.processChunk(ctx, i, chunks, chunk, options, results, apiTokens)
+	}
+
+	// Deliver the contiguous prefix of finished chunks every time a new result arrives
+	pending := make(map[int]chunkResult)
+	next := 0
+	for received := 0; received < len(chunks); received++ {
+		var result chunkResult
+		select {
+		case result = <-results:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		pending[result.index] = result
+		for {
+			ready, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if ready.err != nil {
+				log.Printf("Error translating chunk %d: %v\n", ready.index, ready.err)
+			}
+			translated := TranslatedChunk{
+				Index:              next,
+				Total:              len(chunks),
+				Source:             chunks[next],
+				Text:               ready.text,
+				GlossaryViolations: ready.violations,
+				FromMemory:         ready.fromMemory,
+				Usage:              ready.usage,
+				QualityIssues:      ready.issues,
+				Attempts:           ready.attempts,
+				Backend:            ready.backend,
+				Fallback:           ready.fallback,
+			}
+			if err := handle(translated); err != nil {
+				return err
+			}
+			next++
+		}
+	}
+	return nil
+}
+
+// processChunk processes a single text chunk by translating it using the Translator and reports the outcome.
+// An exact translation memory match that satisfies both the glossary and the term sheet is reused without translating;
+// any other match is supplied to the Translator as a reference. New translations are checked for quality, see
+// translateWithRetries, and stored in the memory only if they pass every check and come from the primary backend.
 // index specifies the position of the chunk in the chunks slice.
 // chunks contains all text chunks to be processed.
 // chunk is the specific text chunk being processed.
-// translator is an instance of a domain.Translator to handle the translation task.
-// translatedChunks is an array to store the translated output of each chunk.
-// translationErrors is an array to store any error encountered during translation of each chunk.
-// workersPool is a channel used to manage the pool of goroutines processing chunks concurrently.
-// wg is a WaitGroup used to track the completion of goroutine processing for synchronization.
-func processChunk(
+// options holds the glossary whose matching terms are supplied with the chunk and checked in its translation, and the
+// term sheet whose matching terms are supplied with the chunk.
+// results is the channel the translated output or error of the chunk is sent to.
+// apiTokens is a channel used to limit the number of concurrent translation requests.
+// ctx cancels the chunk while it waits for a worker as well as the Translator call itself.
+func (u *TranslateUsecaseImpl) processChunk(
+	ctx context.Context,
 	index int,
 	chunks []string,
 	chunk string,
-	translator domain.Translator,
-	translatedChunks []string,
-	translationErrors []error,
+	options Options,
+	results chan<- chunkResult,
 	apiTokens chan struct{},
-	wg *sync.WaitGroup,
 ) {
-	defer wg.Done()
+	if err := ctx.Err(); err != nil {
+		results <- chunkResult{index: index, err: err}
+		return
+	}
+	glossary := domain.MatchGlossary(options.Glossary, chunk)
+	termSheet := domain.MatchGlossary(options.termSheet, chunk)
+	memoryKey := u.memoryKey(options)
+	match := u.lookupMemory(ctx, memoryKey, chunk)
+	if match != nil && match.Exact {
+		required := append(append([]domain.GlossaryTerm{}, glossary...), termSheet...)
+		if violations := domain.CheckGlossary(index, required, match.Translation); len(violations) == 0 {
+			results <- chunkResult{index: index, text: match.Translation, fromMemory: true}
+			return
+		}
+	}
+
+	select {
+	case apiTokens <- struct{}{}:
+	case <-ctx.Done():
+		results <- chunkResult{index: index, err: ctx.Err()}
+		return
+	}
 	defer func() { <-apiTokens }() // 释放 worker
-	apiTokens <- struct{}{}
-	prevContext := getPreviousContext(index, chunks)
-	result, err := translator.Translate(prevContext, chunk)
-	mutex.Lock()
-	translatedChunks[index] = result
-	translationErrors[index] = err
-	mutex.Unlock()
+	request := domain.ChunkRequest{
+		PrevContext: getPreviousContext(index, chunks),
+		Text:        chunk,
+		Glossary:    glossary,
+		Reference:   match,
+		TermSheet:   termSheet,
+		Structured:  options.structured,
+		Style:       options.Style,
+	}
+	response, issues, attempts, err := u.translateWithRetries(ctx, index, request)
+	if err != nil {
+		results <- chunkResult{index: index, err: err}
+		return
+	}
+	if len(issues) == 0 && !response.Fallback {
+		u.storeMemory(ctx, memoryKey, chunk, response.Text)
+	}
+	results <- chunkResult{
+		index:      index,
+		text:       response.Text,
+		violations: domain.CheckGlossary(index, glossary, response.Text),
+		usage:      response.Usage,
+		issues:     issues,
+		attempts:   attempts,
+		backend:    response.Backend,
+		fallback:   response.Fallback,
+	}
 }
 
-// getPreviousContext returns the last N words from the previous chunk in the list, or an empty string if index is 0.
-func getPreviousContext(index int, chunks []string) string {
-	if index == 0 {
-		return ""
+// translateWithRetries translates the chunk at index and checks the translation with the validators of the usecase.
+// A translation failing any check is retranslated up to translate.quality.max-retries times, telling the Translator
+// which checks failed and switching to the model configured under translate.quality.retry-model, if any. The
+// translation failing the fewest checks is returned with its issues, the number of attempts made and the tokens spent
+// on all attempts. A failed retry keeps the best translation so far, so only an error of the first attempt is returned.
+func (u *TranslateUsecaseImpl) translateWithRetries(
+	ctx context.Context, index int, request domain.ChunkRequest,
+) (*domain.ChunkResponse, []domain.QualityIssue, int, error) {
+	best, issues, err := u.attempt(ctx, index, request)
+	if err != nil {
+		return nil, nil, 1, err
+	}
+	usage := best.Usage
+	attempts := 1
+	for retries := configuredRetries(); len(issues) > 0 && attempts <= retries; attempts++ {
+		retry := request
+		retry.Feedback = describeIssues(issues)
+		retry.Model = viper.GetString("translate.quality.retry-model")
+		response, retried, err := u.attempt(ctx, index, retry)
+		if err != nil {
+			log.Printf("Error retranslating chunk %d: %v\n", index, err)
+			attempts++
+			break
+		}
+		usage = usage.Add(response.Usage)
+		if len(retried) < len(issues) {
+			best, issues = response, retried
+		}
 	}
-	return utils.GetLastNWords(chunks[index-1], contextWordCount)
+	result := *best
+	result.Usage = usage
+	return &result, issues, attempts, nil
 }
 
-// initResults initializes two slices: one for strings and one for errors, each with a specified size.
-func initResults(size int) ([]string, []error) {
-	return make([]string, size), make([]error, size)
+// attempt translates the chunk at index once and returns the translation with the quality issues it has. An answer
+// the Translator reports as malformed is not an error but an empty translation failing CheckNotTranslation, so that
+// it is retried like any other rejected translation.
+func (u *TranslateUsecaseImpl) attempt(
+	ctx context.Context, index int, request domain.ChunkRequest,
+) (*domain.ChunkResponse, []domain.QualityIssue, error) {
+	response, err := u.translator.Translate(ctx, request)
+	if errors.Is(err, domain.ErrMalformedResponse) {
+		log.Printf("Malformed translation of chunk %d: %v\n", index, err)
+		issue := domain.QualityIssue{ChunkIndex: index, Check: domain.CheckNotTranslation, Detail: err.Error()}
+		return &domain.ChunkResponse{}, []domain.QualityIssue{issue}, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return response, domain.CheckQuality(index, u.validators, request.Text, response.Text), nil
 }
 
-// reportErrors logs any non-nil errors in the provided slice, associating each error with its corresponding chunk index.
-func reportErrors(errors []error) {
-	for i, err := range errors {
-		if err != nil {
-			log.Printf("Error translating chunk %d: %v\n", i, err)
-		}
+// describeIssues returns a description of every quality issue for the Translator.
+func describeIssues(issues []domain.QualityIssue) []string {
+	descriptions := make([]string, len(issues))
+	for i, issue := range issues {
+		descriptions[i] = issue.Check + ": " + issue.Detail
+	}
+	return descriptions
+}
+
+// configuredRetries returns the number of retries configured under translate.quality.max-retries, which may be 0 to
+// only flag chunks failing the quality checks, or defaultQualityRetries if it is not set.
+func configuredRetries() int {
+	if !viper.IsSet("translate.quality.max-retries") {
+		return defaultQualityRetries
+	}
+	return max(viper.GetInt("translate.quality.max-retries"), 0)
+}
+
+// memoryKey returns the translation memory scope of a request translated by the Translator of the usecase.
+func (u *TranslateUsecaseImpl) memoryKey(options Options) domain.MemoryKey {
+	sourceLang := options.SourceLang
+	if sourceLang == "" {
+		sourceLang = unknownLanguage
+	}
+	return domain.MemoryKey{
+		SourceLang: sourceLang,
+		TargetLang: domain.TargetLanguage,
+		Model:      u.translator.Model(),
+		Style:      options.Style.Key(),
+	}
+}
+
+// lookupMemory returns the translation memory match for chunk, or nil if there is none, the memory is disabled or the
+// lookup fails. Failures are logged and treated as misses.
+func (u *TranslateUsecaseImpl) lookupMemory(
+	ctx context.Context, key domain.MemoryKey, chunk string,
+) *domain.MemoryMatch {
+	if u.memory == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, memoryTimeout)
+	defer cancel()
+	match, err := u.memory.Lookup(ctx, key, chunk)
+	if err != nil {
+		log.Printf("Error looking up translation memory: %v", err)
+		return nil
+	}
+	return match
+}
+
+// storeMemory saves the translation of chunk in the translation memory, logging any failure.
+// The translation has already been paid for, so it is stored even if ctx has been cancelled in the meantime.
+func (u *TranslateUsecaseImpl) storeMemory(ctx context.Context, key domain.MemoryKey, chunk, translation string) {
+	if u.memory == nil || translation == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), memoryTimeout)
+	defer cancel()
+	if err := u.memory.Store(ctx, key, chunk, translation); err != nil {
+		log.Printf("Error storing translation memory: %v", err)
+	}
+}
+
+// getPreviousContext returns the trailing sentences of the previous chunk that fit in the context token budget, or an
+// empty string if index is 0.
+func getPreviousContext(index int, chunks []string) string {
+	if index == 0 {
+		return ""
 	}
+	previous := domain.StripMarkers(chunks[index-1])
+	return utils.TailTokens(previous, configuredTokens("translate.context.max-tokens", defaultContextTokens))
 }
@@ -0,0 +1,513 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/oOSomnus/transflate/internal/translate_service/domain"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+// delayedTranslator upper-cases its input, finishing later chunks sooner so results arrive out of order.
+type delayedTranslator struct {
+	failOn string
+}
+
+// Every translation is reported to use one prompt token per input byte and one completion token per output byte.
+func (d *delayedTranslator) Translate(ctx context.Context, req domain.ChunkRequest) (*domain.ChunkResponse, error) {
+	time.Sleep(time.Duration(10-len(req.Text)) * time.Millisecond)
+	if req.Text == d.failOn {
+		return nil, errors.New("translation failed")
+	}
+	usage := domain.TokenUsage{PromptTokens: len(req.Text), CompletionTokens: len(req.Text), TotalTokens: 2 * len(req.Text)}
+	return &domain.ChunkResponse{Text: strings.ToUpper(req.Text), Usage: usage}, nil
+}
+
+func (d *delayedTranslator) Model() string {
+	return "upper"
+}
+
+// mapMemory is an in-memory translation memory that keys entries by model and segment.
+type mapMemory struct {
+	mu      sync.Mutex
+	entries map[string]string
+}
+
+func (m *mapMemory) Lookup(ctx context.Context, key domain.MemoryKey, segment string) (*domain.MemoryMatch, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if translation, ok := m.entries[key.Scope()+":"+segment]; ok {
+		return &domain.MemoryMatch{Source: segment, Translation: translation, Exact: true}, nil
+	}
+	return nil, nil
+}
+
+func (m *mapMemory) Store(ctx context.Context, key domain.MemoryKey, segment, translation string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[key.Scope()+":"+segment] = translation
+	return nil
+}
+
+func TestTranslateChunks(t *testing.T) {
+	tests := []struct {
+		name     string
+		chunks   []string
+		failOn   string
+		expected []string
+	}{
+		{"single chunk", []string{"a"}, "", []string{"A"}},
+		{"in document order", []string{"a", "bb", "ccc", "dddd"}, "", []string{"A", "BB", "CCC", "DDDD"}},
+		{"failed chunk kept in place", []string{"a", "bb", "ccc"}, "bb", []string{"A", "", "CCC"}},
+		{"no chunks", []string{}, "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				var got []string
+				var indexes []int
+				u := NewTranslateUsecase(&delayedTranslator{failOn: tt.failOn}, nil, nil, nil)
+				err := u.translateChunks(
+					context.Background(), tt.chunks, Options{}, func(chunk TranslatedChunk) error {
+						assert.Equal(t, len(tt.chunks), chunk.Total)
+						indexes = append(indexes, chunk.Index)
+						got = append(got, chunk.Text)
+						return nil
+					},
+				)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, got)
+				for i, index := range indexes {
+					assert.Equal(t, i, index)
+				}
+			},
+		)
+	}
+}
+
+func TestTranslateChunksStopsOnHandlerError(t *testing.T) {
+	handlerErr := errors.New("client gone")
+	calls := 0
+	u := NewTranslateUsecase(&delayedTranslator{}, nil, nil, nil)
+	err := u.translateChunks(
+		context.Background(), []string{"a", "bb", "ccc"}, Options{}, func(chunk TranslatedChunk) error {
+			calls++
+			return handlerErr
+		},
+	)
+	assert.ErrorIs(t, err, handlerErr)
+	assert.Equal(t, 1, calls)
+}
+
+func TestTranslateChunksReportsGlossaryViolations(t *testing.T) {
+	options := Options{
+		Glossary: []domain.GlossaryTerm{
+			{Source: "bb", Target: "BB"},
+			{Source: "ccc", Target: "xyz"},
+		},
+	}
+	var violations []domain.GlossaryViolation
+	u := NewTranslateUsecase(&delayedTranslator{}, nil, nil, nil)
+	err := u.translateChunks(
+		context.Background(), []string{"a", "bb", "ccc"}, options, func(chunk TranslatedChunk) error {
+			violations = append(violations, chunk.GlossaryViolations...)
+			return nil
+		},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.GlossaryViolation{{ChunkIndex: 2, Source: "ccc", Target: "xyz"}}, violations)
+}
+
+func TestTranslateChunksReusesMemory(t *testing.T) {
+	memory := &mapMemory{
+		entries: map[string]string{
+			"upper:a":   "cached a",
+			"upper:ccc": "cached c",
+			"other:bb":  "wrong model",
+		},
+	}
+	options := Options{Glossary: []domain.GlossaryTerm{{Source: "ccc", Target: "CCC"}}}
+	var texts []string
+	var fromMemory []bool
+	var usage []domain.TokenUsage
+	u := NewTranslateUsecase(&delayedTranslator{}, memory, nil, nil)
+	err := u.translateChunks(
+		context.Background(), []string{"a", "bb", "ccc"}, options, func(chunk TranslatedChunk) error {
+			texts = append(texts, chunk.Text)
+			fromMemory = append(fromMemory, chunk.FromMemory)
+			usage = append(usage, chunk.Usage)
+			return nil
+		},
+	)
+	assert.NoError(t, err)
+	// "ccc" is cached with a translation that breaks the glossary, so it is translated again
+	assert.Equal(t, []string{"cached a", "BB", "CCC"}, texts)
+	assert.Equal(t, []bool{true, false, false}, fromMemory)
+	assert.Equal(t, domain.TokenUsage{}, usage[0])
+	assert.Equal(t, domain.TokenUsage{PromptTokens: 3, CompletionTokens: 3, TotalTokens: 6}, usage[2])
+	assert.Equal(t, "BB", memory.entries["upper:bb"])
+	assert.Equal(t, "CCC", memory.entries["upper:ccc"])
+}
+
+func TestTranslateTextReportsUsage(t *testing.T) {
+	viper.Set("translate.chunk.max-tokens", 2)
+	defer viper.Set("translate.chunk.max-tokens", nil)
+
+	u := NewTranslateUsecase(&delayedTranslator{}, nil, nil, nil)
+	translation, err := u.TranslateText(context.Background(), "ab cd\n\nefg", Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, "AB CD\nEFG", translation.Text)
+	assert.Equal(t, "upper", translation.Model)
+	assert.Equal(t, 2, translation.ChunkCount)
+	assert.Equal(
+		t, []domain.TokenUsage{
+			{PromptTokens: 5, CompletionTokens: 5, TotalTokens: 10},
+			{PromptTokens: 3, CompletionTokens: 3, TotalTokens: 6},
+		}, translation.ChunkUsage,
+	)
+	assert.Equal(t, domain.TokenUsage{PromptTokens: 8, CompletionTokens: 8, TotalTokens: 16}, translation.Usage)
+	assert.Positive(t, translation.Elapsed)
+	assert.Equal(
+		t, []Segment{{Source: "ab cd", Translation: "AB CD"}, {Source: "efg", Translation: "EFG"}}, translation.Segments,
+	)
+}
+
+func TestTranslateTextWithProgress(t *testing.T) {
+	viper.Set("translate.chunk.max-tokens", 2)
+	defer viper.Set("translate.chunk.max-tokens", nil)
+
+	u := NewTranslateUsecase(&delayedTranslator{}, nil, nil, nil)
+	var progress [][2]int
+	translation, err := u.TranslateTextWithProgress(
+		context.Background(), "ab cd\n\nefg", Options{}, func(done, total int) error {
+			progress = append(progress, [2]int{done, total})
+			return nil
+		},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "AB CD\nEFG", translation.Text)
+	assert.Equal(t, [][2]int{{1, 2}, {2, 2}}, progress)
+}
+
+func TestTranslateTextWithProgressStopsOnError(t *testing.T) {
+	viper.Set("translate.chunk.max-tokens", 2)
+	defer viper.Set("translate.chunk.max-tokens", nil)
+
+	u := NewTranslateUsecase(&delayedTranslator{}, nil, nil, nil)
+	errGone := errors.New("client gone")
+	calls := 0
+	translation, err := u.TranslateTextWithProgress(
+		context.Background(), "ab cd\n\nefg", Options{}, func(done, total int) error {
+			calls++
+			return errGone
+		},
+	)
+	assert.ErrorIs(t, err, errGone)
+	assert.Nil(t, translation)
+	assert.Equal(t, 1, calls)
+}
+
+func TestTranslateTextPreservesMarkdown(t *testing.T) {
+	viper.Set("translate.chunk.max-tokens", 4)
+	defer viper.Set("translate.chunk.max-tokens", nil)
+
+	source := "# Setup guide\n\n- install `go` first\n- then run tests\n\n```sh\nmake test\n```\n\nSee https://example.com for more."
+	u := NewTranslateUsecase(&delayedTranslator{}, nil, nil, nil)
+	translation, err := u.TranslateText(context.Background(), source, Options{})
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		"# SETUP GUIDE\n\n- INSTALL `go` FIRST\n- THEN RUN TESTS\n\n```sh\nmake test\n```\n\nSEE https://example.com FOR MORE.",
+		translation.Text,
+	)
+	assert.Equal(t, 4, translation.ChunkCount)
+	var sources []string
+	for _, segment := range translation.Segments {
+		sources = append(sources, segment.Source)
+	}
+	assert.Equal(t, source, strings.Join(sources, "\n"))
+}
+
+func TestTranslateTextWithoutTextNodes(t *testing.T) {
+	source := "```sh\nmake test\n```"
+	u := NewTranslateUsecase(&delayedTranslator{}, nil, nil, nil)
+	translation, err := u.TranslateText(context.Background(), source, Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, source, translation.Text)
+	assert.Equal(t, domain.TokenUsage{}, translation.Usage)
+}
+
+// blockingTranslator blocks every translation until its context is done and counts the calls it received.
+type blockingTranslator struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (b *blockingTranslator) Translate(ctx context.Context, req domain.ChunkRequest) (*domain.ChunkResponse, error) {
+	b.mu.Lock()
+	b.calls++
+	b.mu.Unlock()
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (b *blockingTranslator) Model() string {
+	return "blocking"
+}
+
+func TestTranslateChunksStopsOnCancel(t *testing.T) {
+	chunks := make([]string, 100)
+	for i := range chunks {
+		chunks[i] = "chunk"
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	translator := &blockingTranslator{}
+	delivered := 0
+	u := NewTranslateUsecase(translator, nil, nil, nil)
+	err := u.translateChunks(
+		ctx, chunks, Options{}, func(chunk TranslatedChunk) error {
+			delivered++
+			return nil
+		},
+	)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Zero(t, delivered)
+
+	// Chunks queued behind the worker limit must never reach the translator
+	time.Sleep(20 * time.Millisecond)
+	translator.mu.Lock()
+	defer translator.mu.Unlock()
+	assert.LessOrEqual(t, translator.calls, max(runtime.NumCPU()*2, 10))
+}
+
+// staticExtractor returns a fixed term sheet for every part of a document.
+type staticExtractor struct {
+	terms []domain.GlossaryTerm
+}
+
+func (s *staticExtractor) ExtractTerms(ctx context.Context, text string) (*domain.TermSheet, error) {
+	usage := domain.TokenUsage{PromptTokens: 1, CompletionTokens: 1, TotalTokens: 2}
+	return &domain.TermSheet{Terms: s.terms, Usage: usage}, nil
+}
+
+// recordingTranslator upper-cases its input and records the term sheet supplied with every chunk.
+type recordingTranslator struct {
+	mu         sync.Mutex
+	termSheets map[string][]domain.GlossaryTerm
+}
+
+func (r *recordingTranslator) Translate(ctx context.Context, req domain.ChunkRequest) (*domain.ChunkResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.termSheets[req.Text] = req.TermSheet
+	return &domain.ChunkResponse{Text: strings.ToUpper(req.Text)}, nil
+}
+
+func (r *recordingTranslator) Model() string {
+	return "recording"
+}
+
+func TestTranslateTextWithConsistency(t *testing.T) {
+	viper.Set("translate.chunk.max-tokens", 3)
+	defer viper.Set("translate.chunk.max-tokens", nil)
+
+	extractor := &staticExtractor{
+		terms: []domain.GlossaryTerm{{Source: "Acme", Target: "ACME-ZH"}, {Source: "court", Target: "COURT-ZH"}},
+	}
+	options := Options{Glossary: []domain.GlossaryTerm{{Source: "court", Target: "法院"}}}
+
+	tests := []struct {
+		name        string
+		consistency bool
+		extractor   domain.TermExtractor
+		termSheet   []domain.GlossaryTerm
+		usage       domain.TokenUsage
+	}{
+		{"disabled", false, extractor, nil, domain.TokenUsage{}},
+		{"no extractor", true, nil, nil, domain.TokenUsage{}},
+		{
+			"glossary takes precedence", true, extractor,
+			[]domain.GlossaryTerm{{Source: "Acme", Target: "ACME-ZH"}},
+			domain.TokenUsage{PromptTokens: 1, CompletionTokens: 1, TotalTokens: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				translator := &recordingTranslator{termSheets: make(map[string][]domain.GlossaryTerm)}
+				u := NewTranslateUsecase(translator, nil, tt.extractor, nil)
+				options.Consistency = tt.consistency
+				translation, err := u.TranslateText(context.Background(), "Acme sued.\n\nThe court agreed.", options)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.termSheet, translation.TermSheet)
+				assert.Equal(t, tt.usage, translation.Usage)
+				assert.Equal(t, tt.termSheet, translator.termSheets["Acme sued."])
+				assert.Empty(t, translator.termSheets["The court agreed."])
+			},
+		)
+	}
+}
+
+// retryingTranslator drops the digits of its input unless it is told why an earlier translation was rejected, and
+// records the model requested by every retry. Inputs containing "stubborn" never keep their digits.
+type retryingTranslator struct {
+	mu          sync.Mutex
+	retryModels []string
+}
+
+func (r *retryingTranslator) Translate(ctx context.Context, req domain.ChunkRequest) (*domain.ChunkResponse, error) {
+	text := strings.ToUpper(req.Text)
+	if len(req.Feedback) == 0 || strings.Contains(req.Text, "stubborn") {
+		text = strings.Map(
+			func(r rune) rune {
+				if r >= '0' && r <= '9' {
+					return -1
+				}
+				return r
+			}, text,
+		)
+	}
+	if len(req.Feedback) > 0 {
+		r.mu.Lock()
+		r.retryModels = append(r.retryModels, req.Model)
+		r.mu.Unlock()
+	}
+	return &domain.ChunkResponse{Text: text, Usage: domain.TokenUsage{TotalTokens: 1}}, nil
+}
+
+func (r *retryingTranslator) Model() string {
+	return "retrying"
+}
+
+func TestTranslateChunksRetriesFailedQualityChecks(t *testing.T) {
+	viper.Set("translate.quality.retry-model", "fallback")
+	defer viper.Set("translate.quality.retry-model", nil)
+
+	translator := &retryingTranslator{}
+	var texts []string
+	var attempts []int
+	var issues []domain.QualityIssue
+	var usage []domain.TokenUsage
+	u := NewTranslateUsecase(translator, nil, nil, []domain.QualityValidator{domain.NumberValidator{}})
+	err := u.translateChunks(
+		context.Background(), []string{"a 1", "b", "stubborn 3"}, Options{}, func(chunk TranslatedChunk) error {
+			texts = append(texts, chunk.Text)
+			attempts = append(attempts, chunk.Attempts)
+			issues = append(issues, chunk.QualityIssues...)
+			usage = append(usage, chunk.Usage)
+			return nil
+		},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"A 1", "B", "STUBBORN "}, texts)
+	assert.Equal(t, []int{2, 1, 2}, attempts)
+	assert.Equal(
+		t, []domain.QualityIssue{{ChunkIndex: 2, Check: domain.CheckMissingNumber, Detail: "missing: 3"}}, issues,
+	)
+	assert.Equal(t, []domain.TokenUsage{{TotalTokens: 2}, {TotalTokens: 1}, {TotalTokens: 2}}, usage)
+	assert.Equal(t, []string{"fallback", "fallback"}, translator.retryModels)
+}
+
+// malformedTranslator answers every first attempt with a malformed response and translates retries.
+type malformedTranslator struct{}
+
+func (m *malformedTranslator) Translate(ctx context.Context, req domain.ChunkRequest) (*domain.ChunkResponse, error) {
+	if len(req.Feedback) == 0 {
+		return nil, domain.ErrMalformedResponse
+	}
+	return &domain.ChunkResponse{Text: strings.ToUpper(req.Text)}, nil
+}
+
+func (m *malformedTranslator) Model() string {
+	return "malformed"
+}
+
+func TestTranslateChunksRetriesMalformedResponses(t *testing.T) {
+	tests := []struct {
+		name     string
+		retries  int
+		text     string
+		attempts int
+		issues   []string
+	}{
+		{"retried", 1, "A", 2, nil},
+		{"flagged without retries", 0, "", 1, []string{domain.CheckNotTranslation}},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				viper.Set("translate.quality.max-retries", tt.retries)
+				defer viper.Set("translate.quality.max-retries", nil)
+
+				var chunks []TranslatedChunk
+				u := NewTranslateUsecase(&malformedTranslator{}, nil, nil, nil)
+				err := u.translateChunks(
+					context.Background(), []string{"a"}, Options{}, func(chunk TranslatedChunk) error {
+						chunks = append(chunks, chunk)
+						return nil
+					},
+				)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.text, chunks[0].Text)
+				assert.Equal(t, tt.attempts, chunks[0].Attempts)
+				var issues []string
+				for _, issue := range chunks[0].QualityIssues {
+					issues = append(issues, issue.Check)
+				}
+				assert.Equal(t, tt.issues, issues)
+			},
+		)
+	}
+}
+
+func TestTranslateChunksScopesMemoryByStyle(t *testing.T) {
+	memory := &mapMemory{entries: map[string]string{"upper:a": "default style"}}
+	style := domain.TranslationStyle{Domain: domain.DomainLegal}
+	var texts []string
+	u := NewTranslateUsecase(&delayedTranslator{}, memory, nil, nil)
+	err := u.translateChunks(
+		context.Background(), []string{"a"}, Options{Style: style}, func(chunk TranslatedChunk) error {
+			texts = append(texts, chunk.Text)
+			return nil
+		},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"A"}, texts)
+	assert.Equal(t, "A", memory.entries["upper+"+style.Key()+":a"])
+}
+
+func TestTranslateChunksKeepsFallbackTranslationsOutOfMemory(t *testing.T) {
+	translator, err := domain.NewFallbackTranslator(
+		[]domain.TranslationBackend{
+			{Name: "primary", Translator: &delayedTranslator{failOn: "b"}},
+			{Name: "secondary", Translator: &delayedTranslator{}},
+		}, domain.BreakerSettings{},
+	)
+	assert.NoError(t, err)
+	memory := &mapMemory{entries: map[string]string{}}
+	var backends []string
+	var fallbacks []bool
+	u := NewTranslateUsecase(translator, memory, nil, nil)
+	err = u.translateChunks(
+		context.Background(), []string{"a", "b"}, Options{}, func(chunk TranslatedChunk) error {
+			backends = append(backends, chunk.Backend)
+			fallbacks = append(fallbacks, chunk.Fallback)
+			return nil
+		},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"primary", "secondary"}, backends)
+	assert.Equal(t, []bool{false, true}, fallbacks)
+	assert.Equal(t, map[string]string{"upper:a": "A"}, memory.entries)
+}
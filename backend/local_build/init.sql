@@ -7,3 +7,104 @@ CREATE TABLE IF NOT EXISTS public.users
     balance  INT DEFAULT 0
 );
 
+CREATE TABLE IF NOT EXISTS public.glossaries
+(
+    glossary_id SERIAL PRIMARY KEY,
+    username    VARCHAR(50)  NOT NULL,
+    name        VARCHAR(100) NOT NULL,
+    created_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS public.glossary_terms
+(
+    term_id     SERIAL PRIMARY KEY,
+    glossary_id INT          NOT NULL REFERENCES public.glossaries (glossary_id) ON DELETE CASCADE,
+    source_term VARCHAR(255) NOT NULL,
+    target_term VARCHAR(255) NOT NULL,
+    UNIQUE (glossary_id, source_term)
+);
+
+CREATE TABLE IF NOT EXISTS public.style_profiles
+(
+    profile_id   SERIAL PRIMARY KEY,
+    username     VARCHAR(50)  NOT NULL,
+    name         VARCHAR(100) NOT NULL,
+    formality    VARCHAR(20)  NOT NULL DEFAULT '',
+    domain       VARCHAR(20)  NOT NULL DEFAULT '',
+    instructions TEXT         NOT NULL DEFAULT '',
+    created_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS public.webhooks
+(
+    webhook_id SERIAL PRIMARY KEY,
+    username   VARCHAR(50)   NOT NULL,
+    url        VARCHAR(2048) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS public.webhook_secrets
+(
+    username   VARCHAR(50) PRIMARY KEY,
+    secret     VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS public.webhook_deliveries
+(
+    delivery_id      SERIAL PRIMARY KEY,
+    webhook_id       INT          NOT NULL REFERENCES public.webhooks (webhook_id) ON DELETE CASCADE,
+    username         VARCHAR(50)  NOT NULL,
+    event            VARCHAR(20)  NOT NULL,
+    task_id          VARCHAR(100) NOT NULL,
+    payload          TEXT         NOT NULL,
+    status           VARCHAR(20)  NOT NULL DEFAULT 'pending',
+    attempts         INT          NOT NULL DEFAULT 0,
+    next_attempt_at  TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_status_code INT          NOT NULL DEFAULT 0,
+    last_error       TEXT         NOT NULL DEFAULT '',
+    replay_of        INT,
+    created_at       TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered_at     TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due ON public.webhook_deliveries (status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_user ON public.webhook_deliveries (username, created_at);
+
+
+CREATE TABLE IF NOT EXISTS public.balance_reservations
+(
+    reservation_id SERIAL PRIMARY KEY,
+    username       VARCHAR(50)  NOT NULL,
+    task_id        VARCHAR(100) NOT NULL,
+    pages          INT          NOT NULL,
+    status         VARCHAR(20)  NOT NULL DEFAULT 'reserved',
+    created_at     TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at     TIMESTAMPTZ  NOT NULL,
+    settled_at     TIMESTAMPTZ,
+    UNIQUE (username, task_id)
+);
+
+CREATE INDEX IF NOT EXISTS balance_reservations_expiry ON public.balance_reservations (status, expires_at);
+
+
+CREATE TABLE IF NOT EXISTS public.balance_transactions
+(
+    transaction_id SERIAL PRIMARY KEY,
+    username       VARCHAR(50)  NOT NULL,
+    kind           VARCHAR(20)  NOT NULL,
+    amount         INT          NOT NULL,
+    balance_after  INT          NOT NULL,
+    task_id        VARCHAR(100) NOT NULL DEFAULT '',
+    reason         TEXT         NOT NULL DEFAULT '',
+    created_at     TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS balance_transactions_user ON public.balance_transactions (username, created_at);
+
+-- Balances that predate the ledger open it, so that every balance equals the sum of its transactions
+INSERT INTO public.balance_transactions (username, kind, amount, balance_after, reason)
+SELECT u.username, 'opening', u.balance, u.balance, 'Opening balance'
+FROM public.users u
+WHERE COALESCE(u.balance, 0) <> 0
+  AND NOT EXISTS (SELECT 1 FROM public.balance_transactions t WHERE t.username = u.username);
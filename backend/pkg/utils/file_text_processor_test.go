@@ -1,64 +1,9 @@
 package utils
 
 import (
-	"reflect"
 	"testing"
 )
 
-func TestSplitString(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    string
-		maxWords int
-		expected []string
-	}{
-		{"short input", "hello world", 5, []string{"hello world"}},
-		{"exact words", "one two three four five", 5, []string{"one two three four five"}},
-		{"split evenly", "one two three four five six", 3, []string{"one two three", "four five six"}},
-		{"split remainder", "one two three four five", 2, []string{"one two", "three four", "five"}},
-		{"empty input", "", 2, []string{""}},
-		{"single chunk", "one", 2, []string{"one"}},
-	}
-
-	for _, tc := range tests {
-		t.Run(
-			tc.name, func(t *testing.T) {
-				result := SplitString(tc.input, tc.maxWords)
-				if !reflect.DeepEqual(result, tc.expected) {
-					t.Errorf("expected %v, got %v", tc.expected, result)
-				}
-			},
-		)
-	}
-}
-
-func TestGetLastNWords(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    string
-		n        int
-		expected string
-	}{
-		{"all words", "hello world", 5, "hello world"},
-		{"exact last n words", "one two three four five", 3, "three four five"},
-		{"n is zero", "one two three", 0, ""},
-		{"n greater than input length", "hello world", 10, "hello world"},
-		{"empty input", "", 3, ""},
-		{"last word", "one", 1, "one"},
-	}
-
-	for _, tc := range tests {
-		t.Run(
-			tc.name, func(t *testing.T) {
-				result := GetLastNWords(tc.input, tc.n)
-				if result != tc.expected {
-					t.Errorf("expected %q, got %q", tc.expected, result)
-				}
-			},
-		)
-	}
-}
-
 func TestRemoveNonUnicodeCharacters(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,282 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// paragraphSeparator matches the blank lines separating two paragraphs.
+var paragraphSeparator = regexp.MustCompile(`\n[ \t\r]*\n\s*`)
+
+// abbreviations lists common lowercase abbreviations whose trailing period does not end a sentence.
+var abbreviations = map[string]bool{
+	"mr": true, "mrs": true, "ms": true, "dr": true, "prof": true, "st": true, "no": true, "vs": true, "etc": true,
+	"e.g": true, "i.e": true, "fig": true, "vol": true, "art": true, "sec": true, "approx": true, "inc": true,
+}
+
+// CountTokens estimates the number of model tokens in s without calling any remote API.
+// The estimate follows how BPE tokenizers such as cl100k treat each script: Latin words cost roughly one token per four
+// letters, other alphabetic scripts like Arabic or Cyrillic one token per two letters, every CJK character and every
+// punctuation mark one token, while whitespace is free.
+// It is a heuristic, not a tokenizer: counts may differ from those of the model by tens of percent, mostly on code,
+// numbers and rare words, so token budgets based on it are approximate.
+func CountTokens(s string) int {
+	tokens := 0
+	wordLen := 0
+	wordDivisor := 4
+	flushWord := func() {
+		if wordLen > 0 {
+			tokens += (wordLen + wordDivisor - 1) / wordDivisor
+			wordLen = 0
+			wordDivisor = 4
+		}
+	}
+	for _, r := range s {
+		switch {
+		case unicode.IsSpace(r):
+			flushWord()
+		case isUnspacedScript(r):
+			flushWord()
+			tokens++
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r):
+			if r > unicode.MaxLatin1 && !unicode.Is(unicode.Latin, r) && !unicode.Is(unicode.Mn, r) {
+				wordDivisor = 2
+			}
+			wordLen++
+		default:
+			flushWord()
+			tokens++
+		}
+	}
+	flushWord()
+	return tokens
+}
+
+// ChunkText splits s into chunks of at most maxTokens estimated tokens while respecting the structure of the text.
+// Whole paragraphs are packed together whenever they fit, paragraphs that are too large are split at sentence
+// boundaries, and only sentences that exceed the budget on their own are cut between words, or between characters
+// for scripts such as Chinese or Japanese that do not separate words with spaces.
+// Returns nil if s contains no text.
+func ChunkText(s string, maxTokens int) []string {
+	if maxTokens <= 0 {
+		maxTokens = 1
+	}
+	var chunks []string
+	var current []string
+	currentTokens := 0
+
+	flush := func() {
+		if len(current) > 0 {
+			chunks = append(chunks, strings.Join(current, "\n\n"))
+			current = nil
+			currentTokens = 0
+		}
+	}
+	for _, paragraph := range SplitParagraphs(s) {
+		tokens := CountTokens(paragraph)
+		if tokens > maxTokens {
+			flush()
+			chunks = append(chunks, packPieces(SplitSentences(paragraph), maxTokens)...)
+			continue
+		}
+		if currentTokens+tokens > maxTokens {
+			flush()
+		}
+		current = append(current, paragraph)
+		currentTokens += tokens
+	}
+	flush()
+	return chunks
+}
+
+// SplitParagraphs splits s on blank lines and returns the trimmed, non-empty paragraphs.
+func SplitParagraphs(s string) []string {
+	var paragraphs []string
+	for _, paragraph := range paragraphSeparator.Split(s, -1) {
+		if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+			paragraphs = append(paragraphs, paragraph)
+		}
+	}
+	return paragraphs
+}
+
+// SplitSentences splits s into sentences, keeping closing quotes and brackets and the whitespace that follows with the
+// sentence they belong to, so that joining the result reproduces s exactly.
+// Latin and Arabic terminators only end a sentence when followed by whitespace, while CJK full-width terminators end
+// one immediately.
+func SplitSentences(s string) []string {
+	var sentences []string
+	start := 0
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		if !isSentenceTerminator(r) {
+			continue
+		}
+		end := i
+		for end < len(s) {
+			next, nextSize := utf8.DecodeRuneInString(s[end:])
+			if !isSentenceTerminator(next) && !isClosingPunctuation(next) {
+				break
+			}
+			end += nextSize
+		}
+		next, _ := utf8.DecodeRuneInString(s[end:])
+		if !isFullWidthTerminator(r) && end < len(s) && !unicode.IsSpace(next) {
+			i = end
+			continue
+		}
+		if r == '.' && endsWithAbbreviation(s[start:i-size]) {
+			i = end
+			continue
+		}
+		for end < len(s) {
+			next, nextSize := utf8.DecodeRuneInString(s[end:])
+			if !unicode.IsSpace(next) {
+				break
+			}
+			end += nextSize
+		}
+		sentences = append(sentences, s[start:end])
+		start, i = end, end
+	}
+	if start < len(s) {
+		sentences = append(sentences, s[start:])
+	}
+	return sentences
+}
+
+// TailTokens returns the end of s that fits within maxTokens estimated tokens, preferring whole sentences and falling
+// back to whole words, or characters for unspaced scripts, when even the last sentence is too long.
+func TailTokens(s string, maxTokens int) string {
+	s = strings.TrimSpace(s)
+	if maxTokens <= 0 || s == "" {
+		return ""
+	}
+	if CountTokens(s) <= maxTokens {
+		return s
+	}
+	tail := takeTail(SplitSentences(s), maxTokens)
+	if tail == "" {
+		tail = takeTail(splitWords(s), maxTokens)
+	}
+	return strings.TrimSpace(tail)
+}
+
+// takeTail joins the longest suffix of pieces whose estimated token count stays within maxTokens.
+func takeTail(pieces []string, maxTokens int) string {
+	tokens := 0
+	start := len(pieces)
+	for start > 0 {
+		pieceTokens := CountTokens(pieces[start-1])
+		if tokens+pieceTokens > maxTokens {
+			break
+		}
+		tokens += pieceTokens
+		start--
+	}
+	return strings.Join(pieces[start:], "")
+}
+
+// packPieces greedily concatenates consecutive pieces into chunks of at most maxTokens estimated tokens.
+// Pieces larger than the budget are split further into words before packing.
+func packPieces(pieces []string, maxTokens int) []string {
+	var chunks []string
+	var builder strings.Builder
+	tokens := 0
+	flush := func() {
+		if chunk := strings.TrimSpace(builder.String()); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		builder.Reset()
+		tokens = 0
+	}
+	for _, piece := range pieces {
+		pieceTokens := CountTokens(piece)
+		if pieceTokens > maxTokens {
+			if words := splitWords(piece); len(words) > 1 {
+				flush()
+				chunks = append(chunks, packPieces(words, maxTokens)...)
+				continue
+			}
+		}
+		if tokens+pieceTokens > maxTokens {
+			flush()
+		}
+		builder.WriteString(piece)
+		tokens += pieceTokens
+	}
+	flush()
+	return chunks
+}
+
+// splitWords splits s into words with their trailing whitespace, treating every character of an unspaced script as a
+// word of its own, so that joining the result reproduces s exactly.
+func splitWords(s string) []string {
+	var words []string
+	start := 0
+	inSpace := false
+	for i, r := range s {
+		switch {
+		case isUnspacedScript(r):
+			if i > start {
+				words = append(words, s[start:i])
+			}
+			start = i
+			inSpace = true // the character ends as soon as anything but whitespace follows
+		case unicode.IsSpace(r):
+			inSpace = true
+		case inSpace:
+			if i > start {
+				words = append(words, s[start:i])
+			}
+			start = i
+			inSpace = false
+		}
+	}
+	if start < len(s) {
+		words = append(words, s[start:])
+	}
+	return words
+}
+
+// endsWithAbbreviation reports whether the last word of s is a known abbreviation or a single letter such as an
+// initial, in which case a following period does not end the sentence.
+func endsWithAbbreviation(s string) bool {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return false
+	}
+	word := strings.TrimLeftFunc(fields[len(fields)-1], func(r rune) bool { return !unicode.IsLetter(r) })
+	return utf8.RuneCountInString(word) == 1 || abbreviations[strings.ToLower(word)]
+}
+
+// isUnspacedScript reports whether r belongs to a script written without spaces between words.
+func isUnspacedScript(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Thai, unicode.Lao, unicode.Khmer)
+}
+
+// isSentenceTerminator reports whether r ends a sentence in Latin, Arabic or CJK text.
+func isSentenceTerminator(r rune) bool {
+	switch r {
+	case '.', '!', '?', '؟', '۔':
+		return true
+	}
+	return isFullWidthTerminator(r)
+}
+
+// isFullWidthTerminator reports whether r is a CJK sentence terminator, which is not followed by a space.
+func isFullWidthTerminator(r rune) bool {
+	switch r {
+	case '。', '！', '？', '｡', '…':
+		return true
+	}
+	return false
+}
+
+// isClosingPunctuation reports whether r is a closing quote or bracket that belongs to the preceding sentence.
+func isClosingPunctuation(r rune) bool {
+	return unicode.In(r, unicode.Pe, unicode.Pf) || r == '"' || r == '\''
+}
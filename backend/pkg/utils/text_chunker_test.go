@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCountTokens(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected int
+	}{
+		{"empty input", "", 0},
+		{"short words", "the cat sat", 3},
+		{"long word", "internationalization", 5},
+		{"punctuation", "Hello, world!", 6},
+		{"chinese characters", "你好世界", 4},
+		{"arabic word", "مرحبا", 3},
+		{"mixed scripts", "GPT 翻译", 3},
+	}
+
+	for _, tc := range tests {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				result := CountTokens(tc.input)
+				if result != tc.expected {
+					t.Errorf("expected %d, got %d", tc.expected, result)
+				}
+			},
+		)
+	}
+}
+
+func TestSplitSentences(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"single sentence", "Hello world.", []string{"Hello world."}},
+		{"two sentences", "Hello world. How are you?", []string{"Hello world. ", "How are you?"}},
+		{"closing quote", `He said "stop." Then left.`, []string{`He said "stop." `, "Then left."}},
+		{"decimal number", "Pi is 3.14 roughly. Yes.", []string{"Pi is 3.14 roughly. ", "Yes."}},
+		{"abbreviation", "Ask Dr. Smith now. Ok.", []string{"Ask Dr. Smith now. ", "Ok."}},
+		{"chinese", "你好。今天天气很好！", []string{"你好。", "今天天气很好！"}},
+		{"arabic", "مرحبا؟ كيف حالك.", []string{"مرحبا؟ ", "كيف حالك."}},
+		{"no terminator", "no end", []string{"no end"}},
+		{"empty input", "", nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				result := SplitSentences(tc.input)
+				if !reflect.DeepEqual(result, tc.expected) {
+					t.Errorf("expected %q, got %q", tc.expected, result)
+				}
+				if strings.Join(result, "") != tc.input {
+					t.Errorf("sentences do not reproduce the input: %q", result)
+				}
+			},
+		)
+	}
+}
+
+func TestChunkText(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		maxTokens int
+		expected  []string
+	}{
+		{"empty input", "  \n ", 10, nil},
+		{"fits in one chunk", "One two. Three four.", 10, []string{"One two. Three four."}},
+		{"packs paragraphs", "One.\n\nTwo.\n\nThree.", 4, []string{"One.\n\nTwo.", "Three."}},
+		{"splits at sentences", "One two. Three four. Five six.", 5, []string{"One two.", "Three four.", "Five six."}},
+		{"splits long sentence at words", "one two three four five six", 3, []string{"one two", "three four", "five six"}},
+		{"splits unspaced script", "你好世界你好世界", 3, []string{"你好世", "界你好", "世界"}},
+		{"chinese sentences", "你好。今天很好。", 4, []string{"你好。", "今天很好", "。"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				result := ChunkText(tc.input, tc.maxTokens)
+				if !reflect.DeepEqual(result, tc.expected) {
+					t.Errorf("expected %q, got %q", tc.expected, result)
+				}
+				for _, chunk := range result {
+					if tokens := CountTokens(chunk); tokens > tc.maxTokens {
+						t.Errorf("chunk %q has %d tokens, budget is %d", chunk, tokens, tc.maxTokens)
+					}
+				}
+			},
+		)
+	}
+}
+
+func TestTailTokens(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		maxTokens int
+		expected  string
+	}{
+		{"whole input", "One two.", 10, "One two."},
+		{"last sentences", "First sentence here. Second one. Third.", 7, "Second one. Third."},
+		{"falls back to words", "one two three four five", 2, "four five"},
+		{"unspaced script", "你好世界", 2, "世界"},
+		{"zero budget", "one two", 0, ""},
+		{"empty input", "", 5, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				result := TailTokens(tc.input, tc.maxTokens)
+				if result != tc.expected {
+					t.Errorf("expected %q, got %q", tc.expected, result)
+				}
+			},
+		)
+	}
+}
@@ -50,3 +50,53 @@ export const fetchTasks = async () => {
         throw e;
     }
 };
+
+/*
+streamTaskEvents reads the Server-Sent Events of the tasks of the user from /tasks/stream, which starts with a
+"snapshot" event holding all tasks and continues with an event for every change of a task. The stream is read with
+fetch rather than EventSource, which cannot send the Authorization header.
+
+Parameters:
+  - onEvent (function): Called with the name and the decoded data of every event.
+  - signal (AbortSignal): Closes the stream when aborted.
+
+Returns:
+  - (Promise): Resolves when the server ends the stream, and rejects if it cannot be opened or breaks.
+*/
+export const streamTaskEvents = async (onEvent, signal) => {
+    const response = await fetch(`${API.defaults.baseURL}/tasks/stream`, {
+        headers: {Authorization: `Bearer ${getToken()}`},
+        signal,
+    });
+    if (!response.ok || !response.body) {
+        throw new Error(`Failed to open task stream: ${response.status}`);
+    }
+    const reader = response.body.getReader();
+    const decoder = new TextDecoder();
+    let buffer = '';
+    for (;;) {
+        const {value, done} = await reader.read();
+        if (done) {
+            return;
+        }
+        buffer += decoder.decode(value, {stream: true});
+        let boundary;
+        while ((boundary = buffer.indexOf('\n\n')) !== -1) {
+            const block = buffer.slice(0, boundary);
+            buffer = buffer.slice(boundary + 2);
+            let name = 'message';
+            const data = [];
+            // Lines starting with a colon are heartbeats and carry no event
+            block.split('\n').forEach((line) => {
+                if (line.startsWith('event:')) {
+                    name = line.slice('event:'.length).trim();
+                } else if (line.startsWith('data:')) {
+                    data.push(line.slice('data:'.length));
+                }
+            });
+            if (data.length > 0) {
+                onEvent(name, JSON.parse(data.join('\n')));
+            }
+        }
+    }
+};
@@ -1,11 +1,12 @@
 import React, {useEffect, useState} from 'react';
 import {useNavigate} from 'react-router-dom';
-import {fetchTasks} from '../api';
+import {fetchTasks, streamTaskEvents} from '../api';
 import {Tooltip} from "react-tooltip";
 import {formatTimestamp} from "../utils"
 
 const TaskPage = () => {
-    const [tasks, setTasks] = useState([]);
+    // 以任务 ID 为键存储任务，便于按事件更新单个任务
+    const [tasks, setTasks] = useState({});
     const navigate = useNavigate();
 
     const statusMap = {
@@ -13,31 +14,83 @@ const TaskPage = () => {
         1: 'Translating',
         2: 'Uploading',
         3: 'Done',
+        4: 'Waiting For Review',
+        5: 'Cancelled',
+        6: 'Recognizing Text',
         9: 'Error',
     };
 
+    // OCR 识别中按页、翻译中按分块显示进度与预计剩余时间
+    const progressUnits = {6: 'pages', 1: 'chunks'};
+
+    const formatProgress = (task) => {
+        const unit = progressUnits[task.status];
+        if (!unit || task[`${unit}_total`] === undefined) return '';
+        let progress = ` (${task[`${unit}_done`] || 0}/${task[`${unit}_total`]} ${unit}`;
+        if (task.eta_seconds !== undefined) {
+            progress += task.eta_seconds < 60
+                ? `, ~${task.eta_seconds}s left`
+                : `, ~${Math.ceil(task.eta_seconds / 60)}min left`;
+        }
+        return progress + ')';
+    };
+
     useEffect(() => {
         let intervalId;
+        const controller = new AbortController();
 
         const loadTasks = async () => {
             try {
                 const response = await fetchTasks();
-                // 获取 tasks 数据并直接存储为值数组
-                setTasks(Object.values(response.data.data));
+                setTasks(response.data.data);
             } catch (error) {
                 alert('Failed to load tasks');
             }
         };
 
-        // 初始化加载任务
-        loadTasks();
+        // 根据推送的事件更新任务，snapshot 事件包含全部任务
+        const applyEvent = (name, event) => {
+            if (name === 'snapshot') {
+                setTasks(event);
+                return;
+            }
+            setTasks((current) => {
+                // 忽略不在列表中的任务（例如已过期的任务）的事件
+                if (!current[event.task_id] && name !== 'created') return current;
+                const task = {...current[event.task_id]};
+                if (event.filename) task.filename = event.filename;
+                if (event.status !== undefined && event.status !== task.status) {
+                    task.status = event.status;
+                    delete task.eta_seconds; // 预计剩余时间只针对当前阶段
+                }
+                if (event.progress) {
+                    task[`${event.progress.unit}_done`] = event.progress.done;
+                    task[`${event.progress.unit}_total`] = event.progress.total;
+                    if (event.progress.eta_seconds !== undefined) {
+                        task.eta_seconds = event.progress.eta_seconds;
+                    } else {
+                        delete task.eta_seconds;
+                    }
+                }
+                if (event.link) task.link = event.link;
+                if (name === 'created') task.created_at = event.time;
+                return {...current, [event.task_id]: task};
+            });
+        };
 
-        // 定时刷新任务状态
-        intervalId = setInterval(() => {
+        // 推送连接失败或断开时退回定时刷新
+        const startPolling = () => {
+            if (controller.signal.aborted || intervalId) return;
             loadTasks();
-        }, 5000); // 每5秒刷新一次
+            intervalId = setInterval(() => {
+                loadTasks();
+            }, 5000); // 每5秒刷新一次
+        };
+
+        streamTaskEvents(applyEvent, controller.signal).then(startPolling, startPolling);
 
         return () => {
+            controller.abort(); // 关闭推送连接
             clearInterval(intervalId); // 清除定时器
         };
     }, []);
@@ -46,7 +99,7 @@ const TaskPage = () => {
         <>
         <div className="task-table-container">
             <h2>Your Tasks</h2>
-            {tasks.length === 0 ? (
+            {Object.keys(tasks).length === 0 ? (
                 <p className="no-tasks">No tasks found or loading failed</p>
             ) : (
                 <table>
@@ -59,14 +112,14 @@ const TaskPage = () => {
                     </tr>
                     </thead>
                     <tbody>
-                    {tasks.map((task, index) => (
-                        <tr key={index}>
+                    {Object.entries(tasks).map(([id, task]) => (
+                        <tr key={id}>
                             <td>
                                 {task.filename.length > 10
                                     ? `${task.filename.substring(0, 10)}...`
                                     : task.filename}
                             </td>
-                            <td>{statusMap[task.status] || 'Unknown Status'}</td>
+                            <td>{(statusMap[task.status] || 'Unknown Status') + formatProgress(task)}</td>
                             <td>{formatTimestamp(task.created_at) || 'Unknown Created Time'}</td>
                             <td>
                                 {task.link ? (